@@ -0,0 +1,25 @@
+package inbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+)
+
+type LexiconParams struct {
+	Name    string
+	StoryID string
+	Entries []domain.LexiconEntry
+}
+
+type LexiconManagerPort interface {
+	Create(ctx context.Context, userID string, params LexiconParams) (domain.Lexicon, error)
+	Get(ctx context.Context, userID string, lexiconID string) (domain.Lexicon, error)
+	List(ctx context.Context, userID string) ([]domain.Lexicon, error)
+	Update(ctx context.Context, userID string, lexiconID string, params LexiconParams) (domain.Lexicon, error)
+	Delete(ctx context.Context, userID string, lexiconID string) error
+	// Resolve merges the user-wide lexicons, the ones scoped to storyID and
+	// the lexicon picked for the story by lexiconID, later ones winning for
+	// the same grapheme. A new story has no id to scope lexicons to before it
+	// is created, so it can only pick one.
+	Resolve(ctx context.Context, userID string, storyID string, lexiconID string) ([]domain.LexiconEntry, error)
+}
@@ -0,0 +1,17 @@
+package inbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+)
+
+type EditSegmentTextParams struct {
+	StoryID   string
+	SegmentID string
+	UserID    string
+	Text      string
+}
+
+type SegmentEditorPort interface {
+	EditText(ctx context.Context, params EditSegmentTextParams) (domain.EditedSegment, error)
+}
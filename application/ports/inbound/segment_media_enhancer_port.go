@@ -5,6 +5,16 @@ import (
 	"generate-script-lambda/domain"
 )
 
+type EnhanceSegmentsParams struct {
+	VoiceID       string
+	VoiceSettings *domain.VoiceSettingsOverride
+	Language      string
+	ImageStyle    string
+	UserID        string
+	Lexicon       []domain.LexiconEntry
+	AudioChunks   chan<- domain.AudioChunk
+}
+
 type SegmentMediaEnhancerPort interface {
-	Enhance(context context.Context, segmentCh <-chan domain.Segment, voiceID string) (<-chan domain.SegmentWithMedia, <-chan error)
+	Enhance(context context.Context, segmentCh <-chan domain.Segment, params EnhanceSegmentsParams) (<-chan domain.SegmentWithMedia, <-chan error)
 }
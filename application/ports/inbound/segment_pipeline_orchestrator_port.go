@@ -8,10 +8,23 @@ import (
 type StartPipelineParams struct {
 	StoryID string
 	Input   string
-	VoiceID string
-	UserID  string
+	// Source selects how Input is turned into segments; an empty source is
+	// a prompt for the LLM.
+	Source        domain.StorySource
+	SuggestScenes bool
+	VoiceID       string
+	VoiceSettings *domain.VoiceSettingsOverride
+	Language      string
+	LexiconID     string
+	UserID        string
+	// AudioChunks, when set, switches audio generation to streaming mode and
+	// receives the audio bytes as they arrive from the provider.
+	AudioChunks chan<- domain.AudioChunk
 }
 
 type SegmentPipelineOrchestrator interface {
-	StartPipeline(ctx context.Context, request StartPipelineParams) (<-chan domain.SegmentEvent, <-chan error)
+	// ValidateInput rejects input there is nothing to narrate from, before a
+	// story is started for it.
+	ValidateInput(source domain.StorySource, input string) error
+	StartPipeline(ctx context.Context, request StartPipelineParams) (<-chan domain.SegmentEvent, <-chan domain.StoryAssets, <-chan error)
 }
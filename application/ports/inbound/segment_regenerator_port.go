@@ -0,0 +1,22 @@
+package inbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+)
+
+// RegenerateSegmentParams optionally replaces the segment's text, which is the
+// image description for image segments, and for audio segments the voice the
+// story was narrated with.
+type RegenerateSegmentParams struct {
+	StoryID       string
+	SegmentID     string
+	UserID        string
+	Text          string
+	VoiceID       string
+	VoiceSettings *domain.VoiceSettingsOverride
+}
+
+type SegmentRegeneratorPort interface {
+	Regenerate(ctx context.Context, params RegenerateSegmentParams) (domain.SegmentEvent, error)
+}
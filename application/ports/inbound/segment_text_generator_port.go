@@ -8,6 +8,9 @@ import (
 type GenerateSegmentsParams struct {
 	Input   string
 	StoryID string
+	// SuggestScenes asks the LLM to describe the manuscript scenes that have
+	// no description of their own.
+	SuggestScenes bool
 }
 
 type SegmentsGeneratorPort interface {
@@ -0,0 +1,15 @@
+package inbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+)
+
+type AssembleStoryAudioParams struct {
+	StoryID string
+	UserID  string
+}
+
+type StoryAudioAssemblerPort interface {
+	Assemble(ctx context.Context, segmentCh <-chan domain.SegmentWithMedia, params AssembleStoryAudioParams) (<-chan domain.StoryAssets, <-chan error)
+}
@@ -0,0 +1,17 @@
+package inbound
+
+import (
+	"context"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+)
+
+type StoryDeliveryPort interface {
+	// Deliver records the story in the outbox and returns; the Story API
+	// receives it in the background. It fails only when the outbox cannot
+	// be written.
+	Deliver(ctx context.Context, params outbound.SaveStoryParams) error
+	// Run dispatches due outbox entries until ctx is done.
+	Run(ctx context.Context)
+	Depth(ctx context.Context) (domain.OutboxDepth, error)
+}
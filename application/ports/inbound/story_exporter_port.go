@@ -0,0 +1,31 @@
+package inbound
+
+import (
+	"context"
+	"io"
+)
+
+type ExportFormat string
+
+const (
+	ZipExportFormat  ExportFormat = "zip"
+	EpubExportFormat ExportFormat = "epub"
+)
+
+type ExportStoryParams struct {
+	StoryID string
+	UserID  string
+	Format  ExportFormat
+}
+
+// StoryExport is resolved before anything is written, so lookup errors can
+// still be reported with a proper status; Write then streams the archive.
+type StoryExport struct {
+	FileName    string
+	ContentType string
+	Write       func(w io.Writer) error
+}
+
+type StoryExporterPort interface {
+	Export(ctx context.Context, params ExportStoryParams) (StoryExport, error)
+}
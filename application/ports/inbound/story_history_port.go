@@ -0,0 +1,44 @@
+package inbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+)
+
+type StartStoryParams struct {
+	StoryID    string
+	UserID     string
+	Parameters domain.StoryParameters
+	RemixOf    string
+}
+
+type CompleteStoryParams struct {
+	StoryID  string
+	UserID   string
+	Segments []domain.SegmentEvent
+	Assets   domain.StoryAssets
+}
+
+type RecordEditParams struct {
+	StoryID string
+	UserID  string
+	Edit    domain.SegmentEdit
+	// Segment is the regenerated segment, which replaces the recorded one.
+	Segment domain.SegmentEvent
+}
+
+type ListStoriesParams struct {
+	UserID    string
+	SortBy    domain.StorySortField
+	Ascending bool
+	Limit     int
+	Cursor    string
+}
+
+type StoryHistoryPort interface {
+	Start(ctx context.Context, params StartStoryParams) error
+	Complete(ctx context.Context, params CompleteStoryParams) error
+	Fail(ctx context.Context, storyID string, userID string) error
+	RecordEdit(ctx context.Context, params RecordEditParams) error
+	List(ctx context.Context, params ListStoriesParams) (domain.StoryPage, error)
+}
@@ -0,0 +1,18 @@
+package inbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+)
+
+type ListSegmentsParams struct {
+	StoryID string
+	UserID  string
+	Type    domain.SegmentType
+	Limit   int
+	Cursor  string
+}
+
+type StoryReaderPort interface {
+	ListSegments(ctx context.Context, params ListSegmentsParams) (domain.SegmentPage, error)
+}
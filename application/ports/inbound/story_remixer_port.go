@@ -0,0 +1,31 @@
+package inbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+)
+
+// RemixStoryParams needs a VoiceID, an ImageStyle or both; whatever is left
+// out is copied from the source story as is.
+type RemixStoryParams struct {
+	SourceStoryID string
+	StoryID       string
+	UserID        string
+	VoiceID       string
+	VoiceSettings *domain.VoiceSettingsOverride
+	ImageStyle    string
+}
+
+// StoryRemix streams the variant's segments like a freshly generated story.
+// Parameters are the variant's own, derived from Source.
+type StoryRemix struct {
+	Source     domain.Story
+	Parameters domain.StoryParameters
+	Segments   <-chan domain.SegmentEvent
+	Assets     <-chan domain.StoryAssets
+	Errors     <-chan error
+}
+
+type StoryRemixerPort interface {
+	Remix(ctx context.Context, params RemixStoryParams) (StoryRemix, error)
+}
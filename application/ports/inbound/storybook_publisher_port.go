@@ -0,0 +1,16 @@
+package inbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+)
+
+type PublishStorybookParams struct {
+	StoryID string
+	UserID  string
+	Tenant  string
+}
+
+type StorybookPublisherPort interface {
+	Publish(ctx context.Context, params PublishStorybookParams) (domain.PublishedStorybook, error)
+}
@@ -0,0 +1,18 @@
+package inbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+)
+
+type VoiceFilter struct {
+	Provider string
+	Language string
+	Gender   string
+	Age      string
+}
+
+type VoiceCatalogServicePort interface {
+	List(ctx context.Context, filter VoiceFilter) ([]domain.Voice, error)
+	Validate(ctx context.Context, voiceID string) error
+}
@@ -1,12 +1,54 @@
 package outbound
 
-import "context"
+import (
+	"context"
+	"generate-script-lambda/domain"
+	"io"
+)
+
+type AudioFormat string
+
+const (
+	AudioFormatMP3  AudioFormat = "mp3"
+	AudioFormatWAV  AudioFormat = "wav"
+	AudioFormatOpus AudioFormat = "opus"
+	AudioFormatPCM  AudioFormat = "pcm"
+)
 
 type GenerateAudioParams struct {
-	Text    string
-	VoiceID string
+	Text          string
+	Speech        domain.Speech
+	VoiceID       string
+	VoiceSettings *domain.VoiceSettings
+	OutputFormat  AudioFormat
+	Language      string
+}
+
+var audioMimeTypes = map[AudioFormat]string{
+	AudioFormatMP3:  "audio/mpeg",
+	AudioFormatWAV:  "audio/wav",
+	AudioFormatOpus: "audio/ogg",
+	AudioFormatPCM:  "audio/L16",
+}
+
+func (f AudioFormat) MimeType() string {
+	return audioMimeTypes[f]
+}
+
+type GeneratedAudio struct {
+	Content   []byte
+	Format    AudioFormat
+	Provider  string
+	Alignment *domain.Alignment
+}
+
+type AudioStream struct {
+	Content  io.ReadCloser
+	Format   AudioFormat
+	Provider string
 }
 
 type AudioGeneratorPort interface {
-	Generate(ctx context.Context, generateAudioParams GenerateAudioParams) ([]byte, error)
+	Generate(ctx context.Context, generateAudioParams GenerateAudioParams) (GeneratedAudio, error)
+	GenerateStream(ctx context.Context, generateAudioParams GenerateAudioParams) (AudioStream, error)
 }
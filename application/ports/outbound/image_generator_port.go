@@ -2,6 +2,19 @@ package outbound
 
 import "context"
 
+// DefaultImageStyle is used when a story does not ask for a style.
+const DefaultImageStyle = "cartoon"
+
+type GenerateImageParams struct {
+	Description string
+	Style       string
+}
+
+type GeneratedImage struct {
+	Content  []byte
+	Provider string
+}
+
 type ImageGeneratorPort interface {
-	Generate(ctx context.Context, description string) ([]byte, error)
+	Generate(ctx context.Context, generateImageParams GenerateImageParams) (GeneratedImage, error)
 }
@@ -0,0 +1,13 @@
+package outbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+)
+
+type LexiconRepositoryPort interface {
+	Save(ctx context.Context, lexicon domain.Lexicon) error
+	Get(ctx context.Context, userID string, lexiconID string) (domain.Lexicon, error)
+	List(ctx context.Context, userID string) ([]domain.Lexicon, error)
+	Delete(ctx context.Context, userID string, lexiconID string) error
+}
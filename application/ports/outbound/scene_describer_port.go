@@ -0,0 +1,8 @@
+package outbound
+
+import "context"
+
+type SceneDescriberPort interface {
+	// Describe suggests a one sentence illustration prompt for a passage.
+	Describe(ctx context.Context, passage string) (string, error)
+}
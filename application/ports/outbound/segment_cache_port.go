@@ -5,6 +5,19 @@ import (
 	"generate-script-lambda/domain"
 )
 
+// SegmentQuery selects the segments of a story owned by UserID. An empty
+// Type matches every segment type.
+type SegmentQuery struct {
+	StoryID string
+	UserID  string
+	Type    domain.SegmentType
+}
+
 type SegmentCachePort interface {
-	Save(ctx context.Context, segment domain.SegmentWithMediaUrl) error
+	Save(ctx context.Context, segment domain.SegmentWithMediaUrl, userID string) error
+	// SaveBatch makes a single batch write attempt and returns the segments
+	// the store did not get to; retrying them is up to the caller.
+	SaveBatch(ctx context.Context, segments []domain.SegmentWithMediaUrl, userID string) ([]domain.SegmentWithMediaUrl, error)
+	List(ctx context.Context, storyID string) ([]domain.SegmentWithMediaUrl, error)
+	Query(ctx context.Context, query SegmentQuery) ([]domain.SegmentWithMediaUrl, error)
 }
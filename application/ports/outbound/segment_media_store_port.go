@@ -3,8 +3,18 @@ package outbound
 import (
 	"context"
 	"generate-script-lambda/domain"
+	"io"
 )
 
 type SegmentMediaStorePort interface {
 	Save(ctx context.Context, segment domain.SegmentWithMedia, userID string) (string, error)
+	SaveStream(ctx context.Context, segment domain.SegmentWithMedia, content io.Reader, userID string) (string, error)
+	SaveAttachment(ctx context.Context, segment domain.Segment, extension string, content []byte, userID string) (string, error)
+	FindMedia(ctx context.Context, storyID string, segmentID string, userID string) (domain.MediaLinks, error)
+	// ArchiveMedia copies the segment's current media and attachments aside
+	// as segment.CurrentVersion(), so they survive being overwritten.
+	ArchiveMedia(ctx context.Context, segment domain.Segment, userID string) error
+	FindMediaVersion(ctx context.Context, storyID string, segmentID string, version int, userID string) (domain.MediaLinks, error)
+	ListMedia(ctx context.Context, storyID string, userID string) ([]domain.MediaObject, error)
+	OpenMedia(ctx context.Context, object domain.MediaObject, userID string) (io.ReadCloser, error)
 }
@@ -0,0 +1,32 @@
+package outbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+	"time"
+)
+
+// StoryOutboxEntry is a story save request waiting for the Story API.
+type StoryOutboxEntry struct {
+	ID            string
+	Story         SaveStoryParams
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	DeadLettered  bool
+	CreatedAt     time.Time
+}
+
+type StoryOutboxPort interface {
+	Add(ctx context.Context, entry StoryOutboxEntry) error
+	// Due lists pending entries whose next attempt is at or before now,
+	// oldest first.
+	Due(ctx context.Context, now time.Time, limit int) ([]StoryOutboxEntry, error)
+	// Claim leases a due entry by moving its next attempt to leaseUntil,
+	// unless another dispatcher has claimed or removed it since it was
+	// listed, which is reported as domain.ErrNotFound.
+	Claim(ctx context.Context, entry StoryOutboxEntry, leaseUntil time.Time) error
+	Update(ctx context.Context, entry StoryOutboxEntry) error
+	Remove(ctx context.Context, id string) error
+	Depth(ctx context.Context) (domain.OutboxDepth, error)
+}
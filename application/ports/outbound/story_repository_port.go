@@ -0,0 +1,24 @@
+package outbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+	"time"
+)
+
+type StoryListQuery struct {
+	UserID    string
+	SortBy    domain.StorySortField
+	Ascending bool
+	Limit     int
+	Cursor    string
+}
+
+type StoryRepositoryPort interface {
+	Save(ctx context.Context, story domain.Story) error
+	// Update saves a story only while its stored UpdatedAt still equals
+	// previousUpdatedAt and returns domain.ErrConflict otherwise.
+	Update(ctx context.Context, story domain.Story, previousUpdatedAt time.Time) error
+	Get(ctx context.Context, userID string, storyID string) (domain.Story, error)
+	List(ctx context.Context, query StoryListQuery) (domain.StoryPage, error)
+}
@@ -1,11 +1,23 @@
 package outbound
 
-import "context"
+import (
+	"context"
+	"generate-script-lambda/domain"
+	"time"
+)
 
+// SaveStoryParams hands a finished story to the Story API. Segments are in
+// reading order.
 type SaveStoryParams struct {
-	ID     string
-	UserID string
-	Input  string
+	ID          string
+	UserID      string
+	Input       string
+	RemixOf     string
+	Parameters  domain.StoryParameters
+	Segments    []domain.SegmentEvent
+	Assets      domain.StoryAssets
+	StartedAt   time.Time
+	CompletedAt time.Time
 }
 
 type StorySaverPort interface {
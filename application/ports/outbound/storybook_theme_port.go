@@ -0,0 +1,7 @@
+package outbound
+
+import "context"
+
+type StorybookThemePort interface {
+	FindTemplate(ctx context.Context, tenant string) (string, error)
+}
@@ -0,0 +1,10 @@
+package outbound
+
+import (
+	"context"
+	"generate-script-lambda/domain"
+)
+
+type VoiceCatalogPort interface {
+	ListVoices(ctx context.Context) ([]domain.Voice, error)
+}
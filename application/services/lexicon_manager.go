@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"github.com/google/uuid"
+	"time"
+)
+
+type lexiconManager struct {
+	logger     outbound.LoggerPort
+	repository outbound.LexiconRepositoryPort
+}
+
+func NewLexiconManager(logger outbound.LoggerPort, repository outbound.LexiconRepositoryPort) inbound.LexiconManagerPort {
+	return &lexiconManager{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+func (l *lexiconManager) Create(ctx context.Context, userID string, params inbound.LexiconParams) (domain.Lexicon, error) {
+	entries, err := l.validateEntries(params.Entries)
+	if err != nil {
+		return domain.Lexicon{}, err
+	}
+
+	now := time.Now().UTC()
+	lexicon := domain.Lexicon{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		StoryID:   params.StoryID,
+		Name:      params.Name,
+		Entries:   entries,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err = l.repository.Save(ctx, lexicon)
+	if err != nil {
+		return domain.Lexicon{}, err
+	}
+
+	return lexicon, nil
+}
+
+func (l *lexiconManager) Get(ctx context.Context, userID string, lexiconID string) (domain.Lexicon, error) {
+	return l.repository.Get(ctx, userID, lexiconID)
+}
+
+func (l *lexiconManager) List(ctx context.Context, userID string) ([]domain.Lexicon, error) {
+	return l.repository.List(ctx, userID)
+}
+
+func (l *lexiconManager) Update(ctx context.Context, userID string, lexiconID string, params inbound.LexiconParams) (domain.Lexicon, error) {
+	entries, err := l.validateEntries(params.Entries)
+	if err != nil {
+		return domain.Lexicon{}, err
+	}
+
+	lexicon, err := l.repository.Get(ctx, userID, lexiconID)
+	if err != nil {
+		return domain.Lexicon{}, err
+	}
+
+	lexicon.Name = params.Name
+	lexicon.StoryID = params.StoryID
+	lexicon.Entries = entries
+	lexicon.UpdatedAt = time.Now().UTC()
+
+	err = l.repository.Save(ctx, lexicon)
+	if err != nil {
+		return domain.Lexicon{}, err
+	}
+
+	return lexicon, nil
+}
+
+func (l *lexiconManager) Delete(ctx context.Context, userID string, lexiconID string) error {
+	_, err := l.repository.Get(ctx, userID, lexiconID)
+	if err != nil {
+		return err
+	}
+
+	return l.repository.Delete(ctx, userID, lexiconID)
+}
+
+func (l *lexiconManager) Resolve(ctx context.Context, userID string, storyID string, lexiconID string) ([]domain.LexiconEntry, error) {
+	lexicons, err := l.repository.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	userEntries := make([]domain.LexiconEntry, 0)
+	storyEntries := make([]domain.LexiconEntry, 0)
+	pickedEntries := make([]domain.LexiconEntry, 0)
+	picked := false
+	for _, lexicon := range lexicons {
+		if lexicon.ID == lexiconID {
+			pickedEntries = lexicon.Entries
+			picked = true
+		} else if lexicon.StoryID == "" {
+			userEntries = append(userEntries, lexicon.Entries...)
+		} else if lexicon.StoryID == storyID {
+			storyEntries = append(storyEntries, lexicon.Entries...)
+		}
+	}
+	// The lexicon is checked when the story is created; one deleted since
+	// then no longer blocks regenerating the story's audio.
+	if lexiconID != "" && !picked {
+		l.logger.WarnWithFields("Picked lexicon no longer exists", map[string]interface{}{
+			"story_id":   storyID,
+			"lexicon_id": lexiconID,
+		})
+	}
+
+	// Story entries and then the picked lexicon come last so they override
+	// user-wide ones for the same grapheme.
+	return mergeLexiconEntries(userEntries, storyEntries, pickedEntries), nil
+}
+
+func (l *lexiconManager) validateEntries(entries []domain.LexiconEntry) ([]domain.LexiconEntry, error) {
+	validated := make([]domain.LexiconEntry, len(entries))
+	for i, entry := range entries {
+		if entry.Grapheme == "" {
+			return nil, fmt.Errorf("%w: entry %d has no grapheme", domain.ErrInvalidInput, i)
+		}
+		if entry.Alias == "" && entry.Phoneme == "" {
+			return nil, fmt.Errorf("%w: entry %q needs an alias or a phoneme", domain.ErrInvalidInput, entry.Grapheme)
+		}
+		if entry.Phoneme != "" {
+			if entry.Alphabet == "" {
+				entry.Alphabet = domain.IpaAlphabet
+			}
+			if entry.Alphabet != domain.IpaAlphabet && entry.Alphabet != domain.CmuArpabetAlphabet {
+				return nil, fmt.Errorf("%w: entry %q has unsupported alphabet %q", domain.ErrInvalidInput, entry.Grapheme, entry.Alphabet)
+			}
+		}
+		validated[i] = entry
+	}
+
+	return validated, nil
+}
+
+func mergeLexiconEntries(entrySets ...[]domain.LexiconEntry) []domain.LexiconEntry {
+	indexByGrapheme := make(map[string]int)
+	merged := make([]domain.LexiconEntry, 0)
+	for _, entries := range entrySets {
+		for _, entry := range entries {
+			if index, ok := indexByGrapheme[entry.Grapheme]; ok {
+				merged[index] = entry
+				continue
+			}
+			indexByGrapheme[entry.Grapheme] = len(merged)
+			merged = append(merged, entry)
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/adapters"
+	"path/filepath"
+	"testing"
+)
+
+func TestLexiconManager_Resolve(t *testing.T) {
+	ctx := context.Background()
+	logger := adapters.NewZerologWrapper()
+	repository, err := adapters.NewSqliteLexiconRepository(filepath.Join(t.TempDir(), "lexicons.db"), logger)
+	if err != nil {
+		t.Fatal("Failed to create lexicon repository:", err)
+	}
+	manager := NewLexiconManager(logger, repository)
+
+	create := func(params inbound.LexiconParams) domain.Lexicon {
+		lexicon, err := manager.Create(ctx, "user-1", params)
+		if err != nil {
+			t.Fatal("Failed to create lexicon:", err)
+		}
+		return lexicon
+	}
+	create(inbound.LexiconParams{Name: "names", Entries: []domain.LexiconEntry{
+		{Grapheme: "Siobhan", Alias: "Shivawn"}, {Grapheme: "Niamh", Alias: "Neev"},
+	}})
+	create(inbound.LexiconParams{Name: "story", StoryID: "story-1", Entries: []domain.LexiconEntry{{Grapheme: "Niamh", Alias: "Nee-uv"}}})
+	picked := create(inbound.LexiconParams{Name: "picked", StoryID: "story-3", Entries: []domain.LexiconEntry{{Grapheme: "Siobhan", Alias: "Shi-vaun"}}})
+
+	tests := []struct {
+		name      string
+		storyID   string
+		lexiconID string
+		expected  map[string]string
+	}{
+		{name: "user-wide", storyID: "story-2", expected: map[string]string{"Siobhan": "Shivawn", "Niamh": "Neev"}},
+		{name: "story-scoped", storyID: "story-1", expected: map[string]string{"Siobhan": "Shivawn", "Niamh": "Nee-uv"}},
+		{name: "picked", storyID: "story-1", lexiconID: picked.ID, expected: map[string]string{"Siobhan": "Shi-vaun", "Niamh": "Nee-uv"}},
+		{name: "deleted pick", storyID: "story-2", lexiconID: "gone", expected: map[string]string{"Siobhan": "Shivawn", "Niamh": "Neev"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := manager.Resolve(ctx, "user-1", tt.storyID, tt.lexiconID)
+			if err != nil {
+				t.Fatal("Failed to resolve lexicon:", err)
+			}
+			if len(entries) != 2 {
+				t.Fatalf("expected two merged entries, got %+v", entries)
+			}
+			for _, entry := range entries {
+				if tt.expected[entry.Grapheme] != entry.Alias {
+					t.Errorf("%s: expected alias %q, got %q", entry.Grapheme, tt.expected[entry.Grapheme], entry.Alias)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	sceneMarkerRegexp    = regexp.MustCompile(`(?i)^\[scene(?:\s*:\s*(.*?))?]$`)
+	headingRegexp        = regexp.MustCompile(`^#{1,6}(?:\s+(.*?)\s*#*)?$`)
+	thematicBreakRegexp  = regexp.MustCompile(`^(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	listMarkerRegexp     = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+`)
+	markdownImageRegexp  = regexp.MustCompile(`!\[[^]]*]\([^)]*\)`)
+	markdownLinkRegexp   = regexp.MustCompile(`\[([^]]*)]\([^)]*\)`)
+	markdownStrongRegexp = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	markdownEmRegexp     = regexp.MustCompile(`(^|[^\w*])[*_]([^*_\s](?:[^*_]*[^*_\s])?)[*_]`)
+	markdownCodeRegexp   = regexp.MustCompile("`([^`]*)`")
+	htmlCommentRegexp    = regexp.MustCompile(`(?s)<!--.*?-->`)
+	terminalPunctuation  = ".!?:;"
+)
+
+// manuscriptScene is a stretch of narration between two scene breaks. The
+// description, when known, is what the scene's illustration shows.
+type manuscriptScene struct {
+	Description string
+	Paragraphs  []string
+}
+
+func (s manuscriptScene) text() string {
+	return strings.Join(s.Paragraphs, "\n\n")
+}
+
+// parseManuscript splits plain text or Markdown into scenes. A scene starts
+// at a [scene] or [scene: description] marker line, at a heading, or at a
+// thematic break such as "* * *". Headings are narrated as the scene's first
+// paragraph; the rest of the Markdown syntax is reduced to plain text.
+func parseManuscript(text string) []manuscriptScene {
+	text = htmlCommentRegexp.ReplaceAllString(strings.ReplaceAll(text, "\r\n", "\n"), "")
+
+	scenes := make([]manuscriptScene, 0)
+	current := manuscriptScene{}
+	paragraph := make([]string, 0)
+
+	endParagraph := func() {
+		if len(paragraph) > 0 {
+			current.Paragraphs = append(current.Paragraphs, strings.Join(paragraph, " "))
+			paragraph = paragraph[:0]
+		}
+	}
+	// A break right after another one, say a [scene] marker followed by a
+	// heading, still belongs to the same scene.
+	startScene := func(description string) {
+		endParagraph()
+		if len(current.Paragraphs) == 0 {
+			if description != "" {
+				current.Description = sanitizeSceneDescription(description)
+			}
+			return
+		}
+		scenes = append(scenes, current)
+		current = manuscriptScene{Description: sanitizeSceneDescription(description)}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			endParagraph()
+		case sceneMarkerRegexp.MatchString(line):
+			startScene(sceneMarkerRegexp.FindStringSubmatch(line)[1])
+		case thematicBreakRegexp.MatchString(line):
+			startScene("")
+		case headingRegexp.MatchString(line):
+			startScene("")
+			if heading := plainManuscriptText(headingRegexp.FindStringSubmatch(line)[1]); heading != "" {
+				current.Paragraphs = append(current.Paragraphs, withTerminalPunctuation(heading))
+			}
+		default:
+			line = strings.TrimSpace(strings.TrimLeft(line, ">"))
+			line = listMarkerRegexp.ReplaceAllString(line, "")
+			if plain := plainManuscriptText(line); plain != "" {
+				paragraph = append(paragraph, plain)
+			}
+		}
+	}
+	startScene("")
+
+	return scenes
+}
+
+// plainManuscriptText drops inline Markdown and turns the brackets the
+// segmenter reserves for image descriptions and placeholders into
+// parentheses.
+func plainManuscriptText(text string) string {
+	text = markdownImageRegexp.ReplaceAllString(text, "")
+	text = markdownLinkRegexp.ReplaceAllString(text, "$1")
+	text = markdownCodeRegexp.ReplaceAllString(text, "$1")
+	text = markdownStrongRegexp.ReplaceAllString(text, "$2")
+	text = markdownEmRegexp.ReplaceAllString(text, "$1$2")
+	text = strings.NewReplacer("[", "(", "]", ")", "{", "(", "}", ")").Replace(text)
+
+	return strings.TrimSpace(text)
+}
+
+func sanitizeSceneDescription(description string) string {
+	description = strings.NewReplacer("[", "", "]", "", "{", "", "}", "", "\n", " ").Replace(description)
+
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(description), `"`))
+}
+
+// withTerminalPunctuation ends a heading like a sentence so the segmenter
+// and the TTS provider pause after it.
+func withTerminalPunctuation(text string) string {
+	if strings.ContainsAny(text[len(text)-1:], terminalPunctuation) {
+		return text
+	}
+
+	return text + "."
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+)
+
+type manuscriptSegmenter struct {
+	logger         outbound.LoggerPort
+	sceneDescriber outbound.SceneDescriberPort
+	workerPool     outbound.TaskDispatcher
+}
+
+// NewManuscriptSegmenter segments user-written text instead of an LLM story.
+// The manuscript is rendered in the story script format and fed through the
+// same segmenter, so scene descriptions become image segments.
+func NewManuscriptSegmenter(logger outbound.LoggerPort, sceneDescriber outbound.SceneDescriberPort,
+	workerPool outbound.TaskDispatcher) inbound.SegmentsGeneratorPort {
+	return &manuscriptSegmenter{
+		logger:         logger,
+		sceneDescriber: sceneDescriber,
+		workerPool:     workerPool,
+	}
+}
+
+func (s *manuscriptSegmenter) Generate(ctx context.Context, params inbound.GenerateSegmentsParams) (<-chan domain.Segment, <-chan error) {
+	script := &manuscriptScriptGenerator{
+		logger:         s.logger,
+		sceneDescriber: s.sceneDescriber,
+		workerPool:     s.workerPool,
+		storyID:        params.StoryID,
+		suggestScenes:  params.SuggestScenes,
+	}
+
+	return NewSegmentTextGenerator(s.logger, script, s.workerPool).Generate(ctx, params)
+}
+
+// manuscriptScriptGenerator plays the part of the story LLM for a
+// manuscript, streaming its script one paragraph at a time.
+type manuscriptScriptGenerator struct {
+	logger         outbound.LoggerPort
+	sceneDescriber outbound.SceneDescriberPort
+	workerPool     outbound.TaskDispatcher
+	storyID        string
+	suggestScenes  bool
+}
+
+func (m *manuscriptScriptGenerator) Generate(ctx context.Context, input string) (<-chan string, <-chan error) {
+	out := make(chan string)
+	errCh := make(chan error, 1)
+
+	err := m.workerPool.Submit(func() {
+		defer close(errCh)
+		defer close(out)
+
+		scenes := parseManuscript(input)
+		if len(scenes) == 0 {
+			errCh <- fmt.Errorf("%w: the manuscript has no text to narrate", domain.ErrInvalidInput)
+			return
+		}
+
+		for i, scene := range scenes {
+			if scene.Description == "" && m.suggestScenes {
+				scene.Description = m.describe(ctx, scene)
+			}
+			if scene.Description != "" {
+				scene.Paragraphs[0] = "[" + scene.Description + "] " + scene.Paragraphs[0]
+			}
+			if i > 0 {
+				scene.Paragraphs[0] = "\n\n" + scene.Paragraphs[0]
+			}
+			for j, paragraph := range scene.Paragraphs {
+				if j > 0 {
+					paragraph = "\n\n" + paragraph
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case out <- paragraph:
+				}
+			}
+		}
+	})
+	if err != nil {
+		errCh <- err
+		close(errCh)
+		close(out)
+	}
+
+	return out, errCh
+}
+
+// describe leaves the scene without an illustration when no description can
+// be suggested; the narration does not depend on it.
+func (m *manuscriptScriptGenerator) describe(ctx context.Context, scene manuscriptScene) string {
+	description, err := m.sceneDescriber.Describe(ctx, scene.text())
+	if err != nil {
+		m.logger.WarnWithFields("failed to suggest a scene description", map[string]interface{}{
+			"story_id": m.storyID,
+			"error":    err.Error(),
+		})
+		return ""
+	}
+
+	return sanitizeSceneDescription(description)
+}
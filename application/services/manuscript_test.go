@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/adapters"
+	"github.com/panjf2000/ants/v2"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type staticSceneDescriber struct {
+	passages []string
+}
+
+func (s *staticSceneDescriber) Describe(_ context.Context, passage string) (string, error) {
+	s.passages = append(s.passages, passage)
+	if strings.Contains(passage, "storm") {
+		return "", errors.New("describer unavailable")
+	}
+
+	return `"[A quiet harbour at dawn]"`, nil
+}
+
+func TestParseManuscript(t *testing.T) {
+	manuscript := "<!-- draft -->\r\n" +
+		"Once upon a time, a **lighthouse** keeper lived alone.\n" +
+		"He kept a [logbook] of every ship.\n\n" +
+		"[scene: a stormy sea]\n" +
+		"## The Storm\n" +
+		"> The waves rose _higher_ than the tower.\n\n" +
+		"* * *\n\n" +
+		"- Morning came.\n" +
+		"[Scene]\n\n" +
+		"Read [the end](http://example.com). ![map](map.png)\n"
+
+	expected := []manuscriptScene{
+		{Paragraphs: []string{"Once upon a time, a lighthouse keeper lived alone. He kept a (logbook) of every ship."}},
+		{Description: "a stormy sea", Paragraphs: []string{"The Storm.", "The waves rose higher than the tower."}},
+		{Paragraphs: []string{"Morning came."}},
+		{Paragraphs: []string{"Read the end."}},
+	}
+
+	if scenes := parseManuscript(manuscript); !reflect.DeepEqual(scenes, expected) {
+		t.Fatalf("Unexpected scenes: %+v", scenes)
+	}
+	if scenes := parseManuscript("[scene]\n\n# \n"); len(scenes) != 0 {
+		t.Fatalf("Expected no scenes, got %+v", scenes)
+	}
+}
+
+func TestSegmentPipelineOrchestrator_ValidateInput(t *testing.T) {
+	orchestrator := NewSegmentPipelineOrchestrator(adapters.NewZerologWrapper(), nil, nil, nil, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		source domain.StorySource
+		input  string
+		valid  bool
+	}{
+		{domain.ManuscriptStorySource, "Once upon a time.", true},
+		{domain.ManuscriptStorySource, " \n\t\n", false},
+		{domain.ManuscriptStorySource, "<!-- draft -->\n[scene]\n\n---\n", false},
+		{"", " ", true},
+	}
+
+	for _, tt := range tests {
+		err := orchestrator.ValidateInput(tt.source, tt.input)
+		if tt.valid && err != nil {
+			t.Errorf("%q: unexpected error %v", tt.input, err)
+		}
+		if !tt.valid && !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("%q: expected invalid input, got %v", tt.input, err)
+		}
+	}
+}
+
+func TestManuscriptSegmenter_Generate(t *testing.T) {
+	workerPool, err := ants.NewPool(10)
+	if err != nil {
+		t.Fatal("Failed to create worker pool:", err)
+	}
+	defer workerPool.Release()
+
+	describer := &staticSceneDescriber{}
+	segmenter := NewManuscriptSegmenter(adapters.NewZerologWrapper(), describer, workerPool)
+
+	segmentCh, errCh := segmenter.Generate(context.Background(), inbound.GenerateSegmentsParams{
+		Input: "[scene: a lighthouse]\nThe keeper woke. He lit the lamp. He waited.\n\n" +
+			"# Night\nA storm came. It raged. It passed.\n\n" +
+			"# Morning\nThe sea was calm. Gulls cried. Boats sailed.",
+		StoryID:       "story-1",
+		SuggestScenes: true,
+	})
+
+	segments := make([]domain.Segment, 0)
+	for segment := range segmentCh {
+		segments = append(segments, segment)
+	}
+	for err := range errCh {
+		t.Fatal("Received an error:", err)
+	}
+
+	descriptions := make([]string, 0)
+	placeholders := make([]string, 0)
+	narration := ""
+	for _, segment := range segments {
+		if segment.StoryID != "story-1" {
+			t.Fatalf("Unexpected story id in %+v", segment)
+		}
+		if segment.Type == domain.ImageSegmentType {
+			descriptions = append(descriptions, segment.Text)
+			continue
+		}
+		placeholders = append(placeholders, domain.ImagePlaceholders(segment.Text)...)
+		narration += segment.Text
+	}
+
+	if !reflect.DeepEqual(descriptions, []string{"a lighthouse", "A quiet harbour at dawn"}) {
+		t.Fatalf("Unexpected image descriptions: %v", descriptions)
+	}
+	if len(placeholders) != 2 || len(describer.passages) != 2 {
+		t.Fatalf("Expected two placeholders and two suggestions, got %v and %v", placeholders, describer.passages)
+	}
+	if !strings.Contains(narration, "Night.") || !strings.Contains(narration, "Boats sailed.") {
+		t.Fatalf("Unexpected narration: %q", narration)
+	}
+
+	segmentCh, errCh = segmenter.Generate(context.Background(), inbound.GenerateSegmentsParams{Input: "[scene]", StoryID: "story-2"})
+	for range segmentCh {
+		t.Fatal("Expected no segments for an empty manuscript")
+	}
+	if err := <-errCh; !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("Expected an invalid input error, got %v", err)
+	}
+}
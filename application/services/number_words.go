@@ -0,0 +1,191 @@
+package services
+
+import (
+	"strings"
+)
+
+type numberScale struct {
+	value    int64
+	singular string
+	plural   string
+}
+
+var englishOnes = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
+	"eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+
+var englishTens = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+
+var englishScales = []numberScale{
+	{value: 1_000_000_000_000, singular: "trillion"},
+	{value: 1_000_000_000, singular: "billion"},
+	{value: 1_000_000, singular: "million"},
+	{value: 1_000, singular: "thousand"},
+}
+
+var englishIrregularOrdinals = map[string]string{
+	"one":    "first",
+	"two":    "second",
+	"three":  "third",
+	"five":   "fifth",
+	"eight":  "eighth",
+	"nine":   "ninth",
+	"twelve": "twelfth",
+}
+
+func englishCardinal(n int64) string {
+	switch {
+	case n < 0:
+		return "minus " + englishCardinal(-n)
+	case n < 20:
+		return englishOnes[n]
+	case n < 100:
+		if n%10 == 0 {
+			return englishTens[n/10]
+		}
+		return englishTens[n/10] + "-" + englishOnes[n%10]
+	case n < 1000:
+		words := englishOnes[n/100] + " hundred"
+		if n%100 != 0 {
+			words += " " + englishCardinal(n%100)
+		}
+		return words
+	}
+
+	for _, scale := range englishScales {
+		if n >= scale.value {
+			words := englishCardinal(n/scale.value) + " " + scale.singular
+			if n%scale.value != 0 {
+				words += " " + englishCardinal(n%scale.value)
+			}
+			return words
+		}
+	}
+
+	return ""
+}
+
+func englishOrdinal(n int64) string {
+	words := englishCardinal(n)
+	split := strings.LastIndexAny(words, " -") + 1
+	prefix, last := words[:split], words[split:]
+
+	if irregular, ok := englishIrregularOrdinals[last]; ok {
+		return prefix + irregular
+	}
+	if strings.HasSuffix(last, "y") {
+		return prefix + strings.TrimSuffix(last, "y") + "ieth"
+	}
+
+	return prefix + last + "th"
+}
+
+// englishYear reads years the way they are spoken: 1999 is "nineteen
+// ninety-nine" and 1905 is "nineteen oh five", while 2000-2009 keep the
+// cardinal reading.
+func englishYear(n int64) string {
+	if n < 1100 || n > 2099 || (n >= 2000 && n < 2010) {
+		return englishCardinal(n)
+	}
+
+	century, rest := n/100, n%100
+	switch {
+	case rest == 0:
+		return englishCardinal(century) + " hundred"
+	case rest < 10:
+		return englishCardinal(century) + " oh " + englishCardinal(rest)
+	default:
+		return englishCardinal(century) + " " + englishCardinal(rest)
+	}
+}
+
+var germanOnes = []string{"null", "eins", "zwei", "drei", "vier", "fünf", "sechs", "sieben", "acht", "neun", "zehn",
+	"elf", "zwölf", "dreizehn", "vierzehn", "fünfzehn", "sechzehn", "siebzehn", "achtzehn", "neunzehn"}
+
+var germanTens = []string{"", "", "zwanzig", "dreißig", "vierzig", "fünfzig", "sechzig", "siebzig", "achtzig", "neunzig"}
+
+var germanScales = []numberScale{
+	{value: 1_000_000_000_000, singular: "Billion", plural: "Billionen"},
+	{value: 1_000_000_000, singular: "Milliarde", plural: "Milliarden"},
+	{value: 1_000_000, singular: "Million", plural: "Millionen"},
+}
+
+var germanIrregularOrdinals = map[int64]string{
+	1: "erste",
+	3: "dritte",
+	7: "siebte",
+	8: "achte",
+}
+
+func germanCardinal(n int64) string {
+	if n < 0 {
+		return "minus " + germanCardinal(-n)
+	}
+	if n == 0 {
+		return germanOnes[0]
+	}
+
+	for _, scale := range germanScales {
+		if n >= scale.value {
+			count := n / scale.value
+			words := "eine " + scale.singular
+			if count > 1 {
+				words = germanCardinal(count) + " " + scale.plural
+			}
+			if n%scale.value != 0 {
+				words += " " + germanCardinal(n%scale.value)
+			}
+			return words
+		}
+	}
+
+	if n >= 1000 {
+		return germanBelowThousand(n/1000, false) + "tausend" + germanBelowThousand(n%1000, true)
+	}
+
+	return germanBelowThousand(n, true)
+}
+
+// germanBelowThousand spells 0-999 as a compound word. A trailing one is
+// "eins" only at the very end of a number, as in "hunderteins".
+func germanBelowThousand(n int64, final bool) string {
+	switch {
+	case n == 0:
+		return ""
+	case n == 1:
+		if final {
+			return "eins"
+		}
+		return "ein"
+	case n < 20:
+		return germanOnes[n]
+	case n < 100:
+		if n%10 == 0 {
+			return germanTens[n/10]
+		}
+		return germanBelowThousand(n%10, false) + "und" + germanTens[n/10]
+	default:
+		return germanBelowThousand(n/100, false) + "hundert" + germanBelowThousand(n%100, final)
+	}
+}
+
+func germanOrdinal(n int64) string {
+	if irregular, ok := germanIrregularOrdinals[n]; ok {
+		return irregular
+	}
+	if n < 20 {
+		return germanCardinal(n) + "te"
+	}
+	if rest := n % 100; rest != 0 && rest < 20 && n < 1_000_000 {
+		return germanCardinal(n-rest) + germanOrdinal(rest)
+	}
+
+	return germanCardinal(n) + "ste"
+}
+
+func germanYear(n int64) string {
+	if n < 1100 || n > 1999 {
+		return germanCardinal(n)
+	}
+
+	return germanBelowThousand(n/100, false) + "hundert" + germanBelowThousand(n%100, true)
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"strings"
+)
+
+type segmentEditor struct {
+	logger       outbound.LoggerPort
+	segmentCache outbound.SegmentCachePort
+	regenerator  inbound.SegmentRegeneratorPort
+	storyHistory inbound.StoryHistoryPort
+}
+
+func NewSegmentEditor(logger outbound.LoggerPort, segmentCache outbound.SegmentCachePort, regenerator inbound.SegmentRegeneratorPort,
+	storyHistory inbound.StoryHistoryPort) inbound.SegmentEditorPort {
+	return &segmentEditor{
+		logger:       logger,
+		segmentCache: segmentCache,
+		regenerator:  regenerator,
+		storyHistory: storyHistory,
+	}
+}
+
+// EditText re-narrates an audio segment with the story's original voice and
+// settings, then records the correction in the story's edit history. The
+// media is replaced first, so a failure to record the edit is reported
+// alongside the new segment rather than as an error.
+func (s *segmentEditor) EditText(ctx context.Context, params inbound.EditSegmentTextParams) (domain.EditedSegment, error) {
+	current, err := findSegment(ctx, s.segmentCache, params.StoryID, params.SegmentID, params.UserID)
+	if err != nil {
+		return domain.EditedSegment{}, err
+	}
+	if current.Type != domain.AudioSegmentType {
+		return domain.EditedSegment{}, fmt.Errorf("%w: only audio segments have editable text", domain.ErrInvalidInput)
+	}
+
+	text := keepImagePlaceholders(current.Text, params.Text)
+	if domain.StripImagePlaceholders(text) == "" {
+		return domain.EditedSegment{}, fmt.Errorf("%w: text is empty", domain.ErrInvalidInput)
+	}
+	if text == current.Text {
+		return domain.EditedSegment{SegmentEvent: current.ToEvent(), HistoryRecorded: true}, nil
+	}
+
+	event, err := s.regenerator.Regenerate(ctx, inbound.RegenerateSegmentParams{
+		StoryID:   params.StoryID,
+		SegmentID: params.SegmentID,
+		UserID:    params.UserID,
+		Text:      text,
+	})
+	if err != nil {
+		return domain.EditedSegment{}, err
+	}
+
+	err = s.storyHistory.RecordEdit(ctx, inbound.RecordEditParams{
+		StoryID: params.StoryID,
+		UserID:  params.UserID,
+		Edit: domain.SegmentEdit{
+			SegmentID:    params.SegmentID,
+			EditorID:     params.UserID,
+			PreviousText: current.Text,
+			Text:         text,
+			Version:      event.Version,
+		},
+		Segment: event,
+	})
+	if errors.Is(err, domain.ErrNotFound) {
+		s.logger.WarnWithFields("Story has no history record, edit not recorded", map[string]interface{}{
+			"story_id":   params.StoryID,
+			"segment_id": params.SegmentID,
+		})
+	} else if err != nil {
+		s.logger.ErrorWithFields(err, "Failed to record segment edit", map[string]interface{}{
+			"story_id":   params.StoryID,
+			"segment_id": params.SegmentID,
+		})
+	}
+
+	return domain.EditedSegment{SegmentEvent: event, HistoryRecorded: err == nil}, nil
+}
+
+// keepImagePlaceholders carries the {imageID} markers over when the
+// corrected text leaves them out, so the illustrations stay in place.
+func keepImagePlaceholders(original string, corrected string) string {
+	corrected = strings.TrimSpace(corrected)
+	placeholders := domain.ImagePlaceholders(original)
+	if len(placeholders) == 0 || len(domain.ImagePlaceholders(corrected)) > 0 {
+		return corrected
+	}
+
+	return strings.Join(placeholders, " ") + " " + corrected
+}
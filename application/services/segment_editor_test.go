@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/adapters"
+	"github.com/panjf2000/ants/v2"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type recordingAudioGenerator struct {
+	requests []outbound.GenerateAudioParams
+}
+
+func (g *recordingAudioGenerator) Generate(ctx context.Context, params outbound.GenerateAudioParams) (outbound.GeneratedAudio, error) {
+	g.requests = append(g.requests, params)
+	return outbound.GeneratedAudio{Content: []byte(params.Text), Format: outbound.AudioFormatMP3, Provider: "static"}, nil
+}
+
+func (g *recordingAudioGenerator) GenerateStream(ctx context.Context, params outbound.GenerateAudioParams) (outbound.AudioStream, error) {
+	return outbound.AudioStream{}, errors.New("streaming is not supported")
+}
+
+type emptyLexiconRepository struct{}
+
+func (emptyLexiconRepository) Save(ctx context.Context, lexicon domain.Lexicon) error { return nil }
+
+func (emptyLexiconRepository) Get(ctx context.Context, userID string, lexiconID string) (domain.Lexicon, error) {
+	return domain.Lexicon{}, domain.ErrNotFound
+}
+
+func (emptyLexiconRepository) List(ctx context.Context, userID string) ([]domain.Lexicon, error) {
+	return nil, nil
+}
+
+func (emptyLexiconRepository) Delete(ctx context.Context, userID string, lexiconID string) error {
+	return nil
+}
+
+func TestSegmentEditor_EditText(t *testing.T) {
+	ctx := context.Background()
+	workerPool, err := ants.NewPool(20)
+	if err != nil {
+		t.Fatal("Failed to create worker pool:", err)
+	}
+	defer workerPool.Release()
+
+	logger := adapters.NewZerologWrapper()
+	mediaStore := adapters.NewMemorySegmentMediaStore()
+	cache := adapters.NewMemorySegmentCache(time.Hour)
+	repository, err := adapters.NewSqliteStoryRepository(filepath.Join(t.TempDir(), "stories.db"), logger)
+	if err != nil {
+		t.Fatal("Failed to create story repository:", err)
+	}
+	storyHistory := NewStoryHistory(logger, repository, "elevenlabs", "gpt", false)
+	stability := 0.3
+	voiceSettings := &domain.VoiceSettingsOverride{Stability: &stability}
+	err = storyHistory.Start(ctx, inbound.StartStoryParams{StoryID: "story-1", UserID: "user-1", Parameters: domain.StoryParameters{
+		VoiceID: "narrator", VoiceSettings: voiceSettings, Language: "en",
+	}})
+	if err != nil {
+		t.Fatal("Failed to start story:", err)
+	}
+
+	events := make([]domain.SegmentEvent, 0)
+	for _, segment := range []domain.Segment{
+		domain.NewSegment("{img-1} Once apon a time.", domain.AudioSegmentType, "audio-1", "story-1", 0),
+		domain.NewSegment("a castle", domain.ImageSegmentType, "img-1", "story-1", 0),
+	} {
+		url, _ := mediaStore.Save(ctx, domain.SegmentWithMedia{MediaContent: []byte(segment.Text), Segment: segment}, "user-1")
+		stored := domain.SegmentWithMediaUrl{MediaURL: url, Segment: segment}
+		_ = cache.Save(ctx, stored, "user-1")
+		events = append(events, stored.ToEvent())
+	}
+	if err = storyHistory.Complete(ctx, inbound.CompleteStoryParams{StoryID: "story-1", UserID: "user-1", Segments: events}); err != nil {
+		t.Fatal("Failed to complete story:", err)
+	}
+
+	audioGenerator := &recordingAudioGenerator{}
+	enhancer := NewSegmentMediaEnhancer(logger, nil, audioGenerator, mediaStore, workerPool, outbound.AudioFormatMP3,
+		domain.VoiceSettings{Stability: 0.5, SimilarityBoost: 0.75})
+	saver := NewSegmentMediaSaver(logger, mediaStore, workerPool, 2)
+	regenerator := NewSegmentRegenerator(logger, workerPool, cache, mediaStore, repository, NewLexiconManager(logger, emptyLexiconRepository{}),
+		enhancer, saver)
+	editor := NewSegmentEditor(logger, cache, regenerator, storyHistory)
+
+	_, err = editor.EditText(ctx, inbound.EditSegmentTextParams{StoryID: "story-1", SegmentID: "img-1", UserID: "user-1", Text: "a tower"})
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("Expected image segments to be rejected, got %v", err)
+	}
+
+	event, err := editor.EditText(ctx, inbound.EditSegmentTextParams{
+		StoryID: "story-1", SegmentID: "audio-1", UserID: "user-1", Text: " Once upon a time. ",
+	})
+	if err != nil {
+		t.Fatal("Failed to edit segment:", err)
+	}
+	if event.Text != "{img-1} Once upon a time." || event.Version != 2 || !event.HistoryRecorded {
+		t.Fatalf("Unexpected edited segment: %+v", event)
+	}
+	if len(event.Words) != 4 || event.Words[1].Word != "upon" {
+		t.Fatalf("Expected timings for the corrected text, got %+v", event.Words)
+	}
+
+	if len(audioGenerator.requests) != 1 {
+		t.Fatalf("Expected one narration, got %d", len(audioGenerator.requests))
+	}
+	request := audioGenerator.requests[0]
+	if request.VoiceID != "narrator" || request.Language != "en" || request.Text != "Once upon a time." ||
+		request.VoiceSettings == nil || request.VoiceSettings.Stability != stability || request.VoiceSettings.SimilarityBoost != 0.75 {
+		t.Fatalf("Expected the story's voice and settings, got %+v", request)
+	}
+
+	cached, _ := cache.Query(ctx, outbound.SegmentQuery{StoryID: "story-1", UserID: "user-1", Type: domain.AudioSegmentType})
+	if len(cached) != 1 || cached[0].Alignment == nil || len(cached[0].Alignment.Words) != 4 {
+		t.Fatalf("Expected the stored timings to be updated, got %+v", cached)
+	}
+
+	story, err := repository.Get(ctx, "user-1", "story-1")
+	if err != nil {
+		t.Fatal("Failed to get story:", err)
+	}
+	if len(story.Edits) != 1 {
+		t.Fatalf("Expected one edit history entry, got %+v", story.Edits)
+	}
+	edit := story.Edits[0]
+	if edit.EditorID != "user-1" || edit.PreviousText != "{img-1} Once apon a time." || edit.Version != 2 || edit.EditedAt.IsZero() {
+		t.Fatalf("Unexpected edit history entry: %+v", edit)
+	}
+	if recorded := story.Segments[0]; recorded.Text != "{img-1} Once upon a time." || recorded.Version != 2 || len(recorded.Words) != 4 {
+		t.Fatalf("Expected the recorded segment to be replaced, got %+v", recorded)
+	}
+}
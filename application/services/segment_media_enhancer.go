@@ -1,44 +1,62 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"generate-script-lambda/application/ports/inbound"
 	"generate-script-lambda/application/ports/outbound"
 	"generate-script-lambda/domain"
+	"generate-script-lambda/media_utils"
+	"io"
 	"regexp"
-	"strings"
 	"sync"
 )
 
+type streamUploadResult struct {
+	url string
+	err error
+}
+
 type segmentMediaEnhancer struct {
 	logger         outbound.LoggerPort
 	imageGenerator outbound.ImageGeneratorPort
 	audioGenerator outbound.AudioGeneratorPort
+	mediaStore     outbound.SegmentMediaStorePort
 	workerPool     outbound.TaskDispatcher
+	outputFormat   outbound.AudioFormat
+	voiceSettings  domain.VoiceSettings
 	imageRegexp    *regexp.Regexp
 }
 
 func NewSegmentMediaEnhancer(logger outbound.LoggerPort, imageGenerator outbound.ImageGeneratorPort, audioGenerator outbound.AudioGeneratorPort,
-	workerPool outbound.TaskDispatcher) inbound.SegmentMediaEnhancerPort {
+	mediaStore outbound.SegmentMediaStorePort, workerPool outbound.TaskDispatcher, outputFormat outbound.AudioFormat,
+	voiceSettings domain.VoiceSettings) inbound.SegmentMediaEnhancerPort {
 	return &segmentMediaEnhancer{
 		logger:         logger,
 		imageGenerator: imageGenerator,
 		audioGenerator: audioGenerator,
+		mediaStore:     mediaStore,
 		workerPool:     workerPool,
+		outputFormat:   outputFormat,
+		voiceSettings:  voiceSettings,
 		imageRegexp:    regexp.MustCompile(`\{[^}]*}`),
 	}
 }
 
-func (s *segmentMediaEnhancer) Enhance(ctx context.Context, segmentCh <-chan domain.Segment, voiceID string) (<-chan domain.SegmentWithMedia, <-chan error) {
+func (s *segmentMediaEnhancer) Enhance(ctx context.Context, segmentCh <-chan domain.Segment, params inbound.EnhanceSegmentsParams) (<-chan domain.SegmentWithMedia, <-chan error) {
 	out := make(chan domain.SegmentWithMedia)
 	errCh := make(chan error)
 
 	newCtx, cancel := context.WithCancel(ctx)
+	lexicon := newLexiconMatcher(params.Lexicon)
 
 	err := s.workerPool.Submit(func() {
 		defer close(out)
 		defer close(errCh)
 		defer cancel()
+		if params.AudioChunks != nil {
+			defer close(params.AudioChunks)
+		}
 
 		var wg sync.WaitGroup
 
@@ -63,7 +81,7 @@ func (s *segmentMediaEnhancer) Enhance(ctx context.Context, segmentCh <-chan dom
 							"type":       segment.Type,
 						})
 						if segment.Type == domain.ImageSegmentType {
-							result, err := s.useImageGenerator(newCtx, segment)
+							result, err := s.useImageGenerator(newCtx, segment, params.ImageStyle)
 							if err != nil {
 								s.logger.ErrorWithFields(err, "Failed to generate image", map[string]interface{}{
 									"description": segment.Text,
@@ -71,10 +89,11 @@ func (s *segmentMediaEnhancer) Enhance(ctx context.Context, segmentCh <-chan dom
 								})
 								errCh <- err
 								cancel()
+								return
 							}
-							out <- result
+							s.send(newCtx, out, result)
 						} else if segment.Type == domain.AudioSegmentType {
-							result, err := s.useAudioGenerator(newCtx, segment, voiceID)
+							result, err := s.useAudioGenerator(newCtx, segment, params, lexicon)
 							if err != nil {
 								s.logger.ErrorWithFields(err, "Failed to generate audio", map[string]interface{}{
 									"text":       segment.Text,
@@ -82,8 +101,9 @@ func (s *segmentMediaEnhancer) Enhance(ctx context.Context, segmentCh <-chan dom
 								})
 								errCh <- err
 								cancel()
+								return
 							}
-							out <- result
+							s.send(newCtx, out, result)
 						}
 					}
 				})
@@ -105,41 +125,192 @@ func (s *segmentMediaEnhancer) Enhance(ctx context.Context, segmentCh <-chan dom
 	return out, errCh
 }
 
-func (s *segmentMediaEnhancer) useImageGenerator(newCtx context.Context, segment domain.Segment) (domain.SegmentWithMedia, error) {
-	content, err := s.imageGenerator.Generate(newCtx, segment.Text)
+func (s *segmentMediaEnhancer) send(ctx context.Context, out chan<- domain.SegmentWithMedia, segment domain.SegmentWithMedia) {
+	select {
+	case <-ctx.Done():
+	case out <- segment:
+	}
+}
+
+func (s *segmentMediaEnhancer) useImageGenerator(newCtx context.Context, segment domain.Segment, style string) (domain.SegmentWithMedia, error) {
+	image, err := s.imageGenerator.Generate(newCtx, outbound.GenerateImageParams{
+		Description: segment.Text,
+		Style:       style,
+	})
 	if err != nil {
 		return domain.SegmentWithMedia{}, err
 	}
 	return domain.SegmentWithMedia{
-		MediaContent: content,
+		MediaContent: image.Content,
+		Provider:     image.Provider,
 		Segment:      segment,
 	}, nil
 }
 
-func (s *segmentMediaEnhancer) useAudioGenerator(newCtx context.Context, segment domain.Segment, voiceID string) (domain.SegmentWithMedia, error) {
-	preparedText := s.prepareTextForTTS(segment.Text)
-	content, err := s.audioGenerator.Generate(newCtx, outbound.GenerateAudioParams{Text: preparedText, VoiceID: voiceID})
+func (s *segmentMediaEnhancer) useAudioGenerator(newCtx context.Context, segment domain.Segment, params inbound.EnhanceSegmentsParams,
+	lexicon *lexiconMatcher) (domain.SegmentWithMedia, error) {
+	speech := s.prepareTextForTTS(segment.Text, lexicon, params.Language)
+	preparedText := speech.PlainText()
+	generateParams := outbound.GenerateAudioParams{
+		Text:          preparedText,
+		Speech:        speech,
+		VoiceID:       params.VoiceID,
+		VoiceSettings: resolveVoiceSettings(params.VoiceSettings, s.voiceSettings, segment.Mood),
+		OutputFormat:  s.outputFormat,
+		Language:      params.Language,
+	}
+	if params.AudioChunks != nil {
+		return s.useAudioStream(newCtx, segment, preparedText, generateParams, params)
+	}
+
+	audio, err := s.audioGenerator.Generate(newCtx, generateParams)
 	if err != nil {
 		return domain.SegmentWithMedia{}, err
 	}
+
+	alignment := audio.Alignment
+	if alignment == nil {
+		estimated := domain.EstimateAlignment(preparedText, s.audioDurationSeconds(audio))
+		alignment = &estimated
+	}
+
 	return domain.SegmentWithMedia{
-		MediaContent: content,
+		MediaContent: audio.Content,
+		MimeType:     audio.Format.MimeType(),
+		Provider:     audio.Provider,
+		Alignment:    alignment,
 		Segment:      segment,
 	}, nil
 }
 
-func (s *segmentMediaEnhancer) prepareTextForTTS(input string) string {
-	result := s.imageRegexp.ReplaceAllString(input, "")
-	result = s.removeEmptySpaces(result)
+func (s *segmentMediaEnhancer) useAudioStream(newCtx context.Context, segment domain.Segment, preparedText string,
+	generateParams outbound.GenerateAudioParams, params inbound.EnhanceSegmentsParams) (domain.SegmentWithMedia, error) {
+	const chunkSize = 16 * 1024
+
+	stream, err := s.audioGenerator.GenerateStream(newCtx, generateParams)
+	if err != nil {
+		return domain.SegmentWithMedia{}, err
+	}
+	defer func(content io.ReadCloser) {
+		err := content.Close()
+		if err != nil {
+			s.logger.Error(err, "Failed to close the audio stream")
+		}
+	}(stream.Content)
+
+	reader, writer := io.Pipe()
+	uploadCh := make(chan streamUploadResult, 1)
+	err = s.workerPool.Submit(func() {
+		url, err := s.mediaStore.SaveStream(newCtx, domain.SegmentWithMedia{
+			MimeType: stream.Format.MimeType(),
+			Provider: stream.Provider,
+			Segment:  segment,
+		}, reader, params.UserID)
+		_ = reader.CloseWithError(err)
+		uploadCh <- streamUploadResult{url: url, err: err}
+	})
+	if err != nil {
+		return domain.SegmentWithMedia{}, err
+	}
+
+	var content bytes.Buffer
+	buffer := make([]byte, chunkSize)
+	sequence := 0
+	for {
+		n, readErr := stream.Content.Read(buffer)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buffer[:n])
+			content.Write(chunk)
+			if _, err := writer.Write(chunk); err != nil {
+				upload := <-uploadCh
+				return domain.SegmentWithMedia{}, upload.err
+			}
+			err := s.sendAudioChunk(newCtx, params.AudioChunks, domain.AudioChunk{
+				StoryID:   segment.StoryID,
+				SegmentId: segment.ID,
+				Ordinal:   segment.Ordinal,
+				Sequence:  sequence,
+				Data:      chunk,
+			})
+			if err != nil {
+				_ = writer.CloseWithError(err)
+				<-uploadCh
+				return domain.SegmentWithMedia{}, err
+			}
+			sequence++
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			_ = writer.CloseWithError(readErr)
+			<-uploadCh
+			return domain.SegmentWithMedia{}, readErr
+		}
+	}
+
+	_ = writer.Close()
+	upload := <-uploadCh
+	if upload.err != nil {
+		return domain.SegmentWithMedia{}, upload.err
+	}
+
+	err = s.sendAudioChunk(newCtx, params.AudioChunks, domain.AudioChunk{
+		StoryID:   segment.StoryID,
+		SegmentId: segment.ID,
+		Ordinal:   segment.Ordinal,
+		Sequence:  sequence,
+		Final:     true,
+	})
+	if err != nil {
+		return domain.SegmentWithMedia{}, err
+	}
+
+	alignment := domain.EstimateAlignment(preparedText, s.audioDurationSeconds(outbound.GeneratedAudio{
+		Content: content.Bytes(),
+		Format:  stream.Format,
+	}))
 
-	return result
+	return domain.SegmentWithMedia{
+		MediaContent: content.Bytes(),
+		MediaURL:     upload.url,
+		MimeType:     stream.Format.MimeType(),
+		Provider:     stream.Provider,
+		Alignment:    &alignment,
+		Segment:      segment,
+	}, nil
 }
 
-func (s *segmentMediaEnhancer) removeEmptySpaces(input string) string {
-	result := strings.Replace(input, "\n", "", -1)
-	result = strings.Replace(result, "\r", "", -1)
-	result = strings.Replace(result, "\t", "", -1)
-	result = strings.TrimSpace(result)
+func (s *segmentMediaEnhancer) sendAudioChunk(ctx context.Context, audioChunks chan<- domain.AudioChunk, chunk domain.AudioChunk) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case audioChunks <- chunk:
+		return nil
+	}
+}
+
+func (s *segmentMediaEnhancer) audioDurationSeconds(audio outbound.GeneratedAudio) float64 {
+	switch audio.Format {
+	case outbound.AudioFormatMP3:
+		duration, err := media_utils.Mp3Duration(audio.Content)
+		if err == nil {
+			return duration.Seconds()
+		}
+	case outbound.AudioFormatWAV:
+		info, err := media_utils.ParseWav(audio.Content)
+		if err == nil {
+			return info.Duration.Seconds()
+		}
+	}
+
+	return 0
+}
+
+func (s *segmentMediaEnhancer) prepareTextForTTS(input string, lexicon *lexiconMatcher, language string) domain.Speech {
+	result := s.imageRegexp.ReplaceAllString(input, "")
+	result = cleanSpeechText(result)
 
-	return result
+	return normalizeSpeech(lexicon.apply(parseSpeechMarkup(result)), language)
 }
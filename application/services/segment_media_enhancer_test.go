@@ -3,9 +3,13 @@ package services
 import (
 	"context"
 	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
 	"generate-script-lambda/channel_utils"
 	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
 	"generate-script-lambda/infrastructure/adapters"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/google/uuid"
 	"github.com/panjf2000/ants/v2"
 	"testing"
@@ -46,7 +50,26 @@ func TestSegmentMediaEnhancer_Enhance(t *testing.T) {
 
 	audioGenerator := adapters.NewAudioGenerator(fetcher, elevenLabsConfig, logger)
 
-	enhancer := NewSegmentMediaEnhancer(logger, imageGenerator, audioGenerator, workerPool)
+	s3Config, err := config.GetS3Config()
+	if err != nil {
+		t.Fatal("Failed to get s3 config:", err)
+	}
+
+	sess := session.Must(session.NewSession())
+
+	s3Client := s3.New(sess)
+
+	urlStrategy, err := adapters.NewMediaUrlStrategy(s3Client, s3Config, &config.MediaUrlConfig{Strategy: config.PublicMediaUrlStrategy})
+	if err != nil {
+		t.Fatal("Failed to create media url strategy:", err)
+	}
+
+	mediaStore := adapters.NewS3SegmentMediaStore(s3Client, s3Config, urlStrategy, logger)
+
+	enhancer := NewSegmentMediaEnhancer(logger, imageGenerator, audioGenerator, mediaStore, workerPool, outbound.AudioFormatMP3, domain.VoiceSettings{
+		Stability:       elevenLabsConfig.Stability,
+		SimilarityBoost: elevenLabsConfig.SimilarityBoost,
+	})
 
 	ctx := context.Background()
 
@@ -55,7 +78,9 @@ func TestSegmentMediaEnhancer_Enhance(t *testing.T) {
 		StoryID: uuid.NewString(),
 	})
 
-	enhancedSegmentsCh, enhancerErrCh := enhancer.Enhance(ctx, segmentsCh, "2EiwWnXFnvU5JabPnv8n")
+	enhancedSegmentsCh, enhancerErrCh := enhancer.Enhance(ctx, segmentsCh, inbound.EnhanceSegmentsParams{
+		VoiceID: "2EiwWnXFnvU5JabPnv8n",
+	})
 	mergedErrCh, err := channel_utils.MergeChannels(workerPool, generatorErrCh, enhancerErrCh)
 	if err != nil {
 		t.Fatal("Failed to merge error channels:", err)
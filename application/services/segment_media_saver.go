@@ -2,25 +2,35 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"generate-script-lambda/application/ports/inbound"
 	"generate-script-lambda/application/ports/outbound"
 	"generate-script-lambda/domain"
+	"generate-script-lambda/media_utils"
+	"net/http"
+	"sync"
 )
 
 type segmentMediaSaver struct {
-	logger     outbound.LoggerPort
-	mediaStore outbound.SegmentMediaStorePort
-	workerPool outbound.TaskDispatcher
+	logger      outbound.LoggerPort
+	mediaStore  outbound.SegmentMediaStorePort
+	workerPool  outbound.TaskDispatcher
+	concurrency int
 }
 
-func NewSegmentMediaSaver(logger outbound.LoggerPort, mediaStore outbound.SegmentMediaStorePort, workerPool outbound.TaskDispatcher) inbound.SegmentMediaSaverPort {
+func NewSegmentMediaSaver(logger outbound.LoggerPort, mediaStore outbound.SegmentMediaStorePort, workerPool outbound.TaskDispatcher,
+	concurrency int) inbound.SegmentMediaSaverPort {
 	return &segmentMediaSaver{
-		logger:     logger,
-		mediaStore: mediaStore,
-		workerPool: workerPool,
+		logger:      logger,
+		mediaStore:  mediaStore,
+		workerPool:  workerPool,
+		concurrency: concurrency,
 	}
 }
 
+// Save uploads up to concurrency segments at once. Segments come out in the
+// order their uploads finish, not the order they arrived in.
 func (s *segmentMediaSaver) Save(ctx context.Context, segmentCh <-chan domain.SegmentWithMedia, userID string) (<-chan domain.SegmentWithMediaUrl, <-chan error) {
 	out := make(chan domain.SegmentWithMediaUrl)
 	errCh := make(chan error)
@@ -31,6 +41,19 @@ func (s *segmentMediaSaver) Save(ctx context.Context, segmentCh <-chan domain.Se
 		defer close(errCh)
 		defer cancel()
 
+		var wg sync.WaitGroup
+		defer wg.Wait()
+
+		var failOnce sync.Once
+		fail := func(err error) {
+			failOnce.Do(func() {
+				errCh <- err
+				cancel()
+			})
+		}
+
+		slots := make(chan struct{}, s.concurrency)
+
 		for {
 			select {
 			case <-newCtx.Done():
@@ -39,17 +62,30 @@ func (s *segmentMediaSaver) Save(ctx context.Context, segmentCh <-chan domain.Se
 				if !ok {
 					return
 				}
-				url, err := s.mediaStore.Save(newCtx, segment, userID)
-				if err != nil {
-					errCh <- err
-					cancel()
+				select {
+				case <-newCtx.Done():
 					return
+				case slots <- struct{}{}:
 				}
 
-				out <- domain.SegmentWithMediaUrl{
-					Segment:  segment.Segment,
-					MediaURL: url,
-				}
+				// Uploads run on their own goroutines, bounded by slots: the
+				// worker pool is full of stage loops like this one waiting
+				// on them, so submitting there could block forever.
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-slots }()
+
+					saved, err := s.saveSegment(newCtx, segment, userID)
+					if err != nil {
+						fail(err)
+						return
+					}
+					select {
+					case <-newCtx.Done():
+					case out <- saved:
+					}
+				}()
 			}
 		}
 	})
@@ -60,3 +96,80 @@ func (s *segmentMediaSaver) Save(ctx context.Context, segmentCh <-chan domain.Se
 
 	return out, errCh
 }
+
+func (s *segmentMediaSaver) saveSegment(ctx context.Context, segment domain.SegmentWithMedia, userID string) (domain.SegmentWithMediaUrl, error) {
+	url := segment.MediaURL
+	if url == "" {
+		savedUrl, err := s.mediaStore.Save(ctx, segment, userID)
+		if err != nil {
+			return domain.SegmentWithMediaUrl{}, err
+		}
+		url = savedUrl
+	}
+
+	subtitles, err := s.saveSubtitles(ctx, segment, userID)
+	if err != nil {
+		return domain.SegmentWithMediaUrl{}, err
+	}
+
+	return domain.SegmentWithMediaUrl{
+		Segment:   segment.Segment,
+		MediaURL:  url,
+		Metadata:  s.probeMedia(segment),
+		Alignment: segment.Alignment,
+		Subtitles: subtitles,
+	}, nil
+}
+
+func (s *segmentMediaSaver) saveSubtitles(ctx context.Context, segment domain.SegmentWithMedia, userID string) (domain.SubtitleURLs, error) {
+	if segment.Alignment == nil || len(segment.Alignment.Words) == 0 {
+		return domain.SubtitleURLs{}, nil
+	}
+
+	cues := media_utils.BuildSubtitleCues(segment.Alignment.Words, 0)
+
+	vttUrl, err := s.mediaStore.SaveAttachment(ctx, segment.Segment, "vtt", media_utils.RenderWebVTT(cues), userID)
+	if err != nil {
+		return domain.SubtitleURLs{}, err
+	}
+
+	srtUrl, err := s.mediaStore.SaveAttachment(ctx, segment.Segment, "srt", media_utils.RenderSRT(cues), userID)
+	if err != nil {
+		return domain.SubtitleURLs{}, err
+	}
+
+	return domain.SubtitleURLs{
+		VttURL: vttUrl,
+		SrtURL: srtUrl,
+	}, nil
+}
+
+func (s *segmentMediaSaver) probeMedia(segment domain.SegmentWithMedia) *domain.MediaMetadata {
+	checksum := sha256.Sum256(segment.MediaContent)
+	metadata := &domain.MediaMetadata{
+		Size:     len(segment.MediaContent),
+		Checksum: hex.EncodeToString(checksum[:]),
+	}
+
+	info, err := media_utils.ProbeMedia(segment.MediaContent)
+	if err != nil {
+		s.logger.WarnWithFields("Failed to probe segment media", map[string]interface{}{
+			"segment_id": segment.ID,
+			"error":      err.Error(),
+		})
+		metadata.MimeType = segment.MimeType
+		if metadata.MimeType == "" {
+			metadata.MimeType = http.DetectContentType(segment.MediaContent)
+		}
+		return metadata
+	}
+
+	metadata.MimeType = info.MimeType
+	metadata.Width = info.Width
+	metadata.Height = info.Height
+	metadata.Duration = info.Duration.Seconds()
+	metadata.Bitrate = info.Bitrate
+	metadata.SampleRate = info.SampleRate
+
+	return metadata
+}
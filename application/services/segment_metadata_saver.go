@@ -2,27 +2,44 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"generate-script-lambda/application/ports/inbound"
 	"generate-script-lambda/application/ports/outbound"
 	"generate-script-lambda/domain"
+	"sync"
+	"time"
+)
+
+const (
+	maxBatchWriteAttempts = 5
+	batchRetryBaseDelay   = 50 * time.Millisecond
 )
 
 type segmentMetadataSaver struct {
 	logger       outbound.LoggerPort
 	workerPool   outbound.TaskDispatcher
 	segmentCache outbound.SegmentCachePort
+	concurrency  int
+	batchSize    int
+	batchWindow  time.Duration
 }
 
 func NewSegmentMetadataSaver(logger outbound.LoggerPort, workerPool outbound.TaskDispatcher,
-	segmentCache outbound.SegmentCachePort) inbound.SegmentMetadataSaverPort {
+	segmentCache outbound.SegmentCachePort, concurrency int, batchSize int, batchWindow time.Duration) inbound.SegmentMetadataSaverPort {
 	return &segmentMetadataSaver{
 		logger:       logger,
 		workerPool:   workerPool,
 		segmentCache: segmentCache,
+		concurrency:  concurrency,
+		batchSize:    batchSize,
+		batchWindow:  batchWindow,
 	}
 }
 
-func (s *segmentMetadataSaver) Save(ctx context.Context, segments <-chan domain.SegmentWithMediaUrl) (<-chan domain.SegmentEvent, <-chan error) {
+// Save groups incoming segments into micro-batches, closed when full or when
+// the batch window runs out, and writes up to concurrency batches at once.
+// Each segment's event goes out as soon as the write that stored it returns.
+func (s *segmentMetadataSaver) Save(ctx context.Context, segments <-chan domain.SegmentWithMediaUrl, userID string) (<-chan domain.SegmentEvent, <-chan error) {
 	out := make(chan domain.SegmentEvent)
 	errCh := make(chan error)
 
@@ -32,24 +49,69 @@ func (s *segmentMetadataSaver) Save(ctx context.Context, segments <-chan domain.
 		defer close(out)
 		defer close(errCh)
 		defer cancel()
+
+		var wg sync.WaitGroup
+		defer wg.Wait()
+
+		var failOnce sync.Once
+		fail := func(err error) {
+			failOnce.Do(func() {
+				errCh <- err
+				cancel()
+			})
+		}
+
+		slots := make(chan struct{}, s.concurrency)
+		batch := make([]domain.SegmentWithMediaUrl, 0, s.batchSize)
+		var timer *time.Timer
+		var window <-chan time.Time
+
+		flush := func() {
+			if timer != nil {
+				timer.Stop()
+				timer, window = nil, nil
+			}
+			if len(batch) == 0 {
+				return
+			}
+			toWrite := batch
+			batch = make([]domain.SegmentWithMediaUrl, 0, s.batchSize)
+
+			select {
+			case <-newCtx.Done():
+				return
+			case slots <- struct{}{}:
+			}
+			// Batch writes run on their own goroutines, bounded by slots,
+			// rather than on the worker pool this loop already occupies.
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				if err := s.writeBatch(newCtx, toWrite, userID, out); err != nil {
+					fail(err)
+				}
+			}()
+		}
+
 		for {
 			select {
 			case <-newCtx.Done():
 				return
-			case segmentWithMedia, ok := <-segments:
+			case <-window:
+				flush()
+			case segment, ok := <-segments:
 				if !ok {
+					flush()
 					return
 				}
-				err := s.segmentCache.Save(newCtx, segmentWithMedia)
-				if err != nil {
-					errCh <- err
-					return
-				} else {
-					s.logger.DebugWithFields("segment saved", map[string]interface{}{
-						"type": segmentWithMedia.Type,
-						"id":   segmentWithMedia.ID,
-					})
-					out <- segmentWithMedia.ToEvent()
+				batch = append(batch, segment)
+				if len(batch) == 1 {
+					timer = time.NewTimer(s.batchWindow)
+					window = timer.C
+				}
+				if len(batch) >= s.batchSize {
+					flush()
 				}
 			}
 		}
@@ -62,3 +124,53 @@ func (s *segmentMetadataSaver) Save(ctx context.Context, segments <-chan domain.
 
 	return out, errCh
 }
+
+// writeBatch retries whatever the store leaves unprocessed with exponential
+// backoff, emitting events for the segments each attempt did store.
+func (s *segmentMetadataSaver) writeBatch(ctx context.Context, batch []domain.SegmentWithMediaUrl, userID string,
+	out chan<- domain.SegmentEvent) error {
+	pending := batch
+	for attempt := 1; ; attempt++ {
+		unprocessed, err := s.segmentCache.SaveBatch(ctx, pending, userID)
+		if err != nil {
+			return err
+		}
+
+		left := make(map[string]bool, len(unprocessed))
+		for _, segment := range unprocessed {
+			left[segment.ID] = true
+		}
+		for _, segment := range pending {
+			if left[segment.ID] {
+				continue
+			}
+			s.logger.DebugWithFields("segment saved", map[string]interface{}{
+				"type": segment.Type,
+				"id":   segment.ID,
+			})
+			select {
+			case <-ctx.Done():
+				return nil
+			case out <- segment.ToEvent():
+			}
+		}
+
+		if len(unprocessed) == 0 {
+			return nil
+		}
+		if attempt >= maxBatchWriteAttempts {
+			return fmt.Errorf("%d segments left unprocessed after %d batch write attempts", len(unprocessed), attempt)
+		}
+
+		s.logger.WarnWithFields("Retrying unprocessed segments", map[string]interface{}{
+			"count":   len(unprocessed),
+			"attempt": attempt,
+		})
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(batchRetryBaseDelay << (attempt - 1)):
+		}
+		pending = unprocessed
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"generate-script-lambda/channel_utils"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/adapters"
+	"github.com/panjf2000/ants/v2"
+	"sync"
+	"testing"
+	"time"
+)
+
+// throttlingSegmentCache leaves the first segment of every batch
+// unprocessed on its first attempt, the way DynamoDB does under throttling.
+type throttlingSegmentCache struct {
+	staticSegmentCache
+	mu         sync.Mutex
+	batchSizes []int
+	throttled  map[string]bool
+}
+
+func (c *throttlingSegmentCache) SaveBatch(ctx context.Context, segments []domain.SegmentWithMediaUrl, userID string) ([]domain.SegmentWithMediaUrl, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.batchSizes = append(c.batchSizes, len(segments))
+	first := segments[0]
+	if !c.throttled[first.ID] {
+		c.throttled[first.ID] = true
+		c.segments = append(c.segments, segments[1:]...)
+		return segments[:1], nil
+	}
+	c.segments = append(c.segments, segments...)
+	return nil, nil
+}
+
+func TestSegmentMetadataSaver_Save(t *testing.T) {
+	workerPool, err := ants.NewPool(20)
+	if err != nil {
+		t.Fatal("Failed to create worker pool:", err)
+	}
+	defer workerPool.Release()
+
+	cache := &throttlingSegmentCache{throttled: make(map[string]bool)}
+	saver := NewSegmentMetadataSaver(adapters.NewZerologWrapper(), workerPool, cache, 2, 4, 20*time.Millisecond)
+
+	const segmentCount = 10
+	segments := make(chan domain.SegmentWithMediaUrl)
+	go func() {
+		defer close(segments)
+		for i := 0; i < segmentCount; i++ {
+			segments <- domain.SegmentWithMediaUrl{
+				Segment: domain.NewSegment("text", domain.AudioSegmentType, fmt.Sprintf("segment-%d", i), "story-1", i),
+			}
+		}
+	}()
+
+	events, errCh := saver.Save(context.Background(), segments, "user-1")
+	mergedErrCh, err := channel_utils.MergeChannels(workerPool, errCh)
+	if err != nil {
+		t.Fatal("Failed to merge error channels:", err)
+	}
+
+	seen := make(map[string]bool)
+	for event := range events {
+		if seen[event.SegmentId] {
+			t.Errorf("segment %s was emitted twice", event.SegmentId)
+		}
+		seen[event.SegmentId] = true
+	}
+	for err := range mergedErrCh {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	if len(seen) != segmentCount || len(cache.segments) != segmentCount {
+		t.Errorf("expected %d saved segments, got %d events and %d stored", segmentCount, len(seen), len(cache.segments))
+	}
+	for _, size := range cache.batchSizes {
+		if size > 4 {
+			t.Errorf("batch of %d exceeds the batch size", size)
+		}
+	}
+}
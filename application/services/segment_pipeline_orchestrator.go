@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"generate-script-lambda/application/ports/inbound"
 	"generate-script-lambda/application/ports/outbound"
 	"generate-script-lambda/channel_utils"
@@ -12,46 +13,98 @@ type segmentPipelineOrchestrator struct {
 	logger           outbound.LoggerPort
 	workerPool       outbound.TaskDispatcher
 	segmentGenerator inbound.SegmentsGeneratorPort
-	mediaEnhancer    inbound.SegmentMediaEnhancerPort
-	mediaSaver       inbound.SegmentMediaSaverPort
-	metadataSaver    inbound.SegmentMetadataSaverPort
+	// manuscriptSegmenter replaces segmentGenerator for manuscript stories.
+	manuscriptSegmenter inbound.SegmentsGeneratorPort
+	mediaEnhancer       inbound.SegmentMediaEnhancerPort
+	mediaSaver          inbound.SegmentMediaSaverPort
+	metadataSaver       inbound.SegmentMetadataSaverPort
+	audioAssembler      inbound.StoryAudioAssemblerPort
+	lexiconManager      inbound.LexiconManagerPort
 }
 
 func NewSegmentPipelineOrchestrator(logger outbound.LoggerPort, workerPool outbound.TaskDispatcher,
-	segmentGenerator inbound.SegmentsGeneratorPort, mediaEnhancer inbound.SegmentMediaEnhancerPort,
-	mediaSaver inbound.SegmentMediaSaverPort, metadataSaver inbound.SegmentMetadataSaverPort) inbound.SegmentPipelineOrchestrator {
+	segmentGenerator inbound.SegmentsGeneratorPort, manuscriptSegmenter inbound.SegmentsGeneratorPort,
+	mediaEnhancer inbound.SegmentMediaEnhancerPort,
+	mediaSaver inbound.SegmentMediaSaverPort, metadataSaver inbound.SegmentMetadataSaverPort,
+	audioAssembler inbound.StoryAudioAssemblerPort, lexiconManager inbound.LexiconManagerPort) inbound.SegmentPipelineOrchestrator {
 	return &segmentPipelineOrchestrator{
-		logger:           logger,
-		workerPool:       workerPool,
-		segmentGenerator: segmentGenerator,
-		mediaEnhancer:    mediaEnhancer,
-		mediaSaver:       mediaSaver,
-		metadataSaver:    metadataSaver,
+		logger:              logger,
+		workerPool:          workerPool,
+		segmentGenerator:    segmentGenerator,
+		manuscriptSegmenter: manuscriptSegmenter,
+		mediaEnhancer:       mediaEnhancer,
+		mediaSaver:          mediaSaver,
+		metadataSaver:       metadataSaver,
+		audioAssembler:      audioAssembler,
+		lexiconManager:      lexiconManager,
 	}
 }
 
-func (s *segmentPipelineOrchestrator) StartPipeline(ctx context.Context, request inbound.StartPipelineParams) (<-chan domain.SegmentEvent, <-chan error) {
-	segmentCh, segmentGeneratorErrCh := s.segmentGenerator.Generate(ctx, inbound.GenerateSegmentsParams{
-		Input:   request.Input,
-		StoryID: request.StoryID,
+func (s *segmentPipelineOrchestrator) ValidateInput(source domain.StorySource, input string) error {
+	if source == domain.ManuscriptStorySource && len(parseManuscript(input)) == 0 {
+		return fmt.Errorf("%w: the manuscript has no text to narrate", domain.ErrInvalidInput)
+	}
+
+	return nil
+}
+
+func (s *segmentPipelineOrchestrator) StartPipeline(ctx context.Context, request inbound.StartPipelineParams) (<-chan domain.SegmentEvent, <-chan domain.StoryAssets, <-chan error) {
+	lexicon, err := s.lexiconManager.Resolve(ctx, request.UserID, request.StoryID, request.LexiconID)
+	if err != nil {
+		s.logger.Error(err, "Failed to resolve pronunciation lexicon")
+		return s.failedPipeline(err)
+	}
+
+	segmentGenerator := s.segmentGenerator
+	if request.Source == domain.ManuscriptStorySource {
+		segmentGenerator = s.manuscriptSegmenter
+	}
+
+	segmentCh, segmentGeneratorErrCh := segmentGenerator.Generate(ctx, inbound.GenerateSegmentsParams{
+		Input:         request.Input,
+		StoryID:       request.StoryID,
+		SuggestScenes: request.SuggestScenes,
 	})
 
-	segmentWithMediaCh, mediaEnhancerErrCh := s.mediaEnhancer.Enhance(ctx, segmentCh, request.VoiceID)
+	segmentWithMediaCh, mediaEnhancerErrCh := s.mediaEnhancer.Enhance(ctx, segmentCh, inbound.EnhanceSegmentsParams{
+		VoiceID:       request.VoiceID,
+		VoiceSettings: request.VoiceSettings,
+		Language:      request.Language,
+		UserID:        request.UserID,
+		Lexicon:       lexicon,
+		AudioChunks:   request.AudioChunks,
+	})
 
-	segmentWithMediaUrlCh, mediaSaverErrCh := s.mediaSaver.Save(ctx, segmentWithMediaCh, request.UserID)
+	toSaveCh, toAssembleCh, err := channel_utils.TeeChannel(ctx, s.workerPool, segmentWithMediaCh)
+	if err != nil {
+		return s.failedPipeline(err)
+	}
 
-	segmentEventsCh, metadataSaverErrCh := s.metadataSaver.Save(ctx, segmentWithMediaUrlCh)
+	segmentWithMediaUrlCh, mediaSaverErrCh := s.mediaSaver.Save(ctx, toSaveCh, request.UserID)
 
-	mergerErrCh, err := channel_utils.MergeChannels(s.workerPool, segmentGeneratorErrCh, mediaEnhancerErrCh, mediaSaverErrCh, metadataSaverErrCh)
+	segmentEventsCh, metadataSaverErrCh := s.metadataSaver.Save(ctx, segmentWithMediaUrlCh, request.UserID)
+
+	storyAssetsCh, audioAssemblerErrCh := s.audioAssembler.Assemble(ctx, toAssembleCh, inbound.AssembleStoryAudioParams{
+		StoryID: request.StoryID,
+		UserID:  request.UserID,
+	})
+
+	mergerErrCh, err := channel_utils.MergeChannels(s.workerPool, segmentGeneratorErrCh, mediaEnhancerErrCh, mediaSaverErrCh, metadataSaverErrCh, audioAssemblerErrCh)
 
 	if err != nil {
-		out := make(chan domain.SegmentEvent)
-		errCh := make(chan error)
-		errCh <- err
-		close(out)
-		close(errCh)
-		return out, errCh
+		return s.failedPipeline(err)
 	}
 
-	return segmentEventsCh, mergerErrCh
+	return segmentEventsCh, storyAssetsCh, mergerErrCh
+}
+
+func (s *segmentPipelineOrchestrator) failedPipeline(err error) (<-chan domain.SegmentEvent, <-chan domain.StoryAssets, <-chan error) {
+	out := make(chan domain.SegmentEvent)
+	assetsCh := make(chan domain.StoryAssets)
+	errCh := make(chan error, 1)
+	errCh <- err
+	close(out)
+	close(assetsCh)
+	close(errCh)
+	return out, assetsCh, errCh
 }
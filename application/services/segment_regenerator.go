@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/channel_utils"
+	"generate-script-lambda/domain"
+)
+
+type segmentRegenerator struct {
+	logger          outbound.LoggerPort
+	workerPool      outbound.TaskDispatcher
+	segmentCache    outbound.SegmentCachePort
+	mediaStore      outbound.SegmentMediaStorePort
+	storyRepository outbound.StoryRepositoryPort
+	lexiconManager  inbound.LexiconManagerPort
+	mediaEnhancer   inbound.SegmentMediaEnhancerPort
+	mediaSaver      inbound.SegmentMediaSaverPort
+}
+
+func NewSegmentRegenerator(logger outbound.LoggerPort, workerPool outbound.TaskDispatcher, segmentCache outbound.SegmentCachePort,
+	mediaStore outbound.SegmentMediaStorePort, storyRepository outbound.StoryRepositoryPort, lexiconManager inbound.LexiconManagerPort,
+	mediaEnhancer inbound.SegmentMediaEnhancerPort, mediaSaver inbound.SegmentMediaSaverPort) inbound.SegmentRegeneratorPort {
+	return &segmentRegenerator{
+		logger:          logger,
+		workerPool:      workerPool,
+		segmentCache:    segmentCache,
+		mediaStore:      mediaStore,
+		storyRepository: storyRepository,
+		lexiconManager:  lexiconManager,
+		mediaEnhancer:   mediaEnhancer,
+		mediaSaver:      mediaSaver,
+	}
+}
+
+// Regenerate runs a single segment through the same enhance and save stages
+// as the story pipeline. The current media is archived under its version
+// number first, then replaced in place so links to the segment keep working.
+func (s *segmentRegenerator) Regenerate(ctx context.Context, params inbound.RegenerateSegmentParams) (domain.SegmentEvent, error) {
+	current, err := findSegment(ctx, s.segmentCache, params.StoryID, params.SegmentID, params.UserID)
+	if err != nil {
+		return domain.SegmentEvent{}, err
+	}
+	if current.Type == domain.ImageSegmentType && (params.VoiceID != "" || params.VoiceSettings != nil) {
+		return domain.SegmentEvent{}, fmt.Errorf("%w: image segments have no voice", domain.ErrInvalidInput)
+	}
+
+	enhanceParams, err := s.enhanceParams(ctx, current.Segment, params)
+	if err != nil {
+		return domain.SegmentEvent{}, err
+	}
+
+	err = s.mediaStore.ArchiveMedia(ctx, current.Segment, params.UserID)
+	if err != nil {
+		return domain.SegmentEvent{}, err
+	}
+
+	segment := current.Segment
+	segment.Version = current.CurrentVersion() + 1
+	if params.Text != "" {
+		segment.Text = params.Text
+	}
+
+	regenerated, err := s.generate(ctx, segment, enhanceParams)
+	if err != nil {
+		return domain.SegmentEvent{}, err
+	}
+
+	err = s.segmentCache.Save(ctx, regenerated, params.UserID)
+	if err != nil {
+		s.logger.ErrorWithFields(err, "Failed to save regenerated segment", map[string]interface{}{
+			"story_id":   params.StoryID,
+			"segment_id": params.SegmentID,
+		})
+		return domain.SegmentEvent{}, err
+	}
+
+	return regenerated.ToEvent(), nil
+}
+
+func findSegment(ctx context.Context, segmentCache outbound.SegmentCachePort, storyID string, segmentID string,
+	userID string) (domain.SegmentWithMediaUrl, error) {
+	segments, err := segmentCache.Query(ctx, outbound.SegmentQuery{
+		StoryID: storyID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return domain.SegmentWithMediaUrl{}, err
+	}
+
+	for _, segment := range segments {
+		if segment.ID == segmentID {
+			return segment, nil
+		}
+	}
+
+	return domain.SegmentWithMediaUrl{}, fmt.Errorf("%w: segment %s", domain.ErrNotFound, segmentID)
+}
+
+// enhanceParams narrates with the story's original voice and language unless
+// the request picks another voice, and illustrates in the story's style.
+func (s *segmentRegenerator) enhanceParams(ctx context.Context, segment domain.Segment,
+	params inbound.RegenerateSegmentParams) (inbound.EnhanceSegmentsParams, error) {
+	enhanceParams := inbound.EnhanceSegmentsParams{
+		VoiceID:       params.VoiceID,
+		VoiceSettings: params.VoiceSettings,
+		UserID:        params.UserID,
+	}
+
+	story, err := s.storyRepository.Get(ctx, params.UserID, params.StoryID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return inbound.EnhanceSegmentsParams{}, err
+	}
+	if segment.Type != domain.AudioSegmentType {
+		enhanceParams.ImageStyle = story.Parameters.ImageStyle
+		return enhanceParams, nil
+	}
+
+	enhanceParams.Language = story.Parameters.Language
+	if enhanceParams.VoiceID == "" {
+		enhanceParams.VoiceID = story.Parameters.VoiceID
+		if enhanceParams.VoiceSettings == nil {
+			enhanceParams.VoiceSettings = story.Parameters.VoiceSettings
+		}
+	}
+	if enhanceParams.VoiceID == "" {
+		return inbound.EnhanceSegmentsParams{}, fmt.Errorf("%w: voice_id is required, story %s has no recorded voice",
+			domain.ErrInvalidInput, params.StoryID)
+	}
+
+	enhanceParams.Lexicon, err = s.lexiconManager.Resolve(ctx, params.UserID, params.StoryID, story.Parameters.LexiconID)
+	if err != nil {
+		s.logger.Error(err, "Failed to resolve pronunciation lexicon")
+		return inbound.EnhanceSegmentsParams{}, err
+	}
+
+	return enhanceParams, nil
+}
+
+func (s *segmentRegenerator) generate(ctx context.Context, segment domain.Segment,
+	params inbound.EnhanceSegmentsParams) (domain.SegmentWithMediaUrl, error) {
+	newCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	segmentCh := make(chan domain.Segment, 1)
+	segmentCh <- segment
+	close(segmentCh)
+
+	segmentWithMediaCh, mediaEnhancerErrCh := s.mediaEnhancer.Enhance(newCtx, segmentCh, params)
+	segmentWithMediaUrlCh, mediaSaverErrCh := s.mediaSaver.Save(newCtx, segmentWithMediaCh, params.UserID)
+
+	errCh, err := channel_utils.MergeChannels(s.workerPool, mediaEnhancerErrCh, mediaSaverErrCh)
+	if err != nil {
+		return domain.SegmentWithMediaUrl{}, err
+	}
+
+	// Both channels are drained so the stages can wind down before returning.
+	var regenerated *domain.SegmentWithMediaUrl
+	var firstErr error
+	for segmentWithMediaUrlCh != nil || errCh != nil {
+		select {
+		case saved, ok := <-segmentWithMediaUrlCh:
+			if !ok {
+				segmentWithMediaUrlCh = nil
+				continue
+			}
+			regenerated = &saved
+		case err, ok := <-errCh:
+			if !ok {
+				errCh = nil
+				continue
+			}
+			if firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return domain.SegmentWithMediaUrl{}, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return domain.SegmentWithMediaUrl{}, err
+	}
+	if regenerated == nil {
+		return domain.SegmentWithMediaUrl{}, fmt.Errorf("no media was generated for segment %s", segment.ID)
+	}
+
+	return *regenerated, nil
+}
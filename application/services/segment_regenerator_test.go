@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/adapters"
+	"github.com/panjf2000/ants/v2"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type staticImageGenerator struct {
+	mu           sync.Mutex
+	descriptions []string
+	styles       []string
+}
+
+func (g *staticImageGenerator) Generate(ctx context.Context, params outbound.GenerateImageParams) (outbound.GeneratedImage, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.descriptions = append(g.descriptions, params.Description)
+	g.styles = append(g.styles, params.Style)
+	return outbound.GeneratedImage{Content: []byte(params.Description), Provider: "static"}, nil
+}
+
+func TestSegmentRegenerator_Regenerate(t *testing.T) {
+	ctx := context.Background()
+	workerPool, err := ants.NewPool(20)
+	if err != nil {
+		t.Fatal("Failed to create worker pool:", err)
+	}
+	defer workerPool.Release()
+
+	logger := adapters.NewZerologWrapper()
+	mediaStore := adapters.NewMemorySegmentMediaStore()
+	cache := adapters.NewMemorySegmentCache(time.Hour)
+	imageGenerator := &staticImageGenerator{}
+	repository, err := adapters.NewSqliteStoryRepository(filepath.Join(t.TempDir(), "stories.db"), logger)
+	if err != nil {
+		t.Fatal("Failed to create story repository:", err)
+	}
+	_ = repository.Save(ctx, domain.Story{ID: "story-1", UserID: "user-1", Status: domain.CompletedStoryStatus,
+		Parameters: domain.StoryParameters{Input: "a castle story", VoiceID: "narrator", ImageStyle: "watercolor"}})
+
+	image := domain.SegmentWithMediaUrl{Segment: domain.NewSegment("a castle", domain.ImageSegmentType, "img-1", "story-1", 0)}
+	image.MediaURL, err = mediaStore.Save(ctx, domain.SegmentWithMedia{MediaContent: []byte("a castle"), Segment: image.Segment}, "user-1")
+	if err != nil {
+		t.Fatal("Failed to save media:", err)
+	}
+	_ = cache.Save(ctx, image, "user-1")
+
+	enhancer := NewSegmentMediaEnhancer(logger, imageGenerator, nil, mediaStore, workerPool, outbound.AudioFormatMP3, domain.VoiceSettings{})
+	saver := NewSegmentMediaSaver(logger, mediaStore, workerPool, 2)
+	regenerator := NewSegmentRegenerator(logger, workerPool, cache, mediaStore, repository, nil, enhancer, saver)
+
+	_, err = regenerator.Regenerate(ctx, inbound.RegenerateSegmentParams{StoryID: "story-1", SegmentID: "img-1", UserID: "user-2"})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("Expected other users' segments to be hidden, got %v", err)
+	}
+	_, err = regenerator.Regenerate(ctx, inbound.RegenerateSegmentParams{StoryID: "story-1", SegmentID: "img-1", UserID: "user-1", VoiceID: "voice"})
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("Expected a voice for an image segment to be rejected, got %v", err)
+	}
+
+	event, err := regenerator.Regenerate(ctx, inbound.RegenerateSegmentParams{
+		StoryID: "story-1", SegmentID: "img-1", UserID: "user-1", Text: "a dark castle",
+	})
+	if err != nil {
+		t.Fatal("Failed to regenerate segment:", err)
+	}
+	if event.Version != 2 || event.Text != "a dark castle" || event.Url != image.MediaURL {
+		t.Fatalf("Unexpected regenerated segment: %+v", event)
+	}
+	event, err = regenerator.Regenerate(ctx, inbound.RegenerateSegmentParams{StoryID: "story-1", SegmentID: "img-1", UserID: "user-1"})
+	if err != nil {
+		t.Fatal("Failed to regenerate segment:", err)
+	}
+	if event.Version != 3 || imageGenerator.descriptions[1] != "a dark castle" {
+		t.Fatalf("Expected the edited description to be kept, got %+v and %v", event, imageGenerator.descriptions)
+	}
+	for _, style := range imageGenerator.styles {
+		if style != "watercolor" {
+			t.Fatalf("Expected illustrations in the story's style, got %v", imageGenerator.styles)
+		}
+	}
+
+	segments, _ := cache.Query(ctx, outbound.SegmentQuery{StoryID: "story-1", UserID: "user-1"})
+	if len(segments) != 1 || segments[0].Version != 3 {
+		t.Fatalf("Expected the cached segment to be replaced, got %+v", segments)
+	}
+
+	for version, expected := range map[int]string{0: "a dark castle", 1: "a castle", 2: "a dark castle"} {
+		content := readMedia(t, mediaStore, domain.MediaObject{StoryID: "story-1", SegmentID: "img-1", Type: domain.ImageSegmentType, Version: version})
+		if content != expected {
+			t.Errorf("version %d holds %q, expected %q", version, content, expected)
+		}
+	}
+	links, err := mediaStore.FindMediaVersion(ctx, "story-1", "img-1", 1, "user-1")
+	if err != nil || links.Version != 1 || links.URL == image.MediaURL {
+		t.Fatalf("Expected the first version to be retrievable, got %+v: %v", links, err)
+	}
+}
+
+func readMedia(t *testing.T, mediaStore outbound.SegmentMediaStorePort, object domain.MediaObject) string {
+	reader, err := mediaStore.OpenMedia(context.Background(), object, "user-1")
+	if err != nil {
+		t.Fatal("Failed to open media:", err)
+	}
+	defer reader.Close()
+	content, _ := io.ReadAll(reader)
+
+	return string(content)
+}
@@ -22,6 +22,7 @@ type segmentTextGenerator struct {
 	workerPool        outbound.TaskDispatcher
 	descRegexp        *regexp.Regexp
 	punctuationRegexp *regexp.Regexp
+	moodRegexp        *regexp.Regexp
 }
 
 func NewSegmentTextGenerator(logger outbound.LoggerPort, scriptGenerator outbound.StoryScriptGeneratorPort,
@@ -32,6 +33,7 @@ func NewSegmentTextGenerator(logger outbound.LoggerPort, scriptGenerator outboun
 		workerPool:        workerPool,
 		descRegexp:        regexp.MustCompile(`\[(.*?)]`),
 		punctuationRegexp: regexp.MustCompile(`[.!?:;]`),
+		moodRegexp:        regexp.MustCompile(`<mood\s+value="([^"]*)"\s*/>`),
 	}
 }
 
@@ -53,6 +55,7 @@ func (s *segmentTextGenerator) Generate(ctx context.Context, params inbound.Gene
 		var builder strings.Builder
 		audioSegmentsCounter := 0
 		imageSegmentsCounter := 0
+		currentMood := domain.NeutralMood
 
 		for {
 			select {
@@ -75,6 +78,7 @@ func (s *segmentTextGenerator) Generate(ctx context.Context, params inbound.Gene
 					builder.WriteString(newBuffer)
 					for _, segment := range segments {
 						if segment.Type == domain.AudioSegmentType {
+							currentMood = s.applyMood(&segment, currentMood)
 							segment.Ordinal = audioSegmentsCounter
 							audioSegmentsCounter++
 							segment.StoryID = params.StoryID
@@ -87,7 +91,9 @@ func (s *segmentTextGenerator) Generate(ctx context.Context, params inbound.Gene
 					}
 				} else {
 					if builder.Len() > 0 {
-						out <- domain.NewSegment(builder.String(), domain.AudioSegmentType, uuid.NewString(), params.StoryID, audioSegmentsCounter)
+						segment := domain.NewSegment(builder.String(), domain.AudioSegmentType, uuid.NewString(), params.StoryID, audioSegmentsCounter)
+						s.applyMood(&segment, currentMood)
+						out <- segment
 						log.Info().Msg("Finished reading from stream.")
 					}
 					return
@@ -207,3 +213,18 @@ func (s *segmentTextGenerator) extractImageSegment(buffer string) (imageSegment
 
 	return
 }
+
+// applyMood strips mood markers from an audio segment and tags it with the
+// first one it contains. Segments without a marker keep the previous mood.
+func (s *segmentTextGenerator) applyMood(segment *domain.Segment, currentMood domain.Mood) domain.Mood {
+	matches := s.moodRegexp.FindAllStringSubmatch(segment.Text, -1)
+	if len(matches) == 0 {
+		segment.Mood = currentMood
+		return currentMood
+	}
+
+	segment.Text = s.moodRegexp.ReplaceAllString(segment.Text, "")
+	segment.Mood = domain.ParseMood(matches[0][1])
+
+	return domain.ParseMood(matches[len(matches)-1][1])
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"generate-script-lambda/domain"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var speechMarkupRegexp = regexp.MustCompile(`<break\s+time="(\d+(?:\.\d+)?)(ms|s)"\s*/>|<emphasis(?:\s+level="[a-z]+")?>(.*?)</emphasis>`)
+
+// parseSpeechMarkup turns SSML-style <break/> and <emphasis> tags found in
+// the story text into speech tokens; everything else stays plain text.
+func parseSpeechMarkup(text string) domain.Speech {
+	speech := make(domain.Speech, 0)
+	position := 0
+	for _, match := range speechMarkupRegexp.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] > position {
+			speech = append(speech, domain.SpeechToken{Type: domain.TextSpeechToken, Text: text[position:match[0]]})
+		}
+		if match[2] != -1 {
+			value, _ := strconv.ParseFloat(text[match[2]:match[3]], 64)
+			unit := time.Second
+			if text[match[4]:match[5]] == "ms" {
+				unit = time.Millisecond
+			}
+			speech = append(speech, domain.SpeechToken{Type: domain.BreakSpeechToken, Pause: time.Duration(value * float64(unit))})
+		} else {
+			speech = append(speech, domain.SpeechToken{Type: domain.EmphasisSpeechToken, Text: text[match[6]:match[7]]})
+		}
+		position = match[1]
+	}
+	if position < len(text) {
+		speech = append(speech, domain.SpeechToken{Type: domain.TextSpeechToken, Text: text[position:]})
+	}
+
+	return speech
+}
+
+type lexiconMatcher struct {
+	pattern *regexp.Regexp
+	entries map[string]domain.LexiconEntry
+}
+
+func newLexiconMatcher(entries []domain.LexiconEntry) *lexiconMatcher {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	sorted := make([]domain.LexiconEntry, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(sorted[i].Grapheme) > len(sorted[j].Grapheme)
+	})
+
+	alternatives := make([]string, len(sorted))
+	byGrapheme := make(map[string]domain.LexiconEntry, len(sorted))
+	for i, entry := range sorted {
+		alternatives[i] = regexp.QuoteMeta(entry.Grapheme)
+		byGrapheme[strings.ToLower(entry.Grapheme)] = entry
+	}
+
+	return &lexiconMatcher{
+		pattern: regexp.MustCompile(`(?i)` + strings.Join(alternatives, "|")),
+		entries: byGrapheme,
+	}
+}
+
+// apply replaces whole-word lexicon matches in plain text tokens with alias
+// or phoneme tokens. A nil matcher leaves the speech untouched.
+func (m *lexiconMatcher) apply(speech domain.Speech) domain.Speech {
+	if m == nil {
+		return speech
+	}
+
+	result := make(domain.Speech, 0, len(speech))
+	for _, token := range speech {
+		if token.Type != domain.TextSpeechToken {
+			result = append(result, token)
+			continue
+		}
+
+		position := 0
+		for _, match := range m.pattern.FindAllStringIndex(token.Text, -1) {
+			if !isWordBoundary(token.Text, match[0], match[1]) {
+				continue
+			}
+			if match[0] > position {
+				result = append(result, domain.SpeechToken{Type: domain.TextSpeechToken, Text: token.Text[position:match[0]]})
+			}
+			matched := token.Text[match[0]:match[1]]
+			entry := m.entries[strings.ToLower(matched)]
+			if entry.Phoneme != "" {
+				result = append(result, domain.SpeechToken{
+					Type:     domain.PhonemeSpeechToken,
+					Text:     matched,
+					Alias:    entry.Alias,
+					Phoneme:  entry.Phoneme,
+					Alphabet: entry.Alphabet,
+				})
+			} else {
+				result = append(result, domain.SpeechToken{Type: domain.TextSpeechToken, Text: entry.Alias})
+			}
+			position = match[1]
+		}
+		if position < len(token.Text) {
+			result = append(result, domain.SpeechToken{Type: domain.TextSpeechToken, Text: token.Text[position:]})
+		}
+	}
+
+	return result
+}
+
+func isWordBoundary(text string, start int, end int) bool {
+	if start > 0 {
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		first, _ := utf8.DecodeRuneInString(text[start:])
+		if isWordRune(before) && isWordRune(first) {
+			return false
+		}
+	}
+	if end < len(text) {
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		last, _ := utf8.DecodeLastRuneInString(text[:end])
+		if isWordRune(after) && isWordRune(last) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
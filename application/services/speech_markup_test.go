@@ -0,0 +1,34 @@
+package services
+
+import (
+	"generate-script-lambda/domain"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPrepareSpeech_LexiconAndMarkup(t *testing.T) {
+	matcher := newLexiconMatcher([]domain.LexiconEntry{
+		{Grapheme: "Aelwyn", Phoneme: "ˈaɪlwɪn", Alphabet: domain.IpaAlphabet},
+		{Grapheme: "ACME", Alias: "Acme"},
+	})
+
+	speech := matcher.apply(parseSpeechMarkup(`aelwyn bought ACME rope.<break time="500ms"/><emphasis>Aelwynson</emphasis> laughed.`))
+
+	expected := domain.Speech{
+		{Type: domain.PhonemeSpeechToken, Text: "aelwyn", Phoneme: "ˈaɪlwɪn", Alphabet: domain.IpaAlphabet},
+		{Type: domain.TextSpeechToken, Text: " bought "},
+		{Type: domain.TextSpeechToken, Text: "Acme"},
+		{Type: domain.TextSpeechToken, Text: " rope."},
+		{Type: domain.BreakSpeechToken, Pause: 500 * time.Millisecond},
+		{Type: domain.EmphasisSpeechToken, Text: "Aelwynson"},
+		{Type: domain.TextSpeechToken, Text: " laughed."},
+	}
+	if !reflect.DeepEqual(speech, expected) {
+		t.Fatalf("Unexpected speech:\n%+v", speech)
+	}
+
+	if plain := speech.PlainText(); plain != "aelwyn bought Acme rope.Aelwynson laughed." {
+		t.Fatalf("Unexpected plain text: %q", plain)
+	}
+}
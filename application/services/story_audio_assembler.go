@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/media_utils"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type storyAudioAssembler struct {
+	logger           outbound.LoggerPort
+	mediaStore       outbound.SegmentMediaStorePort
+	workerPool       outbound.TaskDispatcher
+	enabled          bool
+	paragraphSilence time.Duration
+}
+
+func NewStoryAudioAssembler(logger outbound.LoggerPort, mediaStore outbound.SegmentMediaStorePort, workerPool outbound.TaskDispatcher,
+	enabled bool, paragraphSilence time.Duration) inbound.StoryAudioAssemblerPort {
+	return &storyAudioAssembler{
+		logger:           logger,
+		mediaStore:       mediaStore,
+		workerPool:       workerPool,
+		enabled:          enabled,
+		paragraphSilence: paragraphSilence,
+	}
+}
+
+func (s *storyAudioAssembler) Assemble(ctx context.Context, segmentCh <-chan domain.SegmentWithMedia, params inbound.AssembleStoryAudioParams) (<-chan domain.StoryAssets, <-chan error) {
+	out := make(chan domain.StoryAssets, 1)
+	errCh := make(chan error, 1)
+
+	err := s.workerPool.Submit(func() {
+		defer close(out)
+		defer close(errCh)
+
+		audioSegments := make([]domain.SegmentWithMedia, 0)
+		for segment := range segmentCh {
+			if segment.Type == domain.AudioSegmentType {
+				audioSegments = append(audioSegments, segment)
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		assets := domain.StoryAssets{StoryID: params.StoryID}
+		if !s.enabled || len(audioSegments) == 0 {
+			out <- assets
+			return
+		}
+
+		content, offsets, err := media_utils.ConcatenateMp3(s.toMp3Parts(audioSegments))
+		if errors.Is(err, media_utils.ErrNotMp3) {
+			s.logger.WarnWithFields("Audio segments are not mp3, skipping full story audio", map[string]interface{}{
+				"story_id": params.StoryID,
+			})
+			out <- assets
+			return
+		}
+		if err != nil {
+			s.logger.ErrorWithFields(err, "Failed to concatenate story audio", map[string]interface{}{
+				"story_id": params.StoryID,
+			})
+			errCh <- err
+			return
+		}
+
+		storyAudio := domain.SegmentWithMedia{
+			MediaContent: content,
+			MimeType:     outbound.AudioFormatMP3.MimeType(),
+			Segment:      domain.NewSegment("", domain.StoryAudioSegmentType, params.StoryID, params.StoryID, 0),
+		}
+		url, err := s.mediaStore.Save(ctx, storyAudio, params.UserID)
+		if err != nil {
+			errCh <- err
+			return
+		}
+
+		subtitles, err := s.saveSubtitles(ctx, storyAudio.Segment, audioSegments, offsets, params.UserID)
+		if err != nil {
+			errCh <- err
+			return
+		}
+
+		s.logger.DebugWithFields("story audio saved", map[string]interface{}{
+			"story_id": params.StoryID,
+			"segments": len(audioSegments),
+		})
+
+		assets.FullAudioURL = url
+		assets.Subtitles = subtitles
+		out <- assets
+	})
+	if err != nil {
+		errCh <- err
+	}
+
+	return out, errCh
+}
+
+func (s *storyAudioAssembler) toMp3Parts(audioSegments []domain.SegmentWithMedia) []media_utils.Mp3Part {
+	sort.Slice(audioSegments, func(i, j int) bool {
+		return audioSegments[i].Ordinal < audioSegments[j].Ordinal
+	})
+
+	parts := make([]media_utils.Mp3Part, len(audioSegments))
+	for i, segment := range audioSegments {
+		parts[i] = media_utils.Mp3Part{Content: segment.MediaContent}
+		if i+1 < len(audioSegments) && s.startsParagraph(audioSegments[i+1].Text) {
+			parts[i].SilenceAfter = s.paragraphSilence
+		}
+	}
+
+	return parts
+}
+
+func (s *storyAudioAssembler) saveSubtitles(ctx context.Context, storyAudio domain.Segment, audioSegments []domain.SegmentWithMedia,
+	offsets []time.Duration, userID string) (domain.SubtitleURLs, error) {
+	cues := make([]media_utils.SubtitleCue, 0)
+	for i, segment := range audioSegments {
+		if segment.Alignment == nil {
+			continue
+		}
+		cues = append(cues, media_utils.BuildSubtitleCues(segment.Alignment.Words, offsets[i].Seconds())...)
+	}
+	if len(cues) == 0 {
+		return domain.SubtitleURLs{}, nil
+	}
+
+	vttUrl, err := s.mediaStore.SaveAttachment(ctx, storyAudio, "vtt", media_utils.RenderWebVTT(cues), userID)
+	if err != nil {
+		return domain.SubtitleURLs{}, err
+	}
+
+	srtUrl, err := s.mediaStore.SaveAttachment(ctx, storyAudio, "srt", media_utils.RenderSRT(cues), userID)
+	if err != nil {
+		return domain.SubtitleURLs{}, err
+	}
+
+	return domain.SubtitleURLs{
+		VttURL: vttUrl,
+		SrtURL: srtUrl,
+	}, nil
+}
+
+func (s *storyAudioAssembler) startsParagraph(text string) bool {
+	leading := text[:len(text)-len(strings.TrimLeftFunc(text, unicode.IsSpace))]
+
+	return strings.Contains(leading, "\n")
+}
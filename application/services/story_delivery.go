@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+const (
+	storyOutboxBatchSize = 25
+	storyDeliveryTimeout = 30 * time.Second
+	// storyOutboxLease keeps other replicas off a claimed entry for longer
+	// than a delivery can take; an entry of a replica that died is picked
+	// up again once it runs out.
+	storyOutboxLease = 2 * storyDeliveryTimeout
+)
+
+// storyDelivery is a transactional outbox in front of the Story API. Every
+// entry is delivered at least once, so the Story API has to treat a repeated
+// story id as the same story.
+type storyDelivery struct {
+	logger       outbound.LoggerPort
+	outbox       outbound.StoryOutboxPort
+	storySaver   outbound.StorySaverPort
+	maxAttempts  int
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+	pollInterval time.Duration
+	wake         chan struct{}
+	// depth is the gauge the dispatcher measured last; readers get it
+	// without querying the outbox.
+	depthMu sync.RWMutex
+	depth   domain.OutboxDepth
+}
+
+func NewStoryDelivery(logger outbound.LoggerPort, outbox outbound.StoryOutboxPort, storySaver outbound.StorySaverPort,
+	maxAttempts int, baseBackoff time.Duration, maxBackoff time.Duration, pollInterval time.Duration) inbound.StoryDeliveryPort {
+	return &storyDelivery{
+		logger:       logger,
+		outbox:       outbox,
+		storySaver:   storySaver,
+		maxAttempts:  maxAttempts,
+		baseBackoff:  baseBackoff,
+		maxBackoff:   maxBackoff,
+		pollInterval: pollInterval,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+func (s *storyDelivery) Deliver(ctx context.Context, params outbound.SaveStoryParams) error {
+	now := time.Now().UTC()
+	err := s.outbox.Add(ctx, outbound.StoryOutboxEntry{
+		ID:            uuid.NewString(),
+		Story:         params,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (s *storyDelivery) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.dispatchDue(ctx)
+		s.reportDepth(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// Depth returns the depth measured in the last dispatch round; MeasuredAt
+// is unset until the first round has run.
+func (s *storyDelivery) Depth(_ context.Context) (domain.OutboxDepth, error) {
+	s.depthMu.RLock()
+	defer s.depthMu.RUnlock()
+
+	return s.depth, nil
+}
+
+// dispatchDue drains every entry that is due, one batch at a time.
+func (s *storyDelivery) dispatchDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		entries, err := s.outbox.Due(ctx, time.Now().UTC(), storyOutboxBatchSize)
+		if err != nil {
+			s.logger.Error(err, "Failed to list due story outbox entries")
+			return
+		}
+		for _, entry := range entries {
+			// An outbox that cannot be written would hand out the same
+			// entries again right away; wait for the next round instead.
+			if err = s.dispatch(ctx, entry); err != nil {
+				return
+			}
+		}
+		if len(entries) < storyOutboxBatchSize {
+			return
+		}
+	}
+}
+
+// dispatch sends an entry it managed to claim. An entry another replica has
+// claimed or already delivered is skipped without error.
+func (s *storyDelivery) dispatch(ctx context.Context, entry outbound.StoryOutboxEntry) error {
+	err := s.outbox.Claim(ctx, entry, time.Now().UTC().Add(storyOutboxLease))
+	if errors.Is(err, domain.ErrNotFound) {
+		s.logger.DebugWithFields("Story outbox entry claimed elsewhere", map[string]interface{}{
+			"story_id": entry.Story.ID,
+			"entry_id": entry.ID,
+		})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	saveCtx, cancel := context.WithTimeout(ctx, storyDeliveryTimeout)
+	err = s.storySaver.Save(saveCtx, entry.Story)
+	cancel()
+	if err == nil {
+		if err = s.outbox.Remove(ctx, entry.ID); err != nil {
+			s.logger.ErrorWithFields(err, "Delivered story stays in the outbox and will be sent again", map[string]interface{}{
+				"story_id": entry.Story.ID,
+			})
+		}
+		return err
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	entry.Attempts++
+	entry.LastError = err.Error()
+	fields := map[string]interface{}{
+		"story_id": entry.Story.ID,
+		"entry_id": entry.ID,
+		"attempts": entry.Attempts,
+	}
+	if entry.Attempts >= s.maxAttempts {
+		entry.DeadLettered = true
+		s.logger.ErrorWithFields(err, "Story delivery dead-lettered", fields)
+	} else {
+		entry.NextAttemptAt = time.Now().UTC().Add(s.backoff(entry.Attempts))
+		fields["next_attempt_at"] = entry.NextAttemptAt
+		s.logger.WarnWithFields("Story delivery failed, retrying later", fields)
+	}
+
+	err = s.outbox.Update(ctx, entry)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		s.logger.ErrorWithFields(err, "Failed to reschedule story delivery", fields)
+	}
+
+	return err
+}
+
+// backoff doubles the delay after every failed attempt up to maxBackoff.
+func (s *storyDelivery) backoff(attempts int) time.Duration {
+	delay := s.baseBackoff
+	for i := 1; i < attempts && delay < s.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.maxBackoff {
+		delay = s.maxBackoff
+	}
+
+	return delay
+}
+
+// reportDepth records the outbox depth for Depth and logs it as a gauge for
+// log-based metrics; an empty outbox is not worth a line.
+func (s *storyDelivery) reportDepth(ctx context.Context) {
+	depth, err := s.outbox.Depth(ctx)
+	if err != nil {
+		return
+	}
+	measuredAt := time.Now().UTC()
+	depth.MeasuredAt = &measuredAt
+
+	s.depthMu.Lock()
+	s.depth = depth
+	s.depthMu.Unlock()
+
+	if depth.Pending == 0 && depth.DeadLettered == 0 {
+		return
+	}
+	s.logger.InfoWithFields("Story outbox depth", map[string]interface{}{
+		"metric":        "story_outbox_depth",
+		"pending":       depth.Pending,
+		"dead_lettered": depth.DeadLettered,
+	})
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/infrastructure/adapters"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyStorySaver rejects the first few saves of every story.
+type flakyStorySaver struct {
+	mu       sync.Mutex
+	failures int
+	attempts map[string]int
+	saved    []string
+}
+
+func (f *flakyStorySaver) Save(_ context.Context, params outbound.SaveStoryParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.attempts[params.ID]++
+	if f.attempts[params.ID] <= f.failures {
+		return errors.New("story api unavailable")
+	}
+	f.saved = append(f.saved, params.ID)
+
+	return nil
+}
+
+func TestStoryDelivery_Dispatch(t *testing.T) {
+	ctx := context.Background()
+	logger := adapters.NewZerologWrapper()
+	outbox, err := adapters.NewSqliteStoryOutbox(filepath.Join(t.TempDir(), "outbox.db"), logger)
+	if err != nil {
+		t.Fatal("Failed to create outbox:", err)
+	}
+
+	saver := &flakyStorySaver{failures: 2, attempts: make(map[string]int)}
+	delivery := NewStoryDelivery(logger, outbox, saver, 3, time.Millisecond, 4*time.Millisecond, time.Hour).(*storyDelivery)
+
+	if err = delivery.Deliver(ctx, outbound.SaveStoryParams{ID: "story-1", UserID: "user-1", Input: "a castle"}); err != nil {
+		t.Fatal("Failed to queue story:", err)
+	}
+	for i := 0; i < 3; i++ {
+		delivery.dispatchDue(ctx)
+		time.Sleep(10 * time.Millisecond)
+	}
+	depth, _ := outbox.Depth(ctx)
+	if len(saver.saved) != 1 || saver.attempts["story-1"] != 3 || depth.Pending != 0 || depth.DeadLettered != 0 {
+		t.Fatalf("Expected the story to be delivered on the third attempt, got %+v and %+v", saver, depth)
+	}
+
+	_ = delivery.Deliver(ctx, outbound.SaveStoryParams{ID: "story-claimed", UserID: "user-1"})
+	due, _ := outbox.Due(ctx, time.Now().UTC(), 10)
+	if len(due) != 1 {
+		t.Fatalf("Expected one due entry, got %d", len(due))
+	}
+	if err = outbox.Claim(ctx, due[0], time.Now().UTC().Add(time.Hour)); err != nil {
+		t.Fatal("Failed to claim entry:", err)
+	}
+	if err = delivery.dispatch(ctx, due[0]); err != nil || saver.attempts["story-claimed"] != 0 {
+		t.Fatalf("Expected an entry claimed elsewhere to be skipped, got %v and %d attempts", err, saver.attempts["story-claimed"])
+	}
+	if err = outbox.Remove(ctx, due[0].ID); err != nil {
+		t.Fatal("Failed to remove entry:", err)
+	}
+
+	saver.failures = 5
+	_ = delivery.Deliver(ctx, outbound.SaveStoryParams{ID: "story-2", UserID: "user-1"})
+	for i := 0; i < 5; i++ {
+		delivery.dispatchDue(ctx)
+		time.Sleep(10 * time.Millisecond)
+	}
+	depth, _ = outbox.Depth(ctx)
+	if saver.attempts["story-2"] != 3 || depth.Pending != 0 || depth.DeadLettered != 1 {
+		t.Fatalf("Expected the story to be dead-lettered after three attempts, got %+v and %+v", saver.attempts, depth)
+	}
+
+	delivery.reportDepth(ctx)
+	if cached, _ := delivery.Depth(ctx); cached.DeadLettered != 1 || cached.MeasuredAt == nil {
+		t.Fatalf("Expected the measured depth to be cached, got %+v", cached)
+	}
+
+	if backoff := delivery.backoff(10); backoff != 4*time.Millisecond {
+		t.Fatalf("Expected the backoff to be capped, got %v", backoff)
+	}
+}
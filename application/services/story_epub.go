@@ -0,0 +1,330 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"generate-script-lambda/domain"
+	"hash/crc32"
+	"io"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	epubMimeType      = "application/epub+zip"
+	epubContentDir    = "OEBPS"
+	epubActiveClass   = "-epub-media-overlay-active"
+	epubStoryDocument = "story.xhtml"
+	epubStoryOverlay  = "story.smil"
+)
+
+var epubMediaTypes = map[string]string{
+	"mp3":  "audio/mpeg",
+	"wav":  "audio/wav",
+	"ogg":  "audio/ogg",
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+}
+
+type epubItem struct {
+	ID        string
+	Href      string
+	MediaType string
+}
+
+type epubDocument struct {
+	name     string
+	template string
+}
+
+type epubWord struct {
+	ID   string
+	Text string
+}
+
+// epubBlock is one paragraph or illustration of the story document.
+type epubBlock struct {
+	ID    string
+	Image string
+	Text  string
+	Words []epubWord
+}
+
+type epubClip struct {
+	ID    string
+	Text  string
+	Audio string
+	Begin float64
+	End   float64
+}
+
+type epubBook struct {
+	Identifier string
+	Title      string
+	Language   string
+	Modified   string
+	Duration   string
+	Items      []epubItem
+	Blocks     []epubBlock
+	Clips      []epubClip
+}
+
+// buildEpubBook lays the manifest out as a single read-along document. When
+// the displayed text splits into as many words as the narration timings,
+// every word gets its own overlay clip; otherwise the paragraph is synced as
+// a whole, since the spoken text went through normalisation first.
+func buildEpubBook(manifest domain.StoryManifest, modified time.Time) epubBook {
+	book := epubBook{
+		Identifier: "urn:uuid:" + manifest.StoryID,
+		Title:      manifest.Title,
+		Language:   manifest.Language,
+		Modified:   modified.UTC().Format("2006-01-02T15:04:05Z"),
+		Blocks:     make([]epubBlock, 0, len(manifest.Segments)),
+		Clips:      make([]epubClip, 0),
+	}
+
+	addItem := func(id string, file string) {
+		extension := strings.TrimPrefix(path.Ext(file), ".")
+		mediaType, ok := epubMediaTypes[extension]
+		if !ok {
+			mediaType = "application/octet-stream"
+		}
+		book.Items = append(book.Items, epubItem{ID: id, Href: file, MediaType: mediaType})
+	}
+
+	for i, segment := range manifest.Segments {
+		if segment.File == "" {
+			continue
+		}
+		blockID := fmt.Sprintf("s%d", i+1)
+
+		switch segment.Type {
+		case domain.ImageSegmentType:
+			addItem("img-"+blockID, segment.File)
+			book.Blocks = append(book.Blocks, epubBlock{ID: blockID, Image: segment.File, Text: segment.Text})
+		case domain.AudioSegmentType:
+			addItem("audio-"+blockID, segment.File)
+			block := epubBlock{ID: blockID, Text: segment.Text}
+
+			displayed := strings.Fields(segment.Text)
+			if len(displayed) > 0 && len(displayed) == len(segment.Words) {
+				for j, word := range displayed {
+					wordID := fmt.Sprintf("%s-w%d", blockID, j+1)
+					block.Words = append(block.Words, epubWord{ID: wordID, Text: word})
+					book.Clips = append(book.Clips, epubClip{
+						ID:    "par-" + wordID,
+						Text:  wordID,
+						Audio: segment.File,
+						Begin: segment.Words[j].Start,
+						End:   segment.Words[j].End,
+					})
+				}
+			} else if end := segmentAudioDuration(segment); end > 0 {
+				book.Clips = append(book.Clips, epubClip{ID: "par-" + blockID, Text: blockID, Audio: segment.File, End: end})
+			}
+			book.Blocks = append(book.Blocks, block)
+		}
+	}
+
+	if len(book.Clips) > 0 {
+		duration := 0.0
+		for _, clip := range book.Clips {
+			duration += clip.End - clip.Begin
+		}
+		book.Duration = epubClock(duration)
+	}
+
+	return book
+}
+
+func segmentAudioDuration(segment domain.ManifestSegment) float64 {
+	if len(segment.Words) > 0 {
+		return segment.Words[len(segment.Words)-1].End
+	}
+	if segment.Media != nil {
+		return segment.Media.Duration
+	}
+
+	return 0
+}
+
+func epubClock(seconds float64) string {
+	return fmt.Sprintf("%.3fs", seconds)
+}
+
+func escapeXML(value string) (string, error) {
+	var buf bytes.Buffer
+	err := xml.EscapeText(&buf, []byte(value))
+
+	return buf.String(), err
+}
+
+var epubTemplates = template.Must(template.New("epub").Funcs(template.FuncMap{
+	"xml":   escapeXML,
+	"clock": epubClock,
+}).Parse(`
+{{- define "container.xml" -}}
+<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+{{end}}
+
+{{- define "content.opf" -}}
+<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="{{xml .Language}}">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">{{xml .Identifier}}</dc:identifier>
+    <dc:title>{{xml .Title}}</dc:title>
+    <dc:language>{{xml .Language}}</dc:language>
+    <meta property="dcterms:modified">{{.Modified}}</meta>
+{{- if .Duration}}
+    <meta property="media:duration" refines="#overlay">{{.Duration}}</meta>
+    <meta property="media:duration">{{.Duration}}</meta>
+    <meta property="media:active-class">` + epubActiveClass + `</meta>
+{{- end}}
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="story" href="` + epubStoryDocument + `" media-type="application/xhtml+xml"{{if .Duration}} media-overlay="overlay"{{end}}/>
+{{- if .Duration}}
+    <item id="overlay" href="` + epubStoryOverlay + `" media-type="application/smil+xml"/>
+{{- end}}
+{{- range .Items}}
+    <item id="{{.ID}}" href="{{xml .Href}}" media-type="{{.MediaType}}"/>
+{{- end}}
+  </manifest>
+  <spine>
+    <itemref idref="story"/>
+  </spine>
+</package>
+{{end}}
+
+{{- define "nav.xhtml" -}}
+<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{xml .Language}}" lang="{{xml .Language}}">
+<head><title>{{xml .Title}}</title></head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <ol>
+      <li><a href="` + epubStoryDocument + `">{{xml .Title}}</a></li>
+    </ol>
+  </nav>
+</body>
+</html>
+{{end}}
+
+{{- define "story.xhtml" -}}
+<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="{{xml .Language}}" lang="{{xml .Language}}">
+<head>
+  <title>{{xml .Title}}</title>
+  <style>.` + epubActiveClass + ` { background-color: #fff4cc; } figure { text-align: center; } img { max-width: 100%; }</style>
+</head>
+<body>
+{{- range .Blocks}}
+{{- if .Image}}
+  <figure id="{{.ID}}"><img src="{{xml .Image}}" alt="{{xml .Text}}"/></figure>
+{{- else if .Words}}
+  <p id="{{.ID}}">{{range $i, $word := .Words}}{{if $i}} {{end}}<span id="{{$word.ID}}">{{xml $word.Text}}</span>{{end}}</p>
+{{- else}}
+  <p id="{{.ID}}">{{xml .Text}}</p>
+{{- end}}
+{{- end}}
+</body>
+</html>
+{{end}}
+
+{{- define "story.smil" -}}
+<?xml version="1.0" encoding="UTF-8"?>
+<smil xmlns="http://www.w3.org/ns/SMIL" xmlns:epub="http://www.idpf.org/2007/ops" version="3.0">
+  <body>
+    <seq id="seq-story" epub:textref="` + epubStoryDocument + `">
+{{- range .Clips}}
+      <par id="{{.ID}}">
+        <text src="` + epubStoryDocument + `#{{.Text}}"/>
+        <audio src="{{xml .Audio}}" clipBegin="{{clock .Begin}}" clipEnd="{{clock .End}}"/>
+      </par>
+{{- end}}
+    </seq>
+  </body>
+</smil>
+{{end}}
+`))
+
+// writeEpub follows the OCF container rules: the uncompressed mimetype
+// entry comes first, followed by META-INF/container.xml pointing at the
+// package document.
+func (s *storyExporter) writeEpub(ctx context.Context, w io.Writer, manifest domain.StoryManifest, files []exportFile, userID string) error {
+	archive := zip.NewWriter(w)
+	modified := time.Now()
+	book := buildEpubBook(manifest, modified)
+
+	// Written raw so the entry has neither a data descriptor nor the
+	// timestamp extra field, which strict readers reject.
+	mimetypeWriter, err := archive.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE([]byte(epubMimeType)),
+		CompressedSize64:   uint64(len(epubMimeType)),
+		UncompressedSize64: uint64(len(epubMimeType)),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(mimetypeWriter, epubMimeType); err != nil {
+		return err
+	}
+
+	documents := []epubDocument{
+		{name: "META-INF/container.xml", template: "container.xml"},
+		{name: path.Join(epubContentDir, "content.opf"), template: "content.opf"},
+		{name: path.Join(epubContentDir, "nav.xhtml"), template: "nav.xhtml"},
+		{name: path.Join(epubContentDir, epubStoryDocument), template: "story.xhtml"},
+	}
+	if book.Duration != "" {
+		documents = append(documents, epubDocument{name: path.Join(epubContentDir, epubStoryOverlay), template: "story.smil"})
+	}
+	for _, document := range documents {
+		documentWriter, err := archive.CreateHeader(&zip.FileHeader{Name: document.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		if err = epubTemplates.ExecuteTemplate(documentWriter, document.template, book); err != nil {
+			return err
+		}
+	}
+
+	included := make(map[string]bool, len(book.Items))
+	for _, item := range book.Items {
+		included[item.Href] = true
+	}
+	for _, file := range files {
+		if !included[file.name] {
+			continue
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = s.copyMedia(ctx, archive, path.Join(epubContentDir, file.name), file.object, userID, modified); err != nil {
+			s.logger.ErrorWithFields(err, "Failed to add media to story export", map[string]interface{}{
+				"story_id":   manifest.StoryID,
+				"segment_id": file.object.SegmentID,
+			})
+			return err
+		}
+	}
+
+	return archive.Close()
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"generate-script-lambda/application/ports/inbound"
+	"io"
+	"path"
+	"strings"
+	"testing"
+)
+
+type opfPackage struct {
+	UniqueIdentifier string `xml:"unique-identifier,attr"`
+	Metadata         struct {
+		Identifiers []struct {
+			ID    string `xml:"id,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"identifier"`
+		Titles    []string `xml:"title"`
+		Languages []string `xml:"language"`
+		Meta      []struct {
+			Property string `xml:"property,attr"`
+			Refines  string `xml:"refines,attr"`
+			Value    string `xml:",chardata"`
+		} `xml:"meta"`
+	} `xml:"metadata"`
+	Items []struct {
+		ID           string `xml:"id,attr"`
+		Href         string `xml:"href,attr"`
+		MediaType    string `xml:"media-type,attr"`
+		Properties   string `xml:"properties,attr"`
+		MediaOverlay string `xml:"media-overlay,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+type smilPars struct {
+	Pars []struct {
+		Text struct {
+			Src string `xml:"src,attr"`
+		} `xml:"text"`
+		Audio struct {
+			Src string `xml:"src,attr"`
+		} `xml:"audio"`
+	} `xml:"body>seq>par"`
+}
+
+// TestStoryExporter_ExportEpub checks the OCF and package rules an EPUB 3
+// reader depends on.
+func TestStoryExporter_ExportEpub(t *testing.T) {
+	archive := exportStory(t, newTestStoryExporter(t), inbound.EpubExportFormat)
+	files := readArchive(t, archive)
+
+	first := archive.File[0]
+	if first.Name != "mimetype" || first.Method != zip.Store || files["mimetype"] != "application/epub+zip" {
+		t.Fatalf("mimetype must be the first, uncompressed entry, got %s (method %d)", first.Name, first.Method)
+	}
+	if len(first.Extra) != 0 || first.Flags&0x8 != 0 {
+		t.Error("mimetype entry must not carry extra fields or a data descriptor")
+	}
+
+	var container struct {
+		Rootfiles []struct {
+			FullPath  string `xml:"full-path,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	decodeXML(t, files, "META-INF/container.xml", &container)
+	if len(container.Rootfiles) != 1 || container.Rootfiles[0].MediaType != "application/oebps-package+xml" {
+		t.Fatalf("unexpected rootfiles %+v", container.Rootfiles)
+	}
+	opfPath := container.Rootfiles[0].FullPath
+	baseDir := path.Dir(opfPath)
+
+	var opf opfPackage
+	decodeXML(t, files, opfPath, &opf)
+	if len(opf.Metadata.Identifiers) == 0 || opf.Metadata.Identifiers[0].ID != opf.UniqueIdentifier {
+		t.Error("unique-identifier must reference a dc:identifier")
+	}
+	if len(opf.Metadata.Titles) != 1 || opf.Metadata.Titles[0] != "The Lighthouse Keeper" ||
+		len(opf.Metadata.Languages) != 1 || opf.Metadata.Languages[0] != "de" {
+		t.Errorf("expected the story's dc:title and dc:language, got %v %v", opf.Metadata.Titles, opf.Metadata.Languages)
+	}
+	meta := make(map[string]string)
+	for _, m := range opf.Metadata.Meta {
+		meta[m.Refines+m.Property] = m.Value
+	}
+	if meta["dcterms:modified"] == "" {
+		t.Error("dcterms:modified is required")
+	}
+
+	items := make(map[string]string)
+	hasNav := false
+	listed := map[string]bool{"mimetype": true, "META-INF/container.xml": true, opfPath: true}
+	for _, item := range opf.Items {
+		href := path.Join(baseDir, item.Href)
+		if _, ok := files[href]; !ok {
+			t.Errorf("manifest item %s points at missing %s", item.ID, href)
+		}
+		if item.MediaType == "" {
+			t.Errorf("manifest item %s has no media type", item.ID)
+		}
+		if item.Properties == "nav" {
+			hasNav = true
+		}
+		if item.MediaOverlay != "" {
+			if meta["#"+item.MediaOverlay+"media:duration"] == "" {
+				t.Errorf("media overlay %s has no duration", item.MediaOverlay)
+			}
+		}
+		items[item.ID] = href
+		listed[href] = true
+	}
+	if !hasNav {
+		t.Error("package must declare a navigation document")
+	}
+	for name := range files {
+		if !listed[name] {
+			t.Errorf("%s is not listed in the package manifest", name)
+		}
+	}
+	for _, itemref := range opf.Spine {
+		if _, ok := items[itemref.IDRef]; !ok {
+			t.Errorf("spine references unknown item %s", itemref.IDRef)
+		}
+	}
+
+	for name := range files {
+		if strings.HasSuffix(name, ".xhtml") {
+			decodeXML(t, files, name, &struct{}{})
+		}
+	}
+
+	var overlay smilPars
+	decodeXML(t, files, items["overlay"], &overlay)
+	if len(overlay.Pars) != 5 {
+		t.Fatalf("expected 4 word clips and 1 paragraph clip, got %d", len(overlay.Pars))
+	}
+	for _, par := range overlay.Pars {
+		document, fragment, _ := strings.Cut(par.Text.Src, "#")
+		content := files[path.Join(baseDir, document)]
+		if !strings.Contains(content, `id="`+fragment+`"`) {
+			t.Errorf("overlay text %s does not resolve", par.Text.Src)
+		}
+		if _, ok := files[path.Join(baseDir, par.Audio.Src)]; !ok {
+			t.Errorf("overlay audio %s is missing", par.Audio.Src)
+		}
+	}
+}
+
+func decodeXML(t *testing.T, files map[string]string, name string, v interface{}) {
+	content, ok := files[name]
+	if !ok {
+		t.Fatalf("archive is missing %s", name)
+	}
+	decoder := xml.NewDecoder(bytes.NewReader([]byte(content)))
+	decoder.Strict = true
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(v); err != nil && err != io.EOF {
+		t.Fatalf("%s is not well-formed: %v", name, err)
+	}
+}
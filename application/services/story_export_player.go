@@ -0,0 +1,69 @@
+package services
+
+import "html/template"
+
+// storyPlayerTemplate is the offline player bundled with exports. It only
+// references files inside the archive, so it works straight from disk.
+var storyPlayerTemplate = template.Must(template.New("player").Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Georgia, serif; max-width: 42rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.6; color: #222; }
+figure { margin: 1.5rem 0; text-align: center; }
+img { max-width: 100%; border-radius: 4px; }
+.segment { padding: 0.5rem; border-radius: 4px; cursor: pointer; }
+.segment.playing { background: #fff4cc; }
+.controls { position: sticky; top: 0; background: #fff; padding: 0.5rem 0; border-bottom: 1px solid #ddd; }
+</style>
+</head>
+<body>
+<div class="controls">
+<button id="play">Play</button>
+<button id="pause">Pause</button>
+{{- with .StoryAudio}}{{if .File}}
+<a href="{{.File}}" download>Download full audio</a>
+{{- end}}{{end}}
+</div>
+{{- range .Segments}}
+{{- if eq .Type "image"}}{{if .File}}
+<figure><img src="{{.File}}" alt=""></figure>
+{{- end}}
+{{- else if eq .Type "audio"}}
+<p class="segment" data-src="{{.File}}">{{.Text}}</p>
+{{- end}}
+{{- end}}
+<audio id="player" preload="auto"></audio>
+<script>
+(function () {
+  var player = document.getElementById("player");
+  var segments = Array.prototype.filter.call(document.querySelectorAll(".segment"), function (el) {
+    return el.dataset.src;
+  });
+  var current = -1;
+
+  function play(index) {
+    if (current >= 0) segments[current].classList.remove("playing");
+    current = index;
+    if (current >= segments.length) { current = -1; return; }
+    segments[current].classList.add("playing");
+    segments[current].scrollIntoView({ behavior: "smooth", block: "center" });
+    player.src = segments[current].dataset.src;
+    player.play();
+  }
+
+  segments.forEach(function (el, index) {
+    el.addEventListener("click", function () { play(index); });
+  });
+  player.addEventListener("ended", function () { play(current + 1); });
+  document.getElementById("play").addEventListener("click", function () {
+    if (current < 0) play(0); else player.play();
+  });
+  document.getElementById("pause").addEventListener("click", function () { player.pause(); });
+})();
+</script>
+</body>
+</html>
+`))
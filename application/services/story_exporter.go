@@ -0,0 +1,244 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"io"
+	"path"
+	"time"
+)
+
+var mediaFileExtensions = map[string]string{
+	"audio/mpeg": "mp3",
+	"audio/wav":  "wav",
+	"audio/ogg":  "ogg",
+	"image/png":  "png",
+	"image/jpeg": "jpg",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+var defaultMediaFileExtensions = map[domain.SegmentType]string{
+	domain.AudioSegmentType:      "mp3",
+	domain.ImageSegmentType:      "png",
+	domain.StoryAudioSegmentType: "mp3",
+}
+
+type storyExportFormat struct {
+	extension   string
+	contentType string
+	write       func(s *storyExporter, ctx context.Context, w io.Writer, manifest domain.StoryManifest, files []exportFile, userID string) error
+}
+
+var storyExportFormats = map[inbound.ExportFormat]storyExportFormat{
+	inbound.ZipExportFormat:  {extension: "zip", contentType: "application/zip", write: (*storyExporter).writeZip},
+	inbound.EpubExportFormat: {extension: "epub", contentType: "application/epub+zip", write: (*storyExporter).writeEpub},
+}
+
+type exportFile struct {
+	name   string
+	object domain.MediaObject
+}
+
+type storyExporter struct {
+	logger          outbound.LoggerPort
+	storyRepository outbound.StoryRepositoryPort
+	mediaStore      outbound.SegmentMediaStorePort
+}
+
+func NewStoryExporter(logger outbound.LoggerPort, storyRepository outbound.StoryRepositoryPort,
+	mediaStore outbound.SegmentMediaStorePort) inbound.StoryExporterPort {
+	return &storyExporter{
+		logger:          logger,
+		storyRepository: storyRepository,
+		mediaStore:      mediaStore,
+	}
+}
+
+func (s *storyExporter) Export(ctx context.Context, params inbound.ExportStoryParams) (inbound.StoryExport, error) {
+	format, ok := storyExportFormats[params.Format]
+	if !ok {
+		return inbound.StoryExport{}, fmt.Errorf("%w: unsupported export format %q", domain.ErrInvalidInput, params.Format)
+	}
+
+	story, err := s.storyRepository.Get(ctx, params.UserID, params.StoryID)
+	if err != nil {
+		return inbound.StoryExport{}, err
+	}
+
+	objects, err := s.mediaStore.ListMedia(ctx, params.StoryID, params.UserID)
+	if err != nil {
+		return inbound.StoryExport{}, err
+	}
+	if len(objects) == 0 {
+		return inbound.StoryExport{}, fmt.Errorf("%w: media of story %s", domain.ErrNotFound, params.StoryID)
+	}
+
+	manifest, files := buildStoryManifest(story, objects)
+
+	return inbound.StoryExport{
+		FileName:    fmt.Sprintf("story-%s.%s", params.StoryID, format.extension),
+		ContentType: format.contentType,
+		Write: func(w io.Writer) error {
+			return format.write(s, ctx, w, manifest, files, params.UserID)
+		},
+	}, nil
+}
+
+func (s *storyExporter) writeZip(ctx context.Context, w io.Writer, manifest domain.StoryManifest, files []exportFile, userID string) error {
+	archive := zip.NewWriter(w)
+	modified := time.Now()
+
+	manifestWriter, err := archive.CreateHeader(&zip.FileHeader{Name: "manifest.json", Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(manifestWriter)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(manifest); err != nil {
+		return err
+	}
+
+	playerWriter, err := archive.CreateHeader(&zip.FileHeader{Name: "index.html", Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	if err = storyPlayerTemplate.Execute(playerWriter, manifest); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = s.copyMedia(ctx, archive, file.name, file.object, userID, modified); err != nil {
+			s.logger.ErrorWithFields(err, "Failed to add media to story export", map[string]interface{}{
+				"story_id":   manifest.StoryID,
+				"segment_id": file.object.SegmentID,
+			})
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// copyMedia stores media without compression: audio and images are
+// already compressed and deflating them again only costs CPU.
+func (s *storyExporter) copyMedia(ctx context.Context, archive *zip.Writer, name string, object domain.MediaObject, userID string,
+	modified time.Time) error {
+	content, err := s.mediaStore.OpenMedia(ctx, object, userID)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	method := zip.Store
+	if object.Extension != "" {
+		method = zip.Deflate
+	}
+	entry, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: method, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, content)
+
+	return err
+}
+
+// buildStoryManifest lays the segments of the story record, which outlives
+// the segment cache, out in reading order and assigns an archive path to
+// every stored object that belongs to one of them.
+func buildStoryManifest(story domain.Story, objects []domain.MediaObject) (domain.StoryManifest, []exportFile) {
+	storyID := story.ID
+	segments := make([]domain.SegmentWithMediaUrl, 0, len(story.Segments))
+	for _, segment := range story.Segments {
+		segments = append(segments, segment.ToSegment(storyID))
+	}
+
+	stored := make(map[string]map[string]domain.MediaObject)
+	for _, object := range objects {
+		key := storedObjectKey(object.Type, object.SegmentID)
+		if stored[key] == nil {
+			stored[key] = make(map[string]domain.MediaObject)
+		}
+		stored[key][object.Extension] = object
+	}
+
+	manifest := domain.StoryManifest{
+		StoryID:  storyID,
+		Title:    story.Title(),
+		Language: story.Language(),
+		Segments: make([]domain.ManifestSegment, 0, len(segments)),
+	}
+	files := make([]exportFile, 0, len(objects))
+
+	addFiles := func(entry *domain.ManifestSegment, mimeType string) {
+		attached := stored[storedObjectKey(entry.Type, entry.SegmentID)]
+		if object, ok := attached[""]; ok {
+			entry.File = mediaFileName(entry.Type, entry.SegmentID, mediaFileExtension(entry.Type, mimeType))
+			files = append(files, exportFile{name: entry.File, object: object})
+		}
+		if object, ok := attached["vtt"]; ok {
+			entry.VttFile = mediaFileName(entry.Type, entry.SegmentID, "vtt")
+			files = append(files, exportFile{name: entry.VttFile, object: object})
+		}
+		if object, ok := attached["srt"]; ok {
+			entry.SrtFile = mediaFileName(entry.Type, entry.SegmentID, "srt")
+			files = append(files, exportFile{name: entry.SrtFile, object: object})
+		}
+	}
+
+	for _, segment := range domain.SortInReadingOrder(segments) {
+		entry := domain.ManifestSegment{
+			SegmentID: segment.ID,
+			Type:      segment.Type,
+			Ordinal:   segment.Ordinal,
+			Text:      domain.StripImagePlaceholders(segment.Text),
+			Media:     segment.Metadata,
+		}
+		if segment.Alignment != nil {
+			entry.Words = segment.Alignment.Words
+		}
+		mimeType := ""
+		if segment.Metadata != nil {
+			mimeType = segment.Metadata.MimeType
+		}
+		addFiles(&entry, mimeType)
+		manifest.Segments = append(manifest.Segments, entry)
+	}
+
+	// The assembled story audio is never cached as a segment; it is stored
+	// under the story ID.
+	if _, ok := stored[storedObjectKey(domain.StoryAudioSegmentType, storyID)]; ok {
+		storyAudio := domain.ManifestSegment{SegmentID: storyID, Type: domain.StoryAudioSegmentType}
+		addFiles(&storyAudio, "")
+		manifest.StoryAudio = &storyAudio
+	}
+
+	return manifest, files
+}
+
+func storedObjectKey(segmentType domain.SegmentType, segmentID string) string {
+	return string(segmentType) + "/" + segmentID
+}
+
+func mediaFileExtension(segmentType domain.SegmentType, mimeType string) string {
+	if extension, ok := mediaFileExtensions[mimeType]; ok {
+		return extension
+	}
+	if extension, ok := defaultMediaFileExtensions[segmentType]; ok {
+		return extension
+	}
+
+	return "bin"
+}
+
+func mediaFileName(segmentType domain.SegmentType, segmentID string, extension string) string {
+	return path.Join("media", string(segmentType), segmentID+"."+extension)
+}
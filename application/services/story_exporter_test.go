@@ -0,0 +1,187 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/adapters"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type staticSegmentCache struct {
+	segments []domain.SegmentWithMediaUrl
+	owners   []string
+}
+
+func (s *staticSegmentCache) Save(ctx context.Context, segment domain.SegmentWithMediaUrl, userID string) error {
+	s.segments = append(s.segments, segment)
+	s.owners = append(s.owners, userID)
+	return nil
+}
+
+func (s *staticSegmentCache) SaveBatch(ctx context.Context, segments []domain.SegmentWithMediaUrl, userID string) ([]domain.SegmentWithMediaUrl, error) {
+	for _, segment := range segments {
+		_ = s.Save(ctx, segment, userID)
+	}
+	return nil, nil
+}
+
+func (s *staticSegmentCache) List(ctx context.Context, storyID string) ([]domain.SegmentWithMediaUrl, error) {
+	return s.segments, nil
+}
+
+func (s *staticSegmentCache) Query(ctx context.Context, query outbound.SegmentQuery) ([]domain.SegmentWithMediaUrl, error) {
+	matched := make([]domain.SegmentWithMediaUrl, 0)
+	for i, segment := range s.segments {
+		if segment.StoryID == query.StoryID && s.owners[i] == query.UserID && (query.Type == "" || segment.Type == query.Type) {
+			matched = append(matched, segment)
+		}
+	}
+	return matched, nil
+}
+
+func newTestStory(t *testing.T) (*staticSegmentCache, outbound.SegmentMediaStorePort) {
+	ctx := context.Background()
+	mediaStore := adapters.NewMemorySegmentMediaStore()
+	cache := &staticSegmentCache{}
+
+	segments := []domain.SegmentWithMediaUrl{
+		{Segment: domain.NewSegment("{img-1} Once upon a time.", domain.AudioSegmentType, "audio-1", "story-1", 0),
+			Alignment: &domain.Alignment{Words: []domain.WordTiming{
+				{Word: "Once", Start: 0, End: 0.4}, {Word: "upon", Start: 0.4, End: 0.8},
+				{Word: "a", Start: 0.8, End: 0.9}, {Word: "time.", Start: 0.9, End: 1.5},
+			}}},
+		{Segment: domain.NewSegment("a castle", domain.ImageSegmentType, "img-1", "story-1", 0),
+			Metadata: &domain.MediaMetadata{MimeType: "image/jpeg"}},
+		{Segment: domain.NewSegment("The end.", domain.AudioSegmentType, "audio-2", "story-1", 1),
+			Metadata: &domain.MediaMetadata{MimeType: "audio/mpeg", Duration: 1.2}},
+	}
+	for _, segment := range segments {
+		_, err := mediaStore.Save(ctx, domain.SegmentWithMedia{MediaContent: []byte(segment.ID), Segment: segment.Segment}, "user-1")
+		if err != nil {
+			t.Fatal("Failed to save media:", err)
+		}
+		_ = cache.Save(ctx, segment, "user-1")
+	}
+
+	return cache, mediaStore
+}
+
+// newTestStoryRepository records the cached test story as a completed story
+// of user-1.
+func newTestStoryRepository(t *testing.T, cache *staticSegmentCache) outbound.StoryRepositoryPort {
+	ctx := context.Background()
+	logger := adapters.NewZerologWrapper()
+	repository, err := adapters.NewSqliteStoryRepository(filepath.Join(t.TempDir(), "stories.db"), logger)
+	if err != nil {
+		t.Fatal("Failed to create story repository:", err)
+	}
+
+	events := make([]domain.SegmentEvent, 0, len(cache.segments))
+	for _, segment := range cache.segments {
+		events = append(events, segment.ToEvent())
+	}
+	history := NewStoryHistory(logger, repository, "elevenlabs", "gpt-4o", true)
+	err = history.Start(ctx, inbound.StartStoryParams{StoryID: "story-1", UserID: "user-1", Parameters: domain.StoryParameters{
+		Input: "# The Lighthouse Keeper\n\nOnce upon a time.", Source: domain.ManuscriptStorySource, Language: "de",
+	}})
+	if err == nil {
+		err = history.Complete(ctx, inbound.CompleteStoryParams{StoryID: "story-1", UserID: "user-1", Segments: events})
+	}
+	if err != nil {
+		t.Fatal("Failed to record story:", err)
+	}
+
+	return repository
+}
+
+func newTestStoryExporter(t *testing.T) inbound.StoryExporterPort {
+	cache, mediaStore := newTestStory(t)
+
+	return NewStoryExporter(adapters.NewZerologWrapper(), newTestStoryRepository(t, cache), mediaStore)
+}
+
+func exportStory(t *testing.T, exporter inbound.StoryExporterPort, format inbound.ExportFormat) *zip.Reader {
+	export, err := exporter.Export(context.Background(), inbound.ExportStoryParams{StoryID: "story-1", UserID: "user-1", Format: format})
+	if err != nil {
+		t.Fatal("Failed to export story:", err)
+	}
+	var buf bytes.Buffer
+	if err = export.Write(&buf); err != nil {
+		t.Fatal("Failed to write export:", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal("Failed to read archive:", err)
+	}
+
+	return archive
+}
+
+func readArchive(t *testing.T, archive *zip.Reader) map[string]string {
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal("Failed to open archive entry:", err)
+		}
+		content, _ := io.ReadAll(reader)
+		_ = reader.Close()
+		files[file.Name] = string(content)
+	}
+
+	return files
+}
+
+func TestStoryExporter_Export(t *testing.T) {
+	ctx := context.Background()
+	exporter := newTestStoryExporter(t)
+
+	_, err := exporter.Export(ctx, inbound.ExportStoryParams{StoryID: "story-1", UserID: "user-2", Format: inbound.ZipExportFormat})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected not found for another user, got %v", err)
+	}
+
+	_, err = exporter.Export(ctx, inbound.ExportStoryParams{StoryID: "story-1", UserID: "user-1", Format: "tar"})
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected invalid input for unknown format, got %v", err)
+	}
+
+	files := readArchive(t, exportStory(t, exporter, inbound.ZipExportFormat))
+
+	for _, name := range []string{"index.html", "media/image/img-1.jpg", "media/audio/audio-1.mp3", "media/audio/audio-2.mp3"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("archive is missing %s", name)
+		}
+	}
+
+	var manifest domain.StoryManifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil {
+		t.Fatal("Failed to decode manifest:", err)
+	}
+	order := make([]string, 0, len(manifest.Segments))
+	for _, segment := range manifest.Segments {
+		order = append(order, segment.SegmentID)
+	}
+	if len(order) != 3 || order[0] != "img-1" || order[1] != "audio-1" || order[2] != "audio-2" {
+		t.Errorf("unexpected reading order %v", order)
+	}
+	if manifest.Title != "The Lighthouse Keeper" || manifest.Language != "de" {
+		t.Errorf("expected the title and language of the story record, got %q, %q", manifest.Title, manifest.Language)
+	}
+	if !strings.Contains(files["index.html"], `<html lang="de">`) {
+		t.Error("player does not declare the story language")
+	}
+	if manifest.Segments[1].Text != "Once upon a time." {
+		t.Errorf("placeholder was not stripped: %q", manifest.Segments[1].Text)
+	}
+}
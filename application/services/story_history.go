@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"time"
+)
+
+const (
+	defaultStoryPageSize = 20
+	maxStoryPageSize     = 100
+	storyUpdateAttempts  = 3
+)
+
+type storyHistory struct {
+	logger             outbound.LoggerPort
+	repository         outbound.StoryRepositoryPort
+	defaultTtsProvider string
+	scriptModel        string
+	// mediaUrlsExpire keeps signed media URLs out of the record, which
+	// outlives them.
+	mediaUrlsExpire bool
+}
+
+func NewStoryHistory(logger outbound.LoggerPort, repository outbound.StoryRepositoryPort, defaultTtsProvider string,
+	scriptModel string, mediaUrlsExpire bool) inbound.StoryHistoryPort {
+	return &storyHistory{
+		logger:             logger,
+		repository:         repository,
+		defaultTtsProvider: defaultTtsProvider,
+		scriptModel:        scriptModel,
+		mediaUrlsExpire:    mediaUrlsExpire,
+	}
+}
+
+func (s *storyHistory) Start(ctx context.Context, params inbound.StartStoryParams) error {
+	provider, _ := domain.SplitVoiceID(params.Parameters.VoiceID, s.defaultTtsProvider)
+	now := time.Now().UTC()
+
+	return s.repository.Save(ctx, domain.Story{
+		ID:         params.StoryID,
+		UserID:     params.UserID,
+		Status:     domain.GeneratingStoryStatus,
+		Parameters: params.Parameters,
+		RemixOf:    params.RemixOf,
+		Provider:   provider,
+		Model:      s.scriptModel,
+		Segments:   make([]domain.StorySegment, 0),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+}
+
+func (s *storyHistory) Complete(ctx context.Context, params inbound.CompleteStoryParams) error {
+	return s.update(ctx, params.UserID, params.StoryID, func(story *domain.Story) {
+		story.Segments = make([]domain.StorySegment, 0, len(params.Segments))
+		for _, event := range params.Segments {
+			story.Segments = append(story.Segments, s.storySegment(params.StoryID, event))
+		}
+		story.FullAudioURL = ""
+		story.FullAudioMediaPath = ""
+		if params.Assets.FullAudioURL != "" {
+			story.FullAudioMediaPath = domain.SegmentMediaPath(params.StoryID, params.StoryID)
+			if !s.mediaUrlsExpire {
+				story.FullAudioURL = params.Assets.FullAudioURL
+			}
+		}
+		story.Status = domain.CompletedStoryStatus
+	})
+}
+
+func (s *storyHistory) Fail(ctx context.Context, storyID string, userID string) error {
+	return s.update(ctx, userID, storyID, func(story *domain.Story) {
+		story.Status = domain.FailedStoryStatus
+	})
+}
+
+// RecordEdit appends the edit and replaces the recorded segment, so the
+// story record replays the corrected text.
+func (s *storyHistory) RecordEdit(ctx context.Context, params inbound.RecordEditParams) error {
+	return s.update(ctx, params.UserID, params.StoryID, func(story *domain.Story) {
+		edit := params.Edit
+		edit.EditedAt = time.Now().UTC()
+		story.Edits = append(story.Edits, edit)
+
+		for i, segment := range story.Segments {
+			if segment.SegmentID == params.Segment.SegmentId {
+				story.Segments[i] = s.storySegment(params.StoryID, params.Segment)
+			}
+		}
+	})
+}
+
+// update applies mutate to the stored story and writes it back only if it
+// did not change in between, starting over from a fresh read on conflicts.
+func (s *storyHistory) update(ctx context.Context, userID string, storyID string, mutate func(story *domain.Story)) error {
+	var err error
+	for attempt := 1; attempt <= storyUpdateAttempts; attempt++ {
+		var story domain.Story
+		story, err = s.repository.Get(ctx, userID, storyID)
+		if err != nil {
+			return err
+		}
+
+		previousUpdatedAt := story.UpdatedAt
+		mutate(&story)
+		// UpdatedAt is the version the next update is conditioned on, so it
+		// has to move forward at the millisecond precision it is stored in.
+		story.UpdatedAt = time.Now().UTC()
+		if !story.UpdatedAt.Truncate(time.Millisecond).After(previousUpdatedAt) {
+			story.UpdatedAt = previousUpdatedAt.Add(time.Millisecond)
+		}
+
+		err = s.repository.Update(ctx, story, previousUpdatedAt)
+		if !errors.Is(err, domain.ErrConflict) {
+			return err
+		}
+		s.logger.DebugWithFields("Story changed concurrently, retrying update", map[string]interface{}{
+			"story_id": storyID,
+			"attempt":  attempt,
+		})
+	}
+
+	return err
+}
+
+func (s *storyHistory) storySegment(storyID string, event domain.SegmentEvent) domain.StorySegment {
+	segment := domain.StorySegment{
+		SegmentID: event.SegmentId,
+		Type:      event.Type,
+		Text:      event.Text,
+		Ordinal:   event.Ordinal,
+		Version:   event.Version,
+		Mood:      event.Mood,
+		Words:     event.Words,
+		Media:     event.Media,
+		MediaPath: domain.SegmentMediaPath(storyID, event.SegmentId),
+	}
+	if !s.mediaUrlsExpire {
+		segment.URL = event.Url
+	}
+
+	return segment
+}
+
+func (s *storyHistory) List(ctx context.Context, params inbound.ListStoriesParams) (domain.StoryPage, error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultStoryPageSize
+	}
+	if limit > maxStoryPageSize {
+		limit = maxStoryPageSize
+	}
+	sortBy := params.SortBy
+	if sortBy == "" {
+		sortBy = domain.CreatedAtStorySortField
+	}
+
+	return s.repository.List(ctx, outbound.StoryListQuery{
+		UserID:    params.UserID,
+		SortBy:    sortBy,
+		Ascending: params.Ascending,
+		Limit:     limit,
+		Cursor:    params.Cursor,
+	})
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/adapters"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// racingStoryRepository lets another writer update the story right before
+// the next conflicts updates go through.
+type racingStoryRepository struct {
+	outbound.StoryRepositoryPort
+	conflicts int
+	queries   []outbound.StoryListQuery
+}
+
+func (r *racingStoryRepository) Update(ctx context.Context, story domain.Story, previousUpdatedAt time.Time) error {
+	if r.conflicts > 0 {
+		r.conflicts--
+		concurrent, err := r.Get(ctx, story.UserID, story.ID)
+		if err != nil {
+			return err
+		}
+		concurrent.Edits = append(concurrent.Edits, domain.SegmentEdit{SegmentID: "concurrent", EditorID: "user-2"})
+		concurrent.UpdatedAt = previousUpdatedAt.Add(time.Second)
+		if err = r.StoryRepositoryPort.Save(ctx, concurrent); err != nil {
+			return err
+		}
+	}
+
+	return r.StoryRepositoryPort.Update(ctx, story, previousUpdatedAt)
+}
+
+func (r *racingStoryRepository) List(ctx context.Context, query outbound.StoryListQuery) (domain.StoryPage, error) {
+	r.queries = append(r.queries, query)
+	return r.StoryRepositoryPort.List(ctx, query)
+}
+
+func newTestStoryHistory(t *testing.T, mediaUrlsExpire bool) (inbound.StoryHistoryPort, *racingStoryRepository) {
+	logger := adapters.NewZerologWrapper()
+	sqlite, err := adapters.NewSqliteStoryRepository(filepath.Join(t.TempDir(), "stories.db"), logger)
+	if err != nil {
+		t.Fatal("Failed to create story repository:", err)
+	}
+	repository := &racingStoryRepository{StoryRepositoryPort: sqlite}
+
+	err = NewStoryHistory(logger, repository, "elevenlabs", "gpt", mediaUrlsExpire).Start(context.Background(), inbound.StartStoryParams{
+		StoryID: "story-1", UserID: "user-1", Parameters: domain.StoryParameters{Input: "a castle", VoiceID: "openai:alloy"},
+	})
+	if err != nil {
+		t.Fatal("Failed to start story:", err)
+	}
+
+	return NewStoryHistory(logger, repository, "elevenlabs", "gpt", mediaUrlsExpire), repository
+}
+
+func TestStoryHistory_Complete(t *testing.T) {
+	ctx := context.Background()
+	events := []domain.SegmentEvent{
+		{StoryID: "story-1", SegmentId: "img-1", Type: domain.ImageSegmentType, Text: "a castle", Url: "https://media/img-1"},
+		{StoryID: "story-1", SegmentId: "audio-1", Type: domain.AudioSegmentType, Text: "{img-1} Once.", Version: 2,
+			Mood: domain.CalmMood, Url: "https://media/audio-1", Words: []domain.WordTiming{{Word: "Once.", End: 0.5}}},
+	}
+
+	tests := []struct {
+		name            string
+		mediaUrlsExpire bool
+		segmentURL      string
+		fullAudioURL    string
+	}{
+		{name: "stable urls", segmentURL: "https://media/img-1", fullAudioURL: "https://media/story-1"},
+		{name: "expiring urls", mediaUrlsExpire: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history, repository := newTestStoryHistory(t, tt.mediaUrlsExpire)
+			err := history.Complete(ctx, inbound.CompleteStoryParams{
+				StoryID: "story-1", UserID: "user-1", Segments: events,
+				Assets: domain.StoryAssets{FullAudioURL: "https://media/story-1"},
+			})
+			if err != nil {
+				t.Fatal("Failed to complete story:", err)
+			}
+
+			story, err := repository.Get(ctx, "user-1", "story-1")
+			if err != nil {
+				t.Fatal("Failed to get story:", err)
+			}
+			if story.Status != domain.CompletedStoryStatus || story.Provider != "openai" || len(story.Segments) != 2 {
+				t.Fatalf("unexpected story %+v", story)
+			}
+			image, audio := story.Segments[0], story.Segments[1]
+			if image.URL != tt.segmentURL || image.MediaPath != "/stories/story-1/media/img-1" {
+				t.Errorf("unexpected image segment %+v", image)
+			}
+			if audio.Text != "{img-1} Once." || audio.Version != 2 || audio.Mood != domain.CalmMood || len(audio.Words) != 1 {
+				t.Errorf("expected the audio segment to be replayable, got %+v", audio)
+			}
+			if story.FullAudioURL != tt.fullAudioURL || story.FullAudioMediaPath != "/stories/story-1/media/story-1" {
+				t.Errorf("unexpected full audio %q, %q", story.FullAudioURL, story.FullAudioMediaPath)
+			}
+		})
+	}
+}
+
+func TestStoryHistory_RecordEdit(t *testing.T) {
+	ctx := context.Background()
+	edit := inbound.RecordEditParams{
+		StoryID: "story-1",
+		UserID:  "user-1",
+		Edit:    domain.SegmentEdit{SegmentID: "audio-1", EditorID: "user-1", PreviousText: "Once.", Text: "Twice.", Version: 2},
+		Segment: domain.SegmentEvent{SegmentId: "audio-1", Type: domain.AudioSegmentType, Text: "Twice.", Version: 2},
+	}
+
+	tests := []struct {
+		name      string
+		conflicts int
+		expected  error
+		edits     int
+	}{
+		{name: "uncontended", edits: 1},
+		{name: "retried after a concurrent update", conflicts: 2, edits: 3},
+		{name: "gives up", conflicts: storyUpdateAttempts, expected: domain.ErrConflict, edits: storyUpdateAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history, repository := newTestStoryHistory(t, false)
+			err := history.Complete(ctx, inbound.CompleteStoryParams{StoryID: "story-1", UserID: "user-1", Segments: []domain.SegmentEvent{
+				{SegmentId: "audio-1", Type: domain.AudioSegmentType, Text: "Once.", Version: 1},
+			}})
+			if err != nil {
+				t.Fatal("Failed to complete story:", err)
+			}
+			repository.conflicts = tt.conflicts
+
+			if err = history.RecordEdit(ctx, edit); !errors.Is(err, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+
+			story, _ := repository.Get(ctx, "user-1", "story-1")
+			if len(story.Edits) != tt.edits {
+				t.Fatalf("expected %d edits, got %+v", tt.edits, story.Edits)
+			}
+			if tt.expected == nil && (story.Segments[0].Text != "Twice." || story.Segments[0].Version != 2) {
+				t.Errorf("expected the recorded segment to be replaced, got %+v", story.Segments[0])
+			}
+		})
+	}
+}
+
+func TestStoryHistory_List(t *testing.T) {
+	history, repository := newTestStoryHistory(t, false)
+
+	tests := []struct {
+		params   inbound.ListStoriesParams
+		expected outbound.StoryListQuery
+	}{
+		{inbound.ListStoriesParams{UserID: "user-1"},
+			outbound.StoryListQuery{UserID: "user-1", SortBy: domain.CreatedAtStorySortField, Limit: defaultStoryPageSize}},
+		{inbound.ListStoriesParams{UserID: "user-1", Limit: 500, SortBy: domain.UpdatedAtStorySortField, Ascending: true},
+			outbound.StoryListQuery{UserID: "user-1", SortBy: domain.UpdatedAtStorySortField, Ascending: true, Limit: maxStoryPageSize}},
+		{inbound.ListStoriesParams{UserID: "user-1", Limit: 5, Cursor: "next"},
+			outbound.StoryListQuery{UserID: "user-1", SortBy: domain.CreatedAtStorySortField, Limit: 5, Cursor: "next"}},
+	}
+
+	for _, tt := range tests {
+		repository.queries = nil
+		_, _ = history.List(context.Background(), tt.params)
+		if len(repository.queries) != 1 || repository.queries[0] != tt.expected {
+			t.Errorf("%+v: expected query %+v, got %+v", tt.params, tt.expected, repository.queries)
+		}
+	}
+}
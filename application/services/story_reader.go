@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+)
+
+const (
+	defaultSegmentPageSize = 50
+	maxSegmentPageSize     = 200
+)
+
+type storyReader struct {
+	logger       outbound.LoggerPort
+	segmentCache outbound.SegmentCachePort
+}
+
+func NewStoryReader(logger outbound.LoggerPort, segmentCache outbound.SegmentCachePort) inbound.StoryReaderPort {
+	return &storyReader{
+		logger:       logger,
+		segmentCache: segmentCache,
+	}
+}
+
+// ListSegments pages through a story in reading order. Stories are small
+// enough to sort in memory, so the cursor is simply the last segment ID
+// returned, which stays valid while segments are still being added.
+func (s *storyReader) ListSegments(ctx context.Context, params inbound.ListSegmentsParams) (domain.SegmentPage, error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultSegmentPageSize
+	}
+	if limit > maxSegmentPageSize {
+		limit = maxSegmentPageSize
+	}
+
+	// The type filter is applied after the query, so that an owned story
+	// without segments of that type reads as an empty page, not as missing.
+	segments, err := s.segmentCache.Query(ctx, outbound.SegmentQuery{
+		StoryID: params.StoryID,
+		UserID:  params.UserID,
+	})
+	if err != nil {
+		return domain.SegmentPage{}, err
+	}
+	// Segments of another user's story are filtered out by the query, so
+	// both cases look the same to the caller, with or without a cursor.
+	if len(segments) == 0 {
+		return domain.SegmentPage{}, fmt.Errorf("%w: story %s", domain.ErrNotFound, params.StoryID)
+	}
+	if params.Type != "" {
+		ofType := make([]domain.SegmentWithMediaUrl, 0, len(segments))
+		for _, segment := range segments {
+			if segment.Type == params.Type {
+				ofType = append(ofType, segment)
+			}
+		}
+		segments = ofType
+	}
+	segments = domain.SortInReadingOrder(segments)
+
+	start := 0
+	if params.Cursor != "" {
+		start, err = cursorPosition(segments, params.Cursor)
+		if err != nil {
+			return domain.SegmentPage{}, err
+		}
+	}
+
+	end := start + limit
+	if end > len(segments) {
+		end = len(segments)
+	}
+
+	page := domain.SegmentPage{Segments: make([]domain.SegmentEvent, 0, end-start)}
+	for _, segment := range segments[start:end] {
+		page.Segments = append(page.Segments, segment.ToEvent())
+	}
+	if end < len(segments) {
+		page.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(segments[end-1].ID))
+	}
+
+	return page, nil
+}
+
+func cursorPosition(segments []domain.SegmentWithMediaUrl, cursor string) (int, error) {
+	segmentID, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		for i, segment := range segments {
+			if segment.ID == string(segmentID) {
+				return i + 1, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("%w: invalid cursor", domain.ErrInvalidInput)
+}
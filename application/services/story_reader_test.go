@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/adapters"
+	"testing"
+)
+
+func TestStoryReader_ListSegments(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newTestStory(t)
+	reader := NewStoryReader(adapters.NewZerologWrapper(), cache)
+
+	ids := make([]string, 0)
+	cursor := ""
+	for {
+		page, err := reader.ListSegments(ctx, inbound.ListSegmentsParams{StoryID: "story-1", UserID: "user-1", Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatal("Failed to list segments:", err)
+		}
+		for _, segment := range page.Segments {
+			ids = append(ids, segment.SegmentId)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(ids) != 3 || ids[0] != "img-1" || ids[1] != "audio-1" || ids[2] != "audio-2" {
+		t.Errorf("unexpected segments %v", ids)
+	}
+
+	page, err := reader.ListSegments(ctx, inbound.ListSegmentsParams{StoryID: "story-1", UserID: "user-1", Type: domain.ImageSegmentType})
+	if err != nil || len(page.Segments) != 1 || page.Segments[0].SegmentId != "img-1" {
+		t.Errorf("type filter returned %+v, %v", page.Segments, err)
+	}
+
+	page, err = reader.ListSegments(ctx, inbound.ListSegmentsParams{StoryID: "story-1", UserID: "user-1", Type: domain.StorybookSegmentType})
+	if err != nil || len(page.Segments) != 0 || page.NextCursor != "" {
+		t.Errorf("expected an empty page for a type without segments, got %+v, %v", page, err)
+	}
+
+	_, err = reader.ListSegments(ctx, inbound.ListSegmentsParams{StoryID: "story-1", UserID: "user-2"})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found for another user, got %v", err)
+	}
+
+	_, err = reader.ListSegments(ctx, inbound.ListSegmentsParams{StoryID: "story-1", UserID: "user-2", Cursor: "bogus"})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found for another user with a cursor, got %v", err)
+	}
+
+	_, err = reader.ListSegments(ctx, inbound.ListSegmentsParams{StoryID: "story-1", UserID: "user-1", Cursor: "bogus"})
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("expected invalid cursor, got %v", err)
+	}
+}
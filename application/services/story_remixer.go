@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/channel_utils"
+	"generate-script-lambda/domain"
+	"io"
+)
+
+type storyRemixer struct {
+	logger          outbound.LoggerPort
+	workerPool      outbound.TaskDispatcher
+	mediaStore      outbound.SegmentMediaStorePort
+	storyRepository outbound.StoryRepositoryPort
+	lexiconManager  inbound.LexiconManagerPort
+	mediaEnhancer   inbound.SegmentMediaEnhancerPort
+	mediaSaver      inbound.SegmentMediaSaverPort
+	metadataSaver   inbound.SegmentMetadataSaverPort
+	audioAssembler  inbound.StoryAudioAssemblerPort
+}
+
+func NewStoryRemixer(logger outbound.LoggerPort, workerPool outbound.TaskDispatcher, mediaStore outbound.SegmentMediaStorePort,
+	storyRepository outbound.StoryRepositoryPort, lexiconManager inbound.LexiconManagerPort, mediaEnhancer inbound.SegmentMediaEnhancerPort,
+	mediaSaver inbound.SegmentMediaSaverPort, metadataSaver inbound.SegmentMetadataSaverPort,
+	audioAssembler inbound.StoryAudioAssemblerPort) inbound.StoryRemixerPort {
+	return &storyRemixer{
+		logger:          logger,
+		workerPool:      workerPool,
+		mediaStore:      mediaStore,
+		storyRepository: storyRepository,
+		lexiconManager:  lexiconManager,
+		mediaEnhancer:   mediaEnhancer,
+		mediaSaver:      mediaSaver,
+		metadataSaver:   metadataSaver,
+		audioAssembler:  audioAssembler,
+	}
+}
+
+// Remix replays the stored text segments of a completed story through the
+// rest of the story pipeline under a new story ID. Only the stage whose input
+// changed runs again: audio for a new voice, images for a new style. Media of
+// the other type is copied from the source story.
+func (s *storyRemixer) Remix(ctx context.Context, params inbound.RemixStoryParams) (inbound.StoryRemix, error) {
+	if params.VoiceID == "" && params.ImageStyle == "" {
+		return inbound.StoryRemix{}, fmt.Errorf("%w: a voice or an image style is required", domain.ErrInvalidInput)
+	}
+
+	source, err := s.storyRepository.Get(ctx, params.UserID, params.SourceStoryID)
+	if err != nil {
+		return inbound.StoryRemix{}, err
+	}
+	if source.Status != domain.CompletedStoryStatus {
+		return inbound.StoryRemix{}, fmt.Errorf("%w: story %s is %s, only completed stories can be remixed",
+			domain.ErrInvalidInput, source.ID, source.Status)
+	}
+
+	// Stories recorded before segment text was kept have nothing to replay.
+	segments := make([]domain.SegmentWithMediaUrl, 0, len(source.Segments))
+	for _, segment := range source.Segments {
+		if segment.Text == "" {
+			return inbound.StoryRemix{}, fmt.Errorf("%w: text of story %s segment %s",
+				domain.ErrNotFound, source.ID, segment.SegmentID)
+		}
+		segments = append(segments, segment.ToSegment(source.ID))
+	}
+	if len(segments) == 0 {
+		return inbound.StoryRemix{}, fmt.Errorf("%w: segments of story %s", domain.ErrNotFound, params.SourceStoryID)
+	}
+
+	parameters := source.Parameters
+	if params.VoiceID != "" {
+		parameters.VoiceID = params.VoiceID
+		parameters.VoiceSettings = params.VoiceSettings
+	}
+	if params.ImageStyle != "" {
+		parameters.ImageStyle = params.ImageStyle
+	}
+
+	var lexicon []domain.LexiconEntry
+	if params.VoiceID != "" {
+		lexicon, err = s.lexiconManager.Resolve(ctx, params.UserID, params.SourceStoryID, parameters.LexiconID)
+		if err != nil {
+			s.logger.Error(err, "Failed to resolve pronunciation lexicon")
+			return inbound.StoryRemix{}, err
+		}
+	}
+
+	segmentCh, reusedCh, replayErrCh := s.replay(ctx, segments, params)
+
+	enhancedCh, mediaEnhancerErrCh := s.mediaEnhancer.Enhance(ctx, segmentCh, inbound.EnhanceSegmentsParams{
+		VoiceID:       parameters.VoiceID,
+		VoiceSettings: parameters.VoiceSettings,
+		Language:      parameters.Language,
+		ImageStyle:    parameters.ImageStyle,
+		UserID:        params.UserID,
+		Lexicon:       lexicon,
+	})
+
+	segmentWithMediaCh, err := channel_utils.MergeChannels(s.workerPool, enhancedCh, reusedCh)
+	if err != nil {
+		return inbound.StoryRemix{}, err
+	}
+
+	toSaveCh, toAssembleCh, err := channel_utils.TeeChannel(ctx, s.workerPool, segmentWithMediaCh)
+	if err != nil {
+		return inbound.StoryRemix{}, err
+	}
+
+	segmentWithMediaUrlCh, mediaSaverErrCh := s.mediaSaver.Save(ctx, toSaveCh, params.UserID)
+
+	segmentEventsCh, metadataSaverErrCh := s.metadataSaver.Save(ctx, segmentWithMediaUrlCh, params.UserID)
+
+	storyAssetsCh, audioAssemblerErrCh := s.audioAssembler.Assemble(ctx, toAssembleCh, inbound.AssembleStoryAudioParams{
+		StoryID: params.StoryID,
+		UserID:  params.UserID,
+	})
+
+	errCh, err := channel_utils.MergeChannels(s.workerPool, replayErrCh, mediaEnhancerErrCh, mediaSaverErrCh, metadataSaverErrCh, audioAssemblerErrCh)
+	if err != nil {
+		return inbound.StoryRemix{}, err
+	}
+
+	return inbound.StoryRemix{
+		Source:     source,
+		Parameters: parameters,
+		Segments:   segmentEventsCh,
+		Assets:     storyAssetsCh,
+		Errors:     errCh,
+	}, nil
+}
+
+// replay moves the source segments over to the new story and splits them
+// between the enhancer and the ones whose stored media is reused.
+func (s *storyRemixer) replay(ctx context.Context, segments []domain.SegmentWithMediaUrl,
+	params inbound.RemixStoryParams) (<-chan domain.Segment, <-chan domain.SegmentWithMedia, <-chan error) {
+	segmentCh := make(chan domain.Segment)
+	reusedCh := make(chan domain.SegmentWithMedia)
+	errCh := make(chan error)
+
+	err := s.workerPool.Submit(func() {
+		defer close(segmentCh)
+		defer close(reusedCh)
+		defer close(errCh)
+
+		for _, source := range domain.SortInReadingOrder(segments) {
+			segment := source.Segment
+			segment.StoryID = params.StoryID
+			segment.Version = 0
+
+			regenerate := (segment.Type == domain.AudioSegmentType && params.VoiceID != "") ||
+				(segment.Type == domain.ImageSegmentType && params.ImageStyle != "")
+			if regenerate {
+				select {
+				case <-ctx.Done():
+					return
+				case segmentCh <- segment:
+				}
+				continue
+			}
+
+			reused, err := s.reuseMedia(ctx, source, segment, params.UserID)
+			if err != nil {
+				select {
+				case <-ctx.Done():
+				case errCh <- err:
+				}
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case reusedCh <- reused:
+			}
+		}
+	})
+	if err != nil {
+		close(segmentCh)
+		close(reusedCh)
+		errCh = make(chan error, 1)
+		errCh <- err
+		close(errCh)
+	}
+
+	return segmentCh, reusedCh, errCh
+}
+
+func (s *storyRemixer) reuseMedia(ctx context.Context, source domain.SegmentWithMediaUrl, segment domain.Segment,
+	userID string) (domain.SegmentWithMedia, error) {
+	media, err := s.mediaStore.OpenMedia(ctx, domain.MediaObject{
+		StoryID:   source.StoryID,
+		SegmentID: source.ID,
+		Type:      source.Type,
+	}, userID)
+	if err != nil {
+		s.logger.ErrorWithFields(err, "Failed to open source segment media", map[string]interface{}{
+			"story_id":   source.StoryID,
+			"segment_id": source.ID,
+		})
+		return domain.SegmentWithMedia{}, err
+	}
+	defer func() {
+		_ = media.Close()
+	}()
+
+	content, err := io.ReadAll(media)
+	if err != nil {
+		return domain.SegmentWithMedia{}, err
+	}
+
+	reused := domain.SegmentWithMedia{
+		MediaContent: content,
+		Alignment:    source.Alignment,
+		Segment:      segment,
+	}
+	if source.Metadata != nil {
+		reused.MimeType = source.Metadata.MimeType
+	}
+
+	return reused, nil
+}
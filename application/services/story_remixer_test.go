@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/adapters"
+	"github.com/panjf2000/ants/v2"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoryRemixer_Remix(t *testing.T) {
+	ctx := context.Background()
+	workerPool, err := ants.NewPool(40)
+	if err != nil {
+		t.Fatal("Failed to create worker pool:", err)
+	}
+	defer workerPool.Release()
+
+	logger := adapters.NewZerologWrapper()
+	mediaStore := adapters.NewMemorySegmentMediaStore()
+	cache := adapters.NewMemorySegmentCache(time.Hour)
+	repository, err := adapters.NewSqliteStoryRepository(filepath.Join(t.TempDir(), "stories.db"), logger)
+	if err != nil {
+		t.Fatal("Failed to create story repository:", err)
+	}
+	// The source segments live only in the story record, not in the cache.
+	sourceSegments := []domain.StorySegment{
+		{SegmentID: "audio-1", Type: domain.AudioSegmentType, Text: "{img-1} Once.", Words: []domain.WordTiming{{Word: "Once", Start: 0, End: 0.5}}},
+		{SegmentID: "img-1", Type: domain.ImageSegmentType, Text: "a castle"},
+	}
+	for _, segment := range sourceSegments {
+		_, _ = mediaStore.Save(ctx, domain.SegmentWithMedia{MediaContent: []byte(segment.Text), Segment: segment.ToSegment("story-1").Segment}, "user-1")
+	}
+	_ = repository.Save(ctx, domain.Story{ID: "story-1", UserID: "user-1", Status: domain.CompletedStoryStatus,
+		Parameters: domain.StoryParameters{Input: "a castle story", VoiceID: "narrator", Language: "en"}, Segments: sourceSegments})
+	_ = repository.Save(ctx, domain.Story{ID: "story-2", UserID: "user-1", Status: domain.GeneratingStoryStatus})
+	_ = repository.Save(ctx, domain.Story{ID: "story-4", UserID: "user-1", Status: domain.CompletedStoryStatus,
+		Segments: []domain.StorySegment{{SegmentID: "audio-1", Type: domain.AudioSegmentType}}})
+
+	imageGenerator := &staticImageGenerator{}
+	audioGenerator := &recordingAudioGenerator{}
+	remixer := NewStoryRemixer(logger, workerPool, mediaStore, repository, NewLexiconManager(logger, emptyLexiconRepository{}),
+		NewSegmentMediaEnhancer(logger, imageGenerator, audioGenerator, mediaStore, workerPool, outbound.AudioFormatMP3, domain.VoiceSettings{}),
+		NewSegmentMediaSaver(logger, mediaStore, workerPool, 2),
+		NewSegmentMetadataSaver(logger, workerPool, cache, 2, 25, 10*time.Millisecond),
+		NewStoryAudioAssembler(logger, mediaStore, workerPool, true, 0))
+
+	for _, tt := range []struct {
+		params   inbound.RemixStoryParams
+		expected error
+	}{
+		{params: inbound.RemixStoryParams{SourceStoryID: "story-1", UserID: "user-1"}, expected: domain.ErrInvalidInput},
+		{params: inbound.RemixStoryParams{SourceStoryID: "story-2", UserID: "user-1", ImageStyle: "ink"}, expected: domain.ErrInvalidInput},
+		{params: inbound.RemixStoryParams{SourceStoryID: "story-1", UserID: "user-2", ImageStyle: "ink"}, expected: domain.ErrNotFound},
+		{params: inbound.RemixStoryParams{SourceStoryID: "story-4", UserID: "user-1", ImageStyle: "ink"}, expected: domain.ErrNotFound},
+	} {
+		if _, err := remixer.Remix(ctx, tt.params); !errors.Is(err, tt.expected) {
+			t.Errorf("Remix(%+v) returned %v, expected %v", tt.params, err, tt.expected)
+		}
+	}
+
+	remix, err := remixer.Remix(ctx, inbound.RemixStoryParams{
+		SourceStoryID: "story-1", StoryID: "story-3", UserID: "user-1", ImageStyle: "watercolor",
+	})
+	if err != nil {
+		t.Fatal("Failed to remix story:", err)
+	}
+	go func() {
+		for err := range remix.Errors {
+			t.Error("Remix failed:", err)
+		}
+	}()
+
+	events := make(map[string]domain.SegmentEvent)
+	for event := range remix.Segments {
+		events[event.SegmentId] = event
+	}
+	if _, ok := <-remix.Assets; !ok {
+		t.Fatal("Expected story assets")
+	}
+
+	if remix.Source.ID != "story-1" || remix.Parameters.VoiceID != "narrator" || remix.Parameters.ImageStyle != "watercolor" {
+		t.Fatalf("Unexpected remix parameters: %+v", remix.Parameters)
+	}
+	if len(events) != 2 || events["audio-1"].StoryID != "story-3" || len(events["audio-1"].Words) != 1 {
+		t.Fatalf("Unexpected remixed segments: %+v", events)
+	}
+	if len(audioGenerator.requests) != 0 {
+		t.Fatalf("Expected the narration to be reused, got %d tts requests", len(audioGenerator.requests))
+	}
+	if len(imageGenerator.styles) != 1 || imageGenerator.styles[0] != "watercolor" || imageGenerator.descriptions[0] != "a castle" {
+		t.Fatalf("Expected one restyled illustration, got %v %v", imageGenerator.descriptions, imageGenerator.styles)
+	}
+
+	audio := readMedia(t, mediaStore, domain.MediaObject{StoryID: "story-3", SegmentID: "audio-1", Type: domain.AudioSegmentType})
+	if audio != "{img-1} Once." {
+		t.Fatalf("Expected the source narration to be copied, got %q", audio)
+	}
+	segments, _ := cache.Query(ctx, outbound.SegmentQuery{StoryID: "story-3", UserID: "user-1"})
+	if len(segments) != 2 {
+		t.Fatalf("Expected the variant's segments to be cached, got %d", len(segments))
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"html"
+	"html/template"
+	"path"
+)
+
+type storybookPublisher struct {
+	logger          outbound.LoggerPort
+	storyRepository outbound.StoryRepositoryPort
+	mediaStore      outbound.SegmentMediaStorePort
+	themes          outbound.StorybookThemePort
+	// stableMediaUrls is false when media URLs expire or are signed; the
+	// page would neither stay shareable nor load its media then.
+	stableMediaUrls bool
+}
+
+func NewStorybookPublisher(logger outbound.LoggerPort, storyRepository outbound.StoryRepositoryPort,
+	mediaStore outbound.SegmentMediaStorePort, themes outbound.StorybookThemePort, stableMediaUrls bool) inbound.StorybookPublisherPort {
+	return &storybookPublisher{
+		logger:          logger,
+		storyRepository: storyRepository,
+		mediaStore:      mediaStore,
+		themes:          themes,
+		stableMediaUrls: stableMediaUrls,
+	}
+}
+
+// Publish renders the storybook and stores it next to the story's media,
+// always under the same key, so republishing keeps the shared URL intact.
+func (s *storybookPublisher) Publish(ctx context.Context, params inbound.PublishStorybookParams) (domain.PublishedStorybook, error) {
+	if !s.stableMediaUrls {
+		return domain.PublishedStorybook{}, fmt.Errorf("%w: storybooks need public media URLs", domain.ErrNotSupported)
+	}
+
+	story, err := s.storyRepository.Get(ctx, params.UserID, params.StoryID)
+	if err != nil {
+		return domain.PublishedStorybook{}, err
+	}
+
+	objects, err := s.mediaStore.ListMedia(ctx, params.StoryID, params.UserID)
+	if err != nil {
+		return domain.PublishedStorybook{}, err
+	}
+	if len(objects) == 0 {
+		return domain.PublishedStorybook{}, fmt.Errorf("%w: media of story %s", domain.ErrNotFound, params.StoryID)
+	}
+
+	manifest, _ := buildStoryManifest(story, objects)
+
+	// The page sits in the storybook folder of the story, so media is
+	// linked relative to it and keeps working behind any public base URL.
+	storybook := buildStorybook(manifest, func(segment domain.ManifestSegment) string {
+		return path.Join("..", string(segment.Type), segment.SegmentID)
+	})
+
+	var page bytes.Buffer
+	if err = s.template(ctx, params.Tenant).Execute(&page, storybook); err != nil {
+		s.logger.ErrorWithFields(err, "Failed to render storybook", map[string]interface{}{
+			"story_id": params.StoryID,
+			"tenant":   params.Tenant,
+		})
+		return domain.PublishedStorybook{}, err
+	}
+
+	storybookSegment := domain.NewSegment("", domain.StorybookSegmentType, params.StoryID, params.StoryID, 0)
+	url, err := s.mediaStore.SaveAttachment(ctx, storybookSegment, "html", page.Bytes(), params.UserID)
+	if err != nil {
+		return domain.PublishedStorybook{}, err
+	}
+
+	return domain.PublishedStorybook{
+		StoryID: params.StoryID,
+		URL:     url,
+		EmbedHTML: fmt.Sprintf(`<iframe src="%s" title="%s" width="100%%" height="640" style="border:0" allow="autoplay" loading="lazy"></iframe>`,
+			html.EscapeString(url), html.EscapeString(storybook.Title)),
+	}, nil
+}
+
+// template falls back to the default theme when the tenant has none or its
+// template does not parse, so a broken theme never blocks publishing.
+func (s *storybookPublisher) template(ctx context.Context, tenant string) *template.Template {
+	if tenant == "" {
+		return defaultStorybookTemplate
+	}
+
+	content, err := s.themes.FindTemplate(ctx, tenant)
+	if err != nil {
+		if !errors.Is(err, domain.ErrNotFound) {
+			s.logger.WarnWithFields("Failed to load storybook theme, using default", map[string]interface{}{
+				"tenant": tenant,
+				"error":  err.Error(),
+			})
+		}
+		return defaultStorybookTemplate
+	}
+
+	themed, err := template.New("storybook").Parse(content)
+	if err != nil {
+		s.logger.WarnWithFields("Invalid storybook theme, using default", map[string]interface{}{
+			"tenant": tenant,
+			"error":  err.Error(),
+		})
+		return defaultStorybookTemplate
+	}
+
+	return themed
+}
+
+// buildStorybook starts a new page at every illustration and collects the
+// narration that follows it.
+func buildStorybook(manifest domain.StoryManifest, link func(segment domain.ManifestSegment) string) domain.Storybook {
+	storybook := domain.Storybook{
+		StoryID:  manifest.StoryID,
+		Title:    manifest.Title,
+		Language: manifest.Language,
+		Pages:    make([]domain.StorybookPage, 0),
+	}
+
+	for _, segment := range manifest.Segments {
+		if segment.File == "" {
+			continue
+		}
+		switch segment.Type {
+		case domain.ImageSegmentType:
+			storybook.Pages = append(storybook.Pages, domain.StorybookPage{
+				Number:   len(storybook.Pages) + 1,
+				Image:    link(segment),
+				ImageAlt: segment.Text,
+			})
+		case domain.AudioSegmentType:
+			if len(storybook.Pages) == 0 {
+				storybook.Pages = append(storybook.Pages, domain.StorybookPage{Number: 1})
+			}
+			current := &storybook.Pages[len(storybook.Pages)-1]
+			current.Paragraphs = append(current.Paragraphs, domain.StorybookParagraph{
+				Text:  segment.Text,
+				Audio: link(segment),
+			})
+		}
+	}
+
+	return storybook
+}
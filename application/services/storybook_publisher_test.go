@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/adapters"
+	"io"
+	"strings"
+	"testing"
+)
+
+type staticStorybookThemes map[string]string
+
+func (s staticStorybookThemes) FindTemplate(ctx context.Context, tenant string) (string, error) {
+	if content, ok := s[tenant]; ok {
+		return content, nil
+	}
+	return "", fmt.Errorf("%w: theme %s", domain.ErrNotFound, tenant)
+}
+
+func TestStorybookPublisher_Publish(t *testing.T) {
+	ctx := context.Background()
+	cache, mediaStore := newTestStory(t)
+	repository := newTestStoryRepository(t, cache)
+	themes := staticStorybookThemes{
+		"acme":   `<h1>ACME</h1>{{range .Pages}}<img src="{{.Image}}">{{range .Paragraphs}}<p>{{.Text}}</p>{{end}}{{end}}`,
+		"broken": `{{range .Pages}`,
+	}
+	publisher := NewStorybookPublisher(adapters.NewZerologWrapper(), repository, mediaStore, themes, true)
+
+	tests := []struct {
+		tenant   string
+		contains []string
+	}{
+		{tenant: "", contains: []string{`<html lang="de">`, "<title>The Lighthouse Keeper</title>", `src="../image/img-1"`, `data-src="../audio/audio-1"`, "Once upon a time."}},
+		{tenant: "acme", contains: []string{"<h1>ACME</h1>", `<img src="../image/img-1">`, "<p>The end.</p>"}},
+		{tenant: "broken", contains: []string{`data-src="../audio/audio-2"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tenant, func(t *testing.T) {
+			published, err := publisher.Publish(ctx, inbound.PublishStorybookParams{StoryID: "story-1", UserID: "user-1", Tenant: tt.tenant})
+			if err != nil {
+				t.Fatal("Failed to publish storybook:", err)
+			}
+			if !strings.Contains(published.EmbedHTML, published.URL) {
+				t.Errorf("embed code does not reference %s", published.URL)
+			}
+
+			stored, err := mediaStore.OpenMedia(ctx, domain.MediaObject{
+				StoryID: "story-1", SegmentID: "story-1", Type: domain.StorybookSegmentType, Extension: "html",
+			}, "user-1")
+			if err != nil {
+				t.Fatal("Failed to open published storybook:", err)
+			}
+			content, _ := io.ReadAll(stored)
+			_ = stored.Close()
+			for _, expected := range tt.contains {
+				if !strings.Contains(string(content), expected) {
+					t.Errorf("storybook is missing %q", expected)
+				}
+			}
+		})
+	}
+
+	_, err := publisher.Publish(ctx, inbound.PublishStorybookParams{StoryID: "story-1", UserID: "user-2"})
+	if err == nil {
+		t.Error("expected publishing another user's story to fail")
+	}
+
+	signedPublisher := NewStorybookPublisher(adapters.NewZerologWrapper(), repository, mediaStore, themes, false)
+	_, err = signedPublisher.Publish(ctx, inbound.PublishStorybookParams{StoryID: "story-1", UserID: "user-1"})
+	if !errors.Is(err, domain.ErrNotSupported) {
+		t.Errorf("expected publishing with expiring media URLs to be rejected, got %v", err)
+	}
+}
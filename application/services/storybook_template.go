@@ -0,0 +1,108 @@
+package services
+
+import "html/template"
+
+// defaultStorybookTemplate is used for tenants without a theme. Tenant
+// themes receive the same domain.Storybook data; the colours here can be
+// restyled by overriding the CSS variables.
+var defaultStorybookTemplate = template.Must(template.New("storybook").Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+:root { --background: #fdfaf3; --text: #2b2b2b; --accent: #c0583b; --highlight: #fff1c1; --font: Georgia, serif; }
+html, body { margin: 0; height: 100%; background: var(--background); color: var(--text); font-family: var(--font); }
+.book { display: flex; flex-direction: column; height: 100%; }
+.page { display: none; flex: 1; overflow-y: auto; padding: 1.5rem; box-sizing: border-box; }
+.page.current { display: block; }
+.page img { display: block; max-width: 100%; max-height: 55vh; margin: 0 auto 1rem; border-radius: 6px; }
+.page p { font-size: 1.2rem; line-height: 1.6; padding: 0.25rem 0.5rem; border-radius: 4px; cursor: pointer; }
+.page p.playing { background: var(--highlight); }
+nav { display: flex; justify-content: space-between; align-items: center; padding: 0.75rem 1.5rem; border-top: 1px solid rgba(0, 0, 0, 0.1); }
+nav button { background: var(--accent); color: #fff; border: 0; border-radius: 4px; padding: 0.5rem 1rem; font: inherit; cursor: pointer; }
+nav button:disabled { opacity: 0.4; cursor: default; }
+</style>
+</head>
+<body>
+<div class="book">
+{{- range .Pages}}
+<section class="page" data-page="{{.Number}}">
+{{- if .Image}}
+<img src="{{.Image}}" alt="{{.ImageAlt}}">
+{{- end}}
+{{- range .Paragraphs}}
+<p data-src="{{.Audio}}">{{.Text}}</p>
+{{- end}}
+</section>
+{{- end}}
+<nav>
+<button id="prev">&larr;</button>
+<button id="play">Play</button>
+<span id="counter"></span>
+<button id="next">&rarr;</button>
+</nav>
+</div>
+<audio id="player"></audio>
+<script>
+(function () {
+  var pages = document.querySelectorAll(".page");
+  var player = document.getElementById("player");
+  var page = 0, paragraph = -1, autoplay = false;
+
+  function paragraphs() { return pages[page] ? pages[page].querySelectorAll("p") : []; }
+
+  function show(index) {
+    if (index < 0 || index >= pages.length) return;
+    player.pause();
+    clearHighlight();
+    pages[page].classList.remove("current");
+    page = index;
+    paragraph = -1;
+    pages[page].classList.add("current");
+    document.getElementById("counter").textContent = (page + 1) + " / " + pages.length;
+    document.getElementById("prev").disabled = page === 0;
+    document.getElementById("next").disabled = page === pages.length - 1;
+    if (autoplay) playNext();
+  }
+
+  function clearHighlight() {
+    var current = paragraphs()[paragraph];
+    if (current) current.classList.remove("playing");
+  }
+
+  function play(index) {
+    clearHighlight();
+    paragraph = index;
+    var current = paragraphs()[paragraph];
+    current.classList.add("playing");
+    player.src = current.dataset.src;
+    player.play();
+  }
+
+  function playNext() {
+    if (paragraph + 1 < paragraphs().length) { play(paragraph + 1); return; }
+    clearHighlight();
+    if (page + 1 < pages.length) show(page + 1); else autoplay = false;
+  }
+
+  player.addEventListener("ended", function () { if (autoplay) playNext(); else clearHighlight(); });
+  document.getElementById("prev").addEventListener("click", function () { show(page - 1); });
+  document.getElementById("next").addEventListener("click", function () { show(page + 1); });
+  document.getElementById("play").addEventListener("click", function () {
+    autoplay = !autoplay;
+    if (autoplay) playNext(); else player.pause();
+  });
+  Array.prototype.forEach.call(pages, function (section) {
+    Array.prototype.forEach.call(section.querySelectorAll("p"), function (p, index) {
+      p.addEventListener("click", function () { play(index); });
+    });
+  });
+
+  if (pages.length) show(0);
+})();
+</script>
+</body>
+</html>
+`))
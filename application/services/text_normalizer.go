@@ -0,0 +1,489 @@
+package services
+
+import (
+	"generate-script-lambda/domain"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type normalizationRule struct {
+	pattern *regexp.Regexp
+	replace func(groups []string) string
+}
+
+type currencyNames struct {
+	singular      string
+	plural        string
+	minorSingular string
+	minorPlural   string
+}
+
+var markdownRules = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?m)^[ \t]{0,3}#{1,6}[ \t]+`), ""},
+	{regexp.MustCompile(`(?m)^[ \t]*>[ \t]?`), ""},
+	{regexp.MustCompile(`(?m)^[ \t]*[-*+][ \t]+`), ""},
+	{regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`), "$1"},
+	{regexp.MustCompile(`\*\*([^*]+)\*\*`), "$1"},
+	{regexp.MustCompile(`__([^_]+)__`), "$1"},
+	{regexp.MustCompile(`~~([^~]+)~~`), "$1"},
+	{regexp.MustCompile(`\*([^*\s][^*]*)\*`), "$1"},
+	{regexp.MustCompile(`\b_([^_\s][^_]*)_\b`), "$1"},
+	{regexp.MustCompile("`([^`]*)`"), "$1"},
+}
+
+var quoteReplacer = strings.NewReplacer(
+	"“", `"`, "”", `"`, "„", `"`, "«", `"`, "»", `"`,
+	"‘", "'", "’", "'", "‚", "'", "‹", "'", "›", "'",
+	"…", "...",
+)
+
+var whitespaceRegexp = regexp.MustCompile(`\s+`)
+
+var spaceBeforePunctuationRegexp = regexp.MustCompile(`\s+([.,!?;:])`)
+
+var normalizationLocales = map[string][]normalizationRule{
+	"en": englishNormalizationRules(),
+	"de": germanNormalizationRules(),
+}
+
+// cleanSpeechText removes what a narrator should never read out, markdown
+// and emoji, and unifies quotes and whitespace. It is locale independent and
+// runs before speech markup is parsed.
+func cleanSpeechText(text string) string {
+	for _, rule := range markdownRules {
+		text = rule.pattern.ReplaceAllString(text, rule.replacement)
+	}
+	text = strings.Map(func(r rune) rune {
+		if isEmoji(r) {
+			return -1
+		}
+		return r
+	}, text)
+	text = quoteReplacer.Replace(text)
+
+	text = whitespaceRegexp.ReplaceAllString(text, " ")
+	text = spaceBeforePunctuationRegexp.ReplaceAllString(text, "$1")
+
+	return strings.TrimSpace(text)
+}
+
+// normalizeSpeech spells out numbers, dates, currencies, ordinals and
+// abbreviations in the plain text of the speech following the rules of the
+// story language. Languages without rules are passed through, leaving the
+// reading to the provider.
+func normalizeSpeech(speech domain.Speech, language string) domain.Speech {
+	rules, ok := normalizationLocales[normalizationLanguage(language)]
+	if !ok {
+		return speech
+	}
+
+	result := make(domain.Speech, len(speech))
+	for i, token := range speech {
+		if token.Type == domain.TextSpeechToken || token.Type == domain.EmphasisSpeechToken {
+			for _, rule := range rules {
+				token.Text = replaceAllSubmatchFunc(rule.pattern, token.Text, rule.replace)
+			}
+		}
+		result[i] = token
+	}
+
+	return result
+}
+
+func normalizationLanguage(language string) string {
+	if language == "" {
+		return "en"
+	}
+	language, _, _ = strings.Cut(strings.ToLower(language), "-")
+	language, _, _ = strings.Cut(language, "_")
+
+	return language
+}
+
+func replaceAllSubmatchFunc(pattern *regexp.Regexp, text string, replace func(groups []string) string) string {
+	var builder strings.Builder
+	position := 0
+	for _, match := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		groups := make([]string, len(match)/2)
+		for i := range groups {
+			if match[2*i] != -1 {
+				groups[i] = text[match[2*i]:match[2*i+1]]
+			}
+		}
+		builder.WriteString(text[position:match[0]])
+		builder.WriteString(replace(groups))
+		position = match[1]
+	}
+	builder.WriteString(text[position:])
+
+	return builder.String()
+}
+
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF:
+	case r >= 0x2600 && r <= 0x27BF:
+	case r >= 0x2B00 && r <= 0x2BFF:
+	case r >= 0xE0020 && r <= 0xE007F:
+	case r == 0x200D, r == 0xFE0F, r == 0x20E3:
+	default:
+		return false
+	}
+	return true
+}
+
+func abbreviationRule(abbreviations map[string]string) normalizationRule {
+	return normalizationRule{
+		pattern: regexp.MustCompile(`(^|[^\p{L}\p{N}.])(` + abbreviationAlternatives(abbreviations) + `)`),
+		replace: func(groups []string) string {
+			return groups[1] + abbreviations[groups[2]]
+		},
+	}
+}
+
+// sentenceFinalAbbreviationRule expands an abbreviation that closes the text,
+// keeping its period as the end of the sentence. It runs before
+// abbreviationRule, which would swallow the period.
+func sentenceFinalAbbreviationRule(abbreviations map[string]string) normalizationRule {
+	return normalizationRule{
+		pattern: regexp.MustCompile(`(^|[^\p{L}\p{N}.])(` + abbreviationAlternatives(abbreviations) + `)(["')\]]*)$`),
+		replace: func(groups []string) string {
+			expansion := abbreviations[groups[2]]
+			if strings.HasSuffix(groups[2], ".") {
+				expansion += "."
+			}
+			return groups[1] + expansion + groups[3]
+		},
+	}
+}
+
+// abbreviationAlternatives joins the abbreviations longest first, so that an
+// abbreviation is never matched by a shorter one it starts with.
+func abbreviationAlternatives(abbreviations map[string]string) string {
+	alternatives := make([]string, 0, len(abbreviations))
+	for abbreviation := range abbreviations {
+		alternatives = append(alternatives, abbreviation)
+	}
+	sort.Slice(alternatives, func(i, j int) bool {
+		return len(alternatives[i]) > len(alternatives[j])
+	})
+	for i, abbreviation := range alternatives {
+		alternatives[i] = regexp.QuoteMeta(abbreviation)
+	}
+
+	return strings.Join(alternatives, "|")
+}
+
+func spellDigits(digits string, ones []string) string {
+	words := make([]string, 0, len(digits))
+	for _, digit := range digits {
+		words = append(words, ones[digit-'0'])
+	}
+
+	return strings.Join(words, " ")
+}
+
+// spellNumber reads an integer part followed by optional decimals digit by
+// digit. Integers too large for int64 are read digit by digit as well.
+func spellNumber(integer string, decimals string, cardinal func(int64) string, ones []string, separator string) string {
+	value, err := strconv.ParseInt(integer, 10, 64)
+	words := spellDigits(integer, ones)
+	if err == nil {
+		words = cardinal(value)
+	}
+	if decimals != "" {
+		words += " " + separator + " " + spellDigits(decimals, ones)
+	}
+
+	return words
+}
+
+func parseDate(year string, month string, day string) (int64, int, int64, bool) {
+	y, yearErr := strconv.ParseInt(year, 10, 64)
+	m, monthErr := strconv.Atoi(month)
+	d, dayErr := strconv.ParseInt(day, 10, 64)
+	if yearErr != nil || monthErr != nil || dayErr != nil || m < 1 || m > 12 || d < 1 || d > 31 {
+		return 0, 0, 0, false
+	}
+
+	return y, m, d, true
+}
+
+func parseMoney(major string, minor string, thousandsSeparator string) (int64, int64, bool) {
+	majorValue, err := strconv.ParseInt(strings.ReplaceAll(major, thousandsSeparator, ""), 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if minor == "" {
+		return majorValue, 0, true
+	}
+	if len(minor) == 1 {
+		minor += "0"
+	}
+	minorValue, err := strconv.ParseInt(minor, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return majorValue, minorValue, true
+}
+
+var englishMonths = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September",
+	"October", "November", "December"}
+
+var englishCurrencies = map[string]currencyNames{
+	"$": {singular: "dollar", plural: "dollars", minorSingular: "cent", minorPlural: "cents"},
+	"€": {singular: "euro", plural: "euros", minorSingular: "cent", minorPlural: "cents"},
+	"£": {singular: "pound", plural: "pounds", minorSingular: "penny", minorPlural: "pence"},
+}
+
+func englishNormalizationRules() []normalizationRule {
+	englishDate := func(year int64, month int, day int64) string {
+		return englishMonths[month-1] + " " + englishOrdinal(day) + ", " + englishYear(year)
+	}
+	englishNumber := func(integer string, decimals string) string {
+		return spellNumber(strings.ReplaceAll(integer, ",", ""), decimals, englishCardinal, englishOnes, "point")
+	}
+
+	abbreviations := map[string]string{
+		"Mr.":     "Mister",
+		"Mrs.":    "Missus",
+		"Ms.":     "Miz",
+		"Dr.":     "Doctor",
+		"Prof.":   "Professor",
+		"Jr.":     "Junior",
+		"Sr.":     "Senior",
+		"vs.":     "versus",
+		"etc.":    "et cetera",
+		"e.g.":    "for example",
+		"i.e.":    "that is",
+		"approx.": "approximately",
+	}
+
+	return []normalizationRule{
+		sentenceFinalAbbreviationRule(abbreviations),
+		abbreviationRule(abbreviations),
+		{
+			pattern: regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`),
+			replace: func(groups []string) string {
+				year, month, day, ok := parseDate(groups[1], groups[2], groups[3])
+				if !ok {
+					return groups[0]
+				}
+				return englishDate(year, month, day)
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})/(\d{4})\b`),
+			replace: func(groups []string) string {
+				year, month, day, ok := parseDate(groups[3], groups[1], groups[2])
+				if !ok {
+					return groups[0]
+				}
+				return englishDate(year, month, day)
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`\b(` + strings.Join(englishMonths, "|") + `) (\d{1,2})(?:st|nd|rd|th)?\b`),
+			replace: func(groups []string) string {
+				day, _ := strconv.ParseInt(groups[2], 10, 64)
+				return groups[1] + " " + englishOrdinal(day)
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`([$€£])\s?(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d{1,2}))?\b`),
+			replace: func(groups []string) string {
+				major, minor, ok := parseMoney(groups[2], groups[3], ",")
+				if !ok {
+					return groups[0]
+				}
+				names := englishCurrencies[groups[1]]
+				parts := make([]string, 0, 2)
+				if major > 0 || minor == 0 {
+					parts = append(parts, englishCardinal(major)+" "+pluralize(major, names.singular, names.plural))
+				}
+				if minor > 0 {
+					parts = append(parts, englishCardinal(minor)+" "+pluralize(minor, names.minorSingular, names.minorPlural))
+				}
+				return strings.Join(parts, " and ")
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`\b(\d+)(?:\.(\d+))?\s?%`),
+			replace: func(groups []string) string {
+				return englishNumber(groups[1], groups[2]) + " percent"
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`\b(\d+)(?:st|nd|rd|th)\b`),
+			replace: func(groups []string) string {
+				value, err := strconv.ParseInt(groups[1], 10, 64)
+				if err != nil {
+					return groups[0]
+				}
+				return englishOrdinal(value)
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`\b((?i:in|since|by|from|until|before|after|of) )(\d{4})\b`),
+			replace: func(groups []string) string {
+				year, _ := strconv.ParseInt(groups[2], 10, 64)
+				return groups[1] + englishYear(year)
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`\b(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d+))?\b`),
+			replace: func(groups []string) string {
+				return englishNumber(groups[1], groups[2])
+			},
+		},
+	}
+}
+
+var germanMonths = []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September",
+	"Oktober", "November", "Dezember"}
+
+var germanCurrencies = map[string]currencyNames{
+	"€":   {singular: "Euro", plural: "Euro", minorSingular: "Cent", minorPlural: "Cent"},
+	"EUR": {singular: "Euro", plural: "Euro", minorSingular: "Cent", minorPlural: "Cent"},
+	"$":   {singular: "Dollar", plural: "Dollar", minorSingular: "Cent", minorPlural: "Cent"},
+	"£":   {singular: "Pfund", plural: "Pfund", minorSingular: "Penny", minorPlural: "Pence"},
+}
+
+func germanNormalizationRules() []normalizationRule {
+	const datePrefix = `(?:^|\b)((?i:am|vom|zum|seit dem|bis zum) )?`
+	const germanAmount = `(\d{1,3}(?:\.\d{3})+|\d+)(?:,(\d{1,2}))?`
+
+	// Dates read as "dritter Mai" on their own and "am dritten Mai" after a
+	// dative preposition.
+	germanDate := func(prefix string, day int64, month string, year string) string {
+		words := germanOrdinal(day) + "r"
+		if prefix != "" {
+			words = prefix + germanOrdinal(day) + "n"
+		}
+		words += " " + month
+		if year != "" {
+			value, _ := strconv.ParseInt(year, 10, 64)
+			words += " " + germanYear(value)
+		}
+		return words
+	}
+	germanNumber := func(integer string, decimals string) string {
+		return spellNumber(strings.ReplaceAll(integer, ".", ""), decimals, germanCardinal, germanOnes, "Komma")
+	}
+	germanMoney := func(groups []string, currency string, major string, minor string) string {
+		majorValue, minorValue, ok := parseMoney(major, minor, ".")
+		if !ok {
+			return groups[0]
+		}
+		names := germanCurrencies[currency]
+		parts := make([]string, 0, 2)
+		if majorValue > 0 || minorValue == 0 {
+			parts = append(parts, germanCountWord(majorValue)+" "+pluralize(majorValue, names.singular, names.plural))
+		}
+		if minorValue > 0 {
+			parts = append(parts, germanCountWord(minorValue)+" "+pluralize(minorValue, names.minorSingular, names.minorPlural))
+		}
+		return strings.Join(parts, " und ")
+	}
+
+	abbreviations := map[string]string{
+		"z.B.":  "zum Beispiel",
+		"z. B.": "zum Beispiel",
+		"d.h.":  "das heißt",
+		"usw.":  "und so weiter",
+		"bzw.":  "beziehungsweise",
+		"ca.":   "circa",
+		"Dr.":   "Doktor",
+		"Prof.": "Professor",
+		"Nr.":   "Nummer",
+		"evtl.": "eventuell",
+		"inkl.": "inklusive",
+		"Hr.":   "Herr",
+		"Fr.":   "Frau",
+	}
+
+	return []normalizationRule{
+		sentenceFinalAbbreviationRule(abbreviations),
+		abbreviationRule(abbreviations),
+		{
+			pattern: regexp.MustCompile(datePrefix + `(\d{4})-(\d{2})-(\d{2})\b`),
+			replace: func(groups []string) string {
+				_, month, day, ok := parseDate(groups[2], groups[3], groups[4])
+				if !ok {
+					return groups[0]
+				}
+				return germanDate(groups[1], day, germanMonths[month-1], groups[2])
+			},
+		},
+		{
+			pattern: regexp.MustCompile(datePrefix + `(\d{1,2})\.(\d{1,2})\.(\d{4})\b`),
+			replace: func(groups []string) string {
+				_, month, day, ok := parseDate(groups[4], groups[3], groups[2])
+				if !ok {
+					return groups[0]
+				}
+				return germanDate(groups[1], day, germanMonths[month-1], groups[4])
+			},
+		},
+		{
+			pattern: regexp.MustCompile(datePrefix + `(\d{1,2})\. (` + strings.Join(germanMonths, "|") + `)`),
+			replace: func(groups []string) string {
+				day, _ := strconv.ParseInt(groups[2], 10, 64)
+				return germanDate(groups[1], day, groups[3], "")
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`\b` + germanAmount + `\s?(€|EUR|\$|£)`),
+			replace: func(groups []string) string {
+				return germanMoney(groups, groups[3], groups[1], groups[2])
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`(€|\$|£)\s?` + germanAmount + `\b`),
+			replace: func(groups []string) string {
+				return germanMoney(groups, groups[1], groups[2], groups[3])
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`\b(\d+)(?:,(\d+))?\s?%`),
+			replace: func(groups []string) string {
+				return germanNumber(groups[1], groups[2]) + " Prozent"
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`\b((?i:im jahre?|seit|bis|um) )(\d{4})\b`),
+			replace: func(groups []string) string {
+				year, _ := strconv.ParseInt(groups[2], 10, 64)
+				return groups[1] + germanYear(year)
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`\b(\d{1,3}(?:\.\d{3})+|\d+)(?:,(\d+))?\b`),
+			replace: func(groups []string) string {
+				return germanNumber(groups[1], groups[2])
+			},
+		},
+	}
+}
+
+// germanCountWord uses the article form "ein" for counted nouns, as in
+// "ein Euro", instead of the bare number "eins".
+func germanCountWord(n int64) string {
+	if n == 1 {
+		return "ein"
+	}
+	return germanCardinal(n)
+}
+
+func pluralize(count int64, singular string, plural string) string {
+	if count == 1 {
+		return singular
+	}
+	return plural
+}
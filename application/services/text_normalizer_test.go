@@ -0,0 +1,56 @@
+package services
+
+import "testing"
+
+func TestNormalizeSpeech_Golden(t *testing.T) {
+	tests := []struct {
+		language string
+		input    string
+		expected string
+	}{
+		{"en", "She counted 42 stars.", "She counted forty-two stars."},
+		{"en", "The castle had 1,250 rooms.", "The castle had one thousand two hundred fifty rooms."},
+		{"en", "Pi is roughly 3.14.", "Pi is roughly three point one four."},
+		{"en", "It cost $5 at the market.", "It cost five dollars at the market."},
+		{"en", "A ticket was $1.50, a pie £2.", "A ticket was one dollar and fifty cents, a pie two pounds."},
+		{"en", "Only €0.99!", "Only ninety-nine cents!"},
+		{"en", "She finished 1st, he was 22nd.", "She finished first, he was twenty-second."},
+		{"en", "The 3rd and 12th doors.", "The third and twelfth doors."},
+		{"en", "It happened on 2024-03-05.", "It happened on March fifth, twenty twenty-four."},
+		{"en", "Born 07/04/1905.", "Born July fourth, nineteen oh five."},
+		{"en", "On March 3 the snow came.", "On March third the snow came."},
+		{"en", "It was built in 1999.", "It was built in nineteen ninety-nine."},
+		{"en", "Since 2005 nobody came.", "Since two thousand five nobody came."},
+		{"en", "The odds were 50%.", "The odds were fifty percent."},
+		{"en", "Mr. and Mrs. Smith met Dr. Who.", "Mister and Missus Smith met Doctor Who."},
+		{"en", "Bring fruit, e.g. apples, etc.", "Bring fruit, for example apples, et cetera."},
+		{"en", "“Pens, inks, etc.”", `"Pens, inks, et cetera."`},
+		{"en", "**Bold** and *quiet* and `code`.", "Bold and quiet and code."},
+		{"en", "# Chapter One\n- a dragon\n> a whisper", "Chapter One a dragon a whisper"},
+		{"en", "The fox 🦊 smiled ✨ wide.", "The fox smiled wide."},
+		{"en", "“Hello,” she said, ‘twice’…", `"Hello," she said, 'twice'...`},
+		{"en-US", "Room 101.", "Room one hundred one."},
+		{"", "Chapter 7.", "Chapter seven."},
+		{"de", "Sie zählte 21 Sterne.", "Sie zählte einundzwanzig Sterne."},
+		{"de", "Das Schloss hatte 1.250 Zimmer.", "Das Schloss hatte eintausendzweihundertfünfzig Zimmer."},
+		{"de", "Es waren 2.000.000 Jahre.", "Es waren zwei Millionen Jahre."},
+		{"de", "Pi ist etwa 3,14.", "Pi ist etwa drei Komma eins vier."},
+		{"de", "Das kostet 5 €.", "Das kostet fünf Euro."},
+		{"de", "Nur 1,50 € pro Stück.", "Nur ein Euro und fünfzig Cent pro Stück."},
+		{"de", "Am 3. Mai schneite es.", "Am dritten Mai schneite es."},
+		{"de", "Geboren 24.12.1999.", "Geboren vierundzwanzigster Dezember neunzehnhundertneunundneunzig."},
+		{"de", "Seit 1989 war es still.", "Seit neunzehnhundertneunundachtzig war es still."},
+		{"de", "Die Chance war 75%.", "Die Chance war fünfundsiebzig Prozent."},
+		{"de", "Er kam z.B. mit Dr. Müller usw.", "Er kam zum Beispiel mit Doktor Müller und so weiter."},
+		{"de", "Zimmer 101.", "Zimmer einhunderteins."},
+		{"de", "„Hallo“, sagte sie.", `"Hallo", sagte sie.`},
+		{"fr", "Il avait 42 ans 😀.", "Il avait 42 ans."},
+	}
+
+	for _, test := range tests {
+		actual := normalizeSpeech(parseSpeechMarkup(cleanSpeechText(test.input)), test.language).PlainText()
+		if actual != test.expected {
+			t.Errorf("%s %q:\n got: %q\nwant: %q", test.language, test.input, actual, test.expected)
+		}
+	}
+}
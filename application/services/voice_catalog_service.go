@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"strings"
+	"sync"
+	"time"
+)
+
+type voiceCatalogService struct {
+	logger          outbound.LoggerPort
+	catalogs        []outbound.VoiceCatalogPort
+	providers       map[string]bool
+	defaultProvider string
+	cacheTTL        time.Duration
+
+	mu        sync.Mutex
+	voices    []domain.Voice
+	expiresAt time.Time
+	refresh   *voiceRefresh
+}
+
+// voiceRefresh is a catalog fetch in flight, shared by every request that
+// finds the cache empty while it runs.
+type voiceRefresh struct {
+	done   chan struct{}
+	voices []domain.Voice
+	err    error
+}
+
+// NewVoiceCatalogService validates voices against the catalogs of the given
+// configured TTS providers.
+func NewVoiceCatalogService(logger outbound.LoggerPort, catalogs []outbound.VoiceCatalogPort, providers []string,
+	defaultProvider string, cacheTTL time.Duration) inbound.VoiceCatalogServicePort {
+	configured := make(map[string]bool, len(providers))
+	for _, provider := range providers {
+		configured[provider] = true
+	}
+
+	return &voiceCatalogService{
+		logger:          logger,
+		catalogs:        catalogs,
+		providers:       configured,
+		defaultProvider: defaultProvider,
+		cacheTTL:        cacheTTL,
+	}
+}
+
+func (v *voiceCatalogService) List(ctx context.Context, filter inbound.VoiceFilter) ([]domain.Voice, error) {
+	voices, err := v.getVoices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]domain.Voice, 0, len(voices))
+	for _, voice := range voices {
+		if matchesFilter(voice.Provider, filter.Provider) && matchesFilter(voice.Language, filter.Language) &&
+			matchesFilter(voice.Gender, filter.Gender) && matchesFilter(voice.Age, filter.Age) {
+			filtered = append(filtered, voice)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (v *voiceCatalogService) Validate(ctx context.Context, voiceID string) error {
+	provider, id := domain.SplitVoiceID(voiceID, v.defaultProvider)
+	if id == "" {
+		return fmt.Errorf("%w: voice_id is empty", domain.ErrInvalidInput)
+	}
+	if !v.providers[provider] {
+		return fmt.Errorf("%w: tts provider %s is not configured", domain.ErrInvalidInput, provider)
+	}
+
+	voices, err := v.getVoices(ctx)
+	if err != nil {
+		v.logger.WarnWithFields("Voice catalog unavailable, skipping voice validation", map[string]interface{}{
+			"voice_id": voiceID,
+			"error":    err.Error(),
+		})
+		return nil
+	}
+
+	providerListed := false
+	fullID := domain.JoinVoiceID(provider, id)
+	for _, voice := range voices {
+		if voice.ID == fullID {
+			return nil
+		}
+		if voice.Provider == provider {
+			providerListed = true
+		}
+	}
+
+	if !providerListed {
+		v.logger.DebugWithFields("No voice catalog for provider, skipping voice validation", map[string]interface{}{
+			"provider": provider,
+		})
+		return nil
+	}
+
+	return fmt.Errorf("%w: unknown voice_id %s", domain.ErrInvalidInput, voiceID)
+}
+
+// getVoices serves the cached catalog and refreshes it once it expires. The
+// providers are fetched without holding the lock: a single request refreshes
+// while the others serve the stale voices, or wait for the refresh when there
+// is nothing cached yet.
+func (v *voiceCatalogService) getVoices(ctx context.Context) ([]domain.Voice, error) {
+	v.mu.Lock()
+	if v.voices != nil && time.Now().Before(v.expiresAt) {
+		voices := v.voices
+		v.mu.Unlock()
+		return voices, nil
+	}
+	stale := v.voices
+	refresh := v.refresh
+	if refresh != nil {
+		v.mu.Unlock()
+		if stale != nil {
+			return stale, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-refresh.done:
+			return refresh.voices, refresh.err
+		}
+	}
+	refresh = &voiceRefresh{done: make(chan struct{})}
+	v.refresh = refresh
+	v.mu.Unlock()
+
+	refresh.voices, refresh.err = v.fetchVoices(ctx)
+
+	v.mu.Lock()
+	if refresh.err == nil {
+		v.voices = refresh.voices
+		v.expiresAt = time.Now().Add(v.cacheTTL)
+	}
+	v.refresh = nil
+	v.mu.Unlock()
+	close(refresh.done)
+
+	if refresh.err != nil {
+		if stale != nil {
+			v.logger.Error(refresh.err, "Failed to refresh voice catalog, serving stale voices")
+			return stale, nil
+		}
+		return nil, refresh.err
+	}
+
+	return refresh.voices, nil
+}
+
+func (v *voiceCatalogService) fetchVoices(ctx context.Context) ([]domain.Voice, error) {
+	voices := make([]domain.Voice, 0)
+	for _, catalog := range v.catalogs {
+		catalogVoices, err := catalog.ListVoices(ctx)
+		if err != nil {
+			return nil, err
+		}
+		voices = append(voices, catalogVoices...)
+	}
+
+	return voices, nil
+}
+
+func matchesFilter(value string, filter string) bool {
+	return filter == "" || strings.EqualFold(value, filter)
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/adapters"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type slowVoiceCatalog struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (c *slowVoiceCatalog) ListVoices(ctx context.Context) ([]domain.Voice, error) {
+	c.calls.Add(1)
+	<-c.release
+	return []domain.Voice{{ID: "elevenlabs:rachel", Provider: "elevenlabs"}}, nil
+}
+
+func TestVoiceCatalogService_RefreshesOnce(t *testing.T) {
+	catalog := &slowVoiceCatalog{release: make(chan struct{})}
+	service := NewVoiceCatalogService(adapters.NewZerologWrapper(), []outbound.VoiceCatalogPort{catalog}, []string{"elevenlabs"},
+		"elevenlabs", time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			voices, err := service.List(context.Background(), inbound.VoiceFilter{})
+			if err != nil || len(voices) != 1 {
+				t.Errorf("unexpected voices %v, %v", voices, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(catalog.release)
+	wg.Wait()
+
+	if calls := catalog.calls.Load(); calls != 1 {
+		t.Errorf("expected a single catalog fetch, got %d", calls)
+	}
+}
+
+func TestVoiceCatalogService_Validate(t *testing.T) {
+	catalog := &slowVoiceCatalog{release: make(chan struct{})}
+	close(catalog.release)
+	service := NewVoiceCatalogService(adapters.NewZerologWrapper(), []outbound.VoiceCatalogPort{catalog},
+		[]string{"elevenlabs", "piper"}, "elevenlabs", time.Minute)
+
+	tests := []struct {
+		voiceID string
+		valid   bool
+	}{
+		{"elevenlabs:rachel", true},
+		{"rachel", true},
+		{"elevenlabs:nobody", false},
+		{"piper:en_US-amy", true},
+		{"elevenlbs:rachel", false},
+		{"openai:alloy", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		err := service.Validate(context.Background(), test.voiceID)
+		if test.valid && err != nil {
+			t.Errorf("%q: unexpected error %v", test.voiceID, err)
+		}
+		if !test.valid && !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("%q: expected invalid input, got %v", test.voiceID, err)
+		}
+	}
+}
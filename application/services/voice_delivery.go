@@ -0,0 +1,51 @@
+package services
+
+import "generate-script-lambda/domain"
+
+const defaultSpeechSpeed = 1.0
+
+var moodVoiceAdjustments = map[domain.Mood]domain.VoiceSettings{
+	domain.CalmMood:   {Stability: 0.2, Style: -0.1, Speed: -0.1},
+	domain.TenseMood:  {Stability: -0.2, Style: 0.3, Speed: 0.05},
+	domain.JoyfulMood: {Stability: -0.1, Style: 0.2, Speed: 0.05},
+}
+
+// resolveVoiceSettings applies the requested settings onto the defaults and
+// shifts them towards the delivery of the segment's mood. Neutral segments
+// without requested settings get none, so providers fall back to their own
+// defaults.
+func resolveVoiceSettings(requested *domain.VoiceSettingsOverride, defaults domain.VoiceSettings, mood domain.Mood) *domain.VoiceSettings {
+	settings := defaults
+	if requested != nil {
+		settings = requested.ApplyTo(defaults)
+	}
+
+	adjustment, ok := moodVoiceAdjustments[mood]
+	if !ok {
+		if requested == nil {
+			return nil
+		}
+		return &settings
+	}
+
+	if settings.Speed == 0 {
+		settings.Speed = defaultSpeechSpeed
+	}
+
+	return &domain.VoiceSettings{
+		Stability:       clamp(settings.Stability+adjustment.Stability, 0, 1),
+		SimilarityBoost: clamp(settings.SimilarityBoost+adjustment.SimilarityBoost, 0, 1),
+		Style:           clamp(settings.Style+adjustment.Style, 0, 1),
+		Speed:           clamp(settings.Speed+adjustment.Speed, 0.7, 1.2),
+	}
+}
+
+func clamp(value float64, lower float64, upper float64) float64 {
+	if value < lower {
+		return lower
+	}
+	if value > upper {
+		return upper
+	}
+	return value
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"generate-script-lambda/domain"
+	"math"
+	"testing"
+)
+
+func TestResolveVoiceSettings(t *testing.T) {
+	defaults := domain.VoiceSettings{Stability: 0.5, SimilarityBoost: 0.75}
+	value := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name      string
+		requested *domain.VoiceSettingsOverride
+		mood      domain.Mood
+		expected  *domain.VoiceSettings
+	}{
+		{"neutral without request", nil, domain.NeutralMood, nil},
+		{"unknown mood without request", nil, "eerie", nil},
+		{"neutral request", &domain.VoiceSettingsOverride{Stability: value(0.3)}, domain.NeutralMood,
+			&domain.VoiceSettings{Stability: 0.3, SimilarityBoost: 0.75}},
+		{"calm defaults", nil, domain.CalmMood,
+			&domain.VoiceSettings{Stability: 0.7, SimilarityBoost: 0.75, Style: 0, Speed: 0.9}},
+		{"tense request clamped", &domain.VoiceSettingsOverride{Stability: value(0.95), Speed: value(1.2)}, domain.TenseMood,
+			&domain.VoiceSettings{Stability: 0.75, SimilarityBoost: 0.75, Style: 0.3, Speed: 1.2}},
+		{"joyful request clamped", &domain.VoiceSettingsOverride{Style: value(0.9), SimilarityBoost: value(1)}, domain.JoyfulMood,
+			&domain.VoiceSettings{Stability: 0.4, SimilarityBoost: 1, Style: 1, Speed: 1.05}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := resolveVoiceSettings(tt.requested, defaults, tt.mood)
+			if tt.expected == nil || settings == nil {
+				if tt.expected != settings {
+					t.Fatalf("expected %+v, got %+v", tt.expected, settings)
+				}
+				return
+			}
+			for _, pair := range [][2]float64{
+				{tt.expected.Stability, settings.Stability},
+				{tt.expected.SimilarityBoost, settings.SimilarityBoost},
+				{tt.expected.Style, settings.Style},
+				{tt.expected.Speed, settings.Speed},
+			} {
+				if math.Abs(pair[0]-pair[1]) > 1e-9 {
+					t.Fatalf("expected %+v, got %+v", *tt.expected, *settings)
+				}
+			}
+		})
+	}
+}
+
+func TestVoiceSettingsOverride_ApplyTo(t *testing.T) {
+	defaults := domain.VoiceSettings{Stability: 0.5, SimilarityBoost: 0.75, Style: 0.1, Speed: 1}
+	zero, speed := 0.0, 0.8
+
+	tests := []struct {
+		name     string
+		override domain.VoiceSettingsOverride
+		expected domain.VoiceSettings
+	}{
+		{"empty", domain.VoiceSettingsOverride{}, defaults},
+		{"explicit zero", domain.VoiceSettingsOverride{Stability: &zero, Style: &zero},
+			domain.VoiceSettings{Stability: 0, SimilarityBoost: 0.75, Style: 0, Speed: 1}},
+		{"speed only", domain.VoiceSettingsOverride{Speed: &speed},
+			domain.VoiceSettings{Stability: 0.5, SimilarityBoost: 0.75, Style: 0.1, Speed: 0.8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if settings := tt.override.ApplyTo(defaults); settings != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, settings)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package channel_utils
+
+import (
+	"context"
+	"generate-script-lambda/application/ports/outbound"
+)
+
+func TeeChannel[T any](ctx context.Context, workerPool outbound.TaskDispatcher, input <-chan T) (<-chan T, <-chan T, error) {
+	first := make(chan T)
+	second := make(chan T)
+
+	err := workerPool.Submit(func() {
+		defer close(first)
+		defer close(second)
+
+		for val := range input {
+			for _, out := range []chan T{first, second} {
+				select {
+				case <-ctx.Done():
+					return
+				case out <- val:
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return first, second, nil
+}
@@ -1,21 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"generate-script-lambda/application/ports/outbound"
 	"generate-script-lambda/application/services"
 	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
 	"generate-script-lambda/infrastructure/adapters"
 	"generate-script-lambda/infrastructure/gin_interface/controllers"
 	"generate-script-lambda/middleware"
 	mockgenerator "generate-script-lambda/mock"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/gin-gonic/gin"
 	"github.com/panjf2000/ants/v2"
 	"github.com/rs/zerolog/log"
 	"os"
 	"strconv"
+	"time"
 )
 
 func main() {
@@ -39,19 +42,57 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to get dalle config")
 	}
 
-	elevenLabsConfig, err := config.GetElevenLabsConfig()
+	ttsConfig, err := config.GetTtsConfig()
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to get eleven labs config")
+		log.Fatal().Err(err).Msg("Failed to get tts config")
 	}
 
-	s3Config, err := config.GetS3Config()
+	pipelineConfig, err := config.GetPipelineConfig()
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to get s3 config")
+		log.Fatal().Err(err).Msg("Failed to get pipeline config")
 	}
 
-	dynamoConfig, err := config.GetDynamoConfig()
+	storyAudioConfig, err := config.GetStoryAudioConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to get story audio config")
+	}
+	if err := storyAudioConfig.CheckOutputFormat(ttsConfig.OutputFormat); err != nil {
+		log.Fatal().Err(err).Msg("Invalid story audio config")
+	}
+
+	mediaStoreConfig, err := config.GetMediaStoreConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to get media store config")
+	}
+
+	segmentCacheConfig, err := config.GetSegmentCacheConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to get segment cache config")
+	}
+
+	voiceCatalogConfig, err := config.GetVoiceCatalogConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to get voice catalog config")
+	}
+
+	lexiconConfig, err := config.GetLexiconConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to get lexicon config")
+	}
+
+	storyRepositoryConfig, err := config.GetStoryRepositoryConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to get story repository config")
+	}
+
+	storyOutboxConfig, err := config.GetStoryOutboxConfig()
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to get dynamo config")
+		log.Fatal().Err(err).Msg("Failed to get story outbox config")
+	}
+
+	storybookConfig, err := config.GetStorybookConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to get storybook config")
 	}
 
 	authConfig, err := config.NewAuthorizerConfig()
@@ -59,9 +100,9 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to get authorizer config")
 	}
 
-	storyApiUrl := os.Getenv("STORY_API_URL")
-	if storyApiUrl == "" {
-		log.Fatal().Msg("STORY_API_URL environment variable not set")
+	storyApiConfig, err := config.GetStoryApiConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to get story api config")
 	}
 
 	jwksUrl := os.Getenv("JWKS_URL")
@@ -86,35 +127,182 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to create aws session")
 	}
 
-	s3Client := s3.New(sess)
 	dynamoClient := dynamodb.New(sess)
 
 	contentFetcher := adapters.NewContentFetcher(zeroLogger)
 
-	audioGenerator := adapters.NewAudioGenerator(contentFetcher, elevenLabsConfig, zeroLogger)
+	audioProviders := make(map[string]outbound.AudioGeneratorPort)
+	voiceCatalogs := make([]outbound.VoiceCatalogPort, 0)
+	defaultVoiceSettings := domain.VoiceSettings{
+		Stability:       ttsConfig.DefaultStability,
+		SimilarityBoost: ttsConfig.DefaultSimilarityBoost,
+	}
+
+	elevenLabsConfig, err := config.GetElevenLabsConfig()
+	if err != nil {
+		log.Warn().Err(err).Msg("Eleven labs tts provider is not configured")
+	} else {
+		if err := ttsConfig.CheckProvider(config.ElevenLabsTtsProvider); err != nil {
+			log.Fatal().Err(err).Msg("Invalid tts config")
+		}
+		audioProviders[config.ElevenLabsTtsProvider] = adapters.NewAudioGenerator(contentFetcher, elevenLabsConfig, zeroLogger)
+		voiceCatalogs = append(voiceCatalogs, adapters.NewElevenLabsVoiceCatalog(contentFetcher, elevenLabsConfig, zeroLogger))
+		defaultVoiceSettings.Stability = elevenLabsConfig.Stability
+		defaultVoiceSettings.SimilarityBoost = elevenLabsConfig.SimilarityBoost
+	}
+
+	openAiTtsConfig, err := config.GetOpenAiTtsConfig()
+	if err != nil {
+		log.Warn().Err(err).Msg("OpenAI tts provider is not configured")
+	} else {
+		if err := ttsConfig.CheckProvider(config.OpenAiTtsProvider); err != nil {
+			log.Fatal().Err(err).Msg("Invalid tts config")
+		}
+		audioProviders[config.OpenAiTtsProvider] = adapters.NewOpenAiAudioGenerator(contentFetcher, openAiTtsConfig, zeroLogger)
+	}
+
+	piperConfig, err := config.GetPiperConfig()
+	if err != nil {
+		log.Warn().Err(err).Msg("Piper tts provider is not configured")
+	} else {
+		audioProviders[config.PiperTtsProvider] = adapters.NewPiperAudioGenerator(contentFetcher, piperConfig, zeroLogger)
+	}
+
+	if voiceCatalogConfig.StaticFile != "" {
+		voiceCatalogs = append(voiceCatalogs, adapters.NewStaticVoiceCatalog(voiceCatalogConfig.StaticFile, zeroLogger))
+	}
+
+	audioGenerator, err := adapters.NewAudioGeneratorRouter(audioProviders, ttsConfig.DefaultProvider, zeroLogger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create audio generator")
+	}
 	imageGenerator := adapters.NewImageGenerator(contentFetcher, dalleConfig, zeroLogger)
 
 	authorizer := adapters.NewCognitoAuthorizer(zeroLogger, authConfig)
 
-	dynamoCache := adapters.NewDynamoCache(zeroLogger, dynamoClient, dynamoConfig)
+	segmentCache, err := newSegmentCache(dynamoClient, segmentCacheConfig, zeroLogger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create segment cache")
+	}
+
+	mediaStore, err := newSegmentMediaStore(sess, mediaStoreConfig, zeroLogger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create media store")
+	}
 
-	s3MediaStore := adapters.NewS3SegmentMediaStore(s3Client, s3Config, zeroLogger)
+	// Published storybooks are loaded by browsers that send no credentials,
+	// which only S3 media behind public or CDN URLs allows: local media is
+	// served behind authentication. Signed S3 URLs expire, so records that
+	// outlive them refer to media through the API instead.
+	stableMediaUrls := false
+	mediaUrlsExpire := false
+	if mediaStoreConfig.Backend == config.S3MediaStoreBackend {
+		mediaUrlConfig, err := config.GetMediaUrlConfig()
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to get media url config")
+		}
+		stableMediaUrls = mediaUrlConfig.StableUrls()
+		mediaUrlsExpire = !stableMediaUrls
+	}
 
-	storySaver := adapters.NewStorySaver(storyApiUrl, authorizer, zeroLogger)
+	lexiconRepository, err := newLexiconRepository(dynamoClient, lexiconConfig, zeroLogger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create lexicon repository")
+	}
+
+	storyRepository, err := newStoryRepository(dynamoClient, storyRepositoryConfig, zeroLogger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create story repository")
+	}
+
+	storySaver := adapters.NewStorySaver(storyApiConfig, authorizer, mediaUrlsExpire, zeroLogger)
+
+	storyOutbox, err := newStoryOutbox(dynamoClient, storyOutboxConfig, zeroLogger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create story outbox")
+	}
+
+	storyDelivery := services.NewStoryDelivery(zeroLogger, storyOutbox, storySaver, storyOutboxConfig.MaxAttempts,
+		storyOutboxConfig.BaseBackoff, storyOutboxConfig.MaxBackoff, storyOutboxConfig.PollInterval)
+
+	err = workerPool.Submit(func() {
+		storyDelivery.Run(context.Background())
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to start story delivery")
+	}
 
 	storyScriptGenerator := adapters.NewStoryScriptGenerator(scriptStreamerWordsPerStory, gptConfig, workerPool, zeroLogger)
 
-	segmentMediaEnhancer := services.NewSegmentMediaEnhancer(zeroLogger, imageGenerator, audioGenerator, workerPool)
+	segmentMediaEnhancer := services.NewSegmentMediaEnhancer(zeroLogger, imageGenerator, audioGenerator, mediaStore, workerPool,
+		outbound.AudioFormat(ttsConfig.OutputFormat), defaultVoiceSettings)
 
-	segmentMetadataSaver := services.NewSegmentMetadataSaver(zeroLogger, workerPool, dynamoCache)
+	segmentMetadataSaver := services.NewSegmentMetadataSaver(zeroLogger, workerPool, segmentCache, pipelineConfig.SaveConcurrency,
+		pipelineConfig.MetadataBatchSize, time.Duration(pipelineConfig.MetadataBatchWindowMs)*time.Millisecond)
 
-	segmentMediaSaver := services.NewSegmentMediaSaver(zeroLogger, s3MediaStore, workerPool)
+	segmentMediaSaver := services.NewSegmentMediaSaver(zeroLogger, mediaStore, workerPool, pipelineConfig.SaveConcurrency)
+
+	storyAudioAssembler := services.NewStoryAudioAssembler(zeroLogger, mediaStore, workerPool, storyAudioConfig.AssemblyEnabled,
+		time.Duration(storyAudioConfig.ParagraphSilenceMs)*time.Millisecond)
 
 	segmentTextGenerator := services.NewSegmentTextGenerator(zeroLogger, storyScriptGenerator, workerPool)
+	sceneDescriber := adapters.NewSceneDescriber(contentFetcher, gptConfig, zeroLogger)
+	manuscriptSegmenter := services.NewManuscriptSegmenter(zeroLogger, sceneDescriber, workerPool)
+
+	lexiconManager := services.NewLexiconManager(zeroLogger, lexiconRepository)
+
+	ttsProviders := make([]string, 0, len(audioProviders))
+	for provider := range audioProviders {
+		ttsProviders = append(ttsProviders, provider)
+	}
+	voiceCatalog := services.NewVoiceCatalogService(zeroLogger, voiceCatalogs, ttsProviders, ttsConfig.DefaultProvider,
+		time.Duration(voiceCatalogConfig.CacheMinutes)*time.Minute)
+
+	storyCreator := services.NewSegmentPipelineOrchestrator(zeroLogger, workerPool, segmentTextGenerator, manuscriptSegmenter, segmentMediaEnhancer, segmentMediaSaver, segmentMetadataSaver, storyAudioAssembler, lexiconManager)
+
+	storyHistory := services.NewStoryHistory(zeroLogger, storyRepository, ttsConfig.DefaultProvider, gptConfig.Model,
+		mediaUrlsExpire)
 
-	storyCreator := services.NewSegmentPipelineOrchestrator(zeroLogger, workerPool, segmentTextGenerator, segmentMediaEnhancer, segmentMediaSaver, segmentMetadataSaver)
+	storySegmentController := controllers.NewStorySegmentsController(zeroLogger, workerPool, storyCreator, storyDelivery, voiceCatalog, storyHistory,
+		lexiconManager)
 
-	storySegmentController := controllers.NewStorySegmentsController(zeroLogger, workerPool, storyCreator, storySaver)
+	storyHistoryController := controllers.NewStoryHistoryController(zeroLogger, storyHistory)
+
+	voiceController := controllers.NewVoiceController(zeroLogger, voiceCatalog)
+
+	lexiconController := controllers.NewLexiconController(zeroLogger, lexiconManager)
+
+	storyMediaController := controllers.NewStoryMediaController(zeroLogger, mediaStore)
+
+	segmentRegenerator := services.NewSegmentRegenerator(zeroLogger, workerPool, segmentCache, mediaStore, storyRepository,
+		lexiconManager, segmentMediaEnhancer, segmentMediaSaver)
+
+	segmentRegenerationController := controllers.NewSegmentRegenerationController(zeroLogger, segmentRegenerator, voiceCatalog)
+
+	segmentEditor := services.NewSegmentEditor(zeroLogger, segmentCache, segmentRegenerator, storyHistory)
+
+	segmentEditController := controllers.NewSegmentEditController(zeroLogger, segmentEditor)
+
+	storyRemixer := services.NewStoryRemixer(zeroLogger, workerPool, mediaStore, storyRepository, lexiconManager,
+		segmentMediaEnhancer, segmentMediaSaver, segmentMetadataSaver, storyAudioAssembler)
+
+	storyRemixController := controllers.NewStoryRemixController(zeroLogger, workerPool, storyRemixer, storyDelivery, voiceCatalog, storyHistory)
+
+	storyReader := services.NewStoryReader(zeroLogger, segmentCache)
+
+	storyReaderController := controllers.NewStoryReaderController(zeroLogger, storyReader)
+
+	storyExporter := services.NewStoryExporter(zeroLogger, storyRepository, mediaStore)
+
+	storyExportController := controllers.NewStoryExportController(zeroLogger, storyExporter)
+
+	storybookThemes := adapters.NewFilesystemStorybookThemes(storybookConfig.ThemesDir, zeroLogger)
+
+	storybookPublisher := services.NewStorybookPublisher(zeroLogger, storyRepository, mediaStore, storybookThemes, stableMediaUrls)
+
+	storybookController := controllers.NewStorybookController(zeroLogger, storybookPublisher)
+
+	storyOutboxController := controllers.NewStoryOutboxController(zeroLogger, storyDelivery)
 
 	router := gin.Default()
 
@@ -129,14 +317,116 @@ func main() {
 	}
 
 	router.Use(authHandler.AuthMiddleware())
-	router.Use(middleware.SSEMiddleware(workerPool))
 
-	mockgenerator.Init(router, workerPool, segmentMetadataSaver, storySaver, zeroLogger)
+	sseRouter := router.Group("", middleware.SSEMiddleware(workerPool))
 
-	storySegmentController.RegisterRoutes(router)
+	mockgenerator.Init(sseRouter, workerPool, segmentMetadataSaver, storySaver, zeroLogger)
+
+	storySegmentController.RegisterRoutes(sseRouter)
+
+	storyRemixController.RegisterRoutes(sseRouter)
+
+	lexiconController.RegisterRoutes(router)
+
+	voiceController.RegisterRoutes(router)
+
+	storyMediaController.RegisterRoutes(router)
+
+	storyReaderController.RegisterRoutes(router)
+
+	segmentRegenerationController.RegisterRoutes(router)
+
+	segmentEditController.RegisterRoutes(router)
+
+	storyHistoryController.RegisterRoutes(router)
+
+	storyExportController.RegisterRoutes(router)
+
+	storybookController.RegisterRoutes(router)
+
+	storyOutboxController.RegisterRoutes(router)
+
+	if mediaStoreConfig.Backend == config.FilesystemMediaStoreBackend {
+		controllers.NewLocalMediaController(zeroLogger, mediaStoreConfig.RootDir).RegisterRoutes(router)
+	}
 
 	err = router.Run(":8080")
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to start server!")
 	}
 }
+
+func newSegmentMediaStore(sess *session.Session, mediaStoreConfig *config.MediaStoreConfig, logger outbound.LoggerPort) (outbound.SegmentMediaStorePort, error) {
+	switch mediaStoreConfig.Backend {
+	case config.FilesystemMediaStoreBackend:
+		return adapters.NewFilesystemSegmentMediaStore(mediaStoreConfig, logger)
+	case config.MemoryMediaStoreBackend:
+		return adapters.NewMemorySegmentMediaStore(), nil
+	}
+
+	s3Config, err := config.GetS3Config()
+	if err != nil {
+		return nil, err
+	}
+
+	mediaUrlConfig, err := config.GetMediaUrlConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	s3Client := adapters.NewS3Client(sess, s3Config)
+
+	mediaUrlStrategy, err := adapters.NewMediaUrlStrategy(s3Client, s3Config, mediaUrlConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return adapters.NewS3SegmentMediaStore(s3Client, s3Config, mediaUrlStrategy, logger), nil
+}
+
+func newStoryRepository(dynamoClient *dynamodb.DynamoDB, repositoryConfig *config.StoryRepositoryConfig,
+	logger outbound.LoggerPort) (outbound.StoryRepositoryPort, error) {
+	if repositoryConfig.Backend == config.SqliteStoryRepositoryBackend {
+		return adapters.NewSqliteStoryRepository(repositoryConfig.SqlitePath, logger)
+	}
+
+	return adapters.NewDynamoStoryRepository(logger, dynamoClient, repositoryConfig), nil
+}
+
+func newLexiconRepository(dynamoClient *dynamodb.DynamoDB, lexiconConfig *config.LexiconConfig,
+	logger outbound.LoggerPort) (outbound.LexiconRepositoryPort, error) {
+	if lexiconConfig.Backend == config.SqliteLexiconRepositoryBackend {
+		return adapters.NewSqliteLexiconRepository(lexiconConfig.SqlitePath, logger)
+	}
+
+	return adapters.NewDynamoLexiconRepository(logger, dynamoClient, lexiconConfig), nil
+}
+
+func newStoryOutbox(dynamoClient *dynamodb.DynamoDB, outboxConfig *config.StoryOutboxConfig,
+	logger outbound.LoggerPort) (outbound.StoryOutboxPort, error) {
+	if outboxConfig.Backend == config.SqliteStoryRepositoryBackend {
+		return adapters.NewSqliteStoryOutbox(outboxConfig.SqlitePath, logger)
+	}
+
+	return adapters.NewDynamoStoryOutbox(logger, dynamoClient, outboxConfig), nil
+}
+
+func newSegmentCache(dynamoClient *dynamodb.DynamoDB, cacheConfig *config.SegmentCacheConfig, logger outbound.LoggerPort) (outbound.SegmentCachePort, error) {
+	switch cacheConfig.Backend {
+	case config.RedisSegmentCacheBackend:
+		redisClient, err := adapters.NewRedisClient(cacheConfig)
+		if err != nil {
+			return nil, err
+		}
+		return adapters.NewRedisSegmentCache(logger, redisClient, cacheConfig), nil
+	case config.MemorySegmentCacheBackend:
+		return adapters.NewMemorySegmentCache(time.Duration(cacheConfig.TtlMinutes) * time.Minute), nil
+	}
+
+	dynamoConfig, err := config.GetDynamoConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return adapters.NewDynamoCache(logger, dynamoClient, dynamoConfig), nil
+}
@@ -8,6 +8,7 @@ import (
 
 type ElevenLabsConfig struct {
 	ApiUrl          string
+	VoicesApiUrl    string
 	ApiKey          string
 	ModelId         string
 	Stability       float64
@@ -19,6 +20,10 @@ func GetElevenLabsConfig() (*ElevenLabsConfig, error) {
 	if apiUrl == "" {
 		return nil, fmt.Errorf("ELEVEN_LABS_API_URL must be set")
 	}
+	voicesApiUrl := os.Getenv("ELEVEN_LABS_VOICES_API_URL")
+	if voicesApiUrl == "" {
+		voicesApiUrl = "https://api.elevenlabs.io/v1/voices"
+	}
 	apiKey := os.Getenv("ELEVEN_LABS_API_KEY")
 	if apiKey == "" {
 		return nil, fmt.Errorf("ELEVEN_LABS_API_KEY must be set")
@@ -46,6 +51,7 @@ func GetElevenLabsConfig() (*ElevenLabsConfig, error) {
 
 	return &ElevenLabsConfig{
 		ApiUrl:          apiUrl,
+		VoicesApiUrl:    voicesApiUrl,
 		ApiKey:          apiKey,
 		ModelId:         modelId,
 		Stability:       stabilityVal,
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	DynamoLexiconRepositoryBackend = "dynamo"
+	SqliteLexiconRepositoryBackend = "sqlite"
+)
+
+type LexiconConfig struct {
+	Backend    string
+	TableName  string
+	SqlitePath string
+}
+
+// GetLexiconConfig keeps lexicons next to the story history: the backend
+// defaults to STORY_REPOSITORY_BACKEND.
+func GetLexiconConfig() (*LexiconConfig, error) {
+	backend := os.Getenv("LEXICON_REPOSITORY_BACKEND")
+	if backend == "" {
+		backend = os.Getenv("STORY_REPOSITORY_BACKEND")
+	}
+	if backend == "" {
+		backend = DynamoLexiconRepositoryBackend
+	}
+
+	lexiconConfig := &LexiconConfig{
+		Backend:    backend,
+		TableName:  os.Getenv("LEXICON_TABLE_NAME"),
+		SqlitePath: os.Getenv("LEXICON_SQLITE_PATH"),
+	}
+
+	switch backend {
+	case DynamoLexiconRepositoryBackend:
+		if lexiconConfig.TableName == "" {
+			return nil, fmt.Errorf("LEXICON_TABLE_NAME must be set")
+		}
+	case SqliteLexiconRepositoryBackend:
+		if lexiconConfig.SqlitePath == "" {
+			lexiconConfig.SqlitePath = "./lexicons.db"
+		}
+	default:
+		return nil, fmt.Errorf("LEXICON_REPOSITORY_BACKEND must be one of %s, %s", DynamoLexiconRepositoryBackend,
+			SqliteLexiconRepositoryBackend)
+	}
+
+	return lexiconConfig, nil
+}
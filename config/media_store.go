@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	S3MediaStoreBackend         = "s3"
+	FilesystemMediaStoreBackend = "filesystem"
+	MemoryMediaStoreBackend     = "memory"
+)
+
+type MediaStoreConfig struct {
+	Backend string
+	RootDir string
+	BaseUrl string
+}
+
+func GetMediaStoreConfig() (*MediaStoreConfig, error) {
+	backend := os.Getenv("MEDIA_STORE_BACKEND")
+	if backend == "" {
+		backend = S3MediaStoreBackend
+	}
+	switch backend {
+	case S3MediaStoreBackend, FilesystemMediaStoreBackend, MemoryMediaStoreBackend:
+	default:
+		return nil, fmt.Errorf("MEDIA_STORE_BACKEND must be one of %s, %s, %s", S3MediaStoreBackend,
+			FilesystemMediaStoreBackend, MemoryMediaStoreBackend)
+	}
+
+	rootDir := os.Getenv("MEDIA_STORE_ROOT")
+	if rootDir == "" {
+		rootDir = "./media"
+	}
+
+	baseUrl := os.Getenv("MEDIA_STORE_BASE_URL")
+	if baseUrl == "" {
+		baseUrl = "http://localhost:8080/media"
+	}
+
+	return &MediaStoreConfig{
+		Backend: backend,
+		RootDir: rootDir,
+		BaseUrl: baseUrl,
+	}, nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	PublicMediaUrlStrategy     = "public"
+	PresignedMediaUrlStrategy  = "presigned"
+	CloudFrontMediaUrlStrategy = "cloudfront"
+	CdnMediaUrlStrategy        = "cdn"
+)
+
+type MediaUrlConfig struct {
+	Strategy                 string
+	ExpiryMinutes            int
+	CdnBaseUrl               string
+	CloudFrontKeyPairId      string
+	CloudFrontPrivateKeyFile string
+}
+
+func GetMediaUrlConfig() (*MediaUrlConfig, error) {
+	strategy := os.Getenv("MEDIA_URL_STRATEGY")
+	if strategy == "" {
+		strategy = PublicMediaUrlStrategy
+	}
+
+	expiryMinutes := 60
+	if value := os.Getenv("MEDIA_URL_EXPIRY_MINUTES"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("MEDIA_URL_EXPIRY_MINUTES must be a positive number")
+		}
+		expiryMinutes = parsed
+	}
+
+	mediaUrlConfig := &MediaUrlConfig{
+		Strategy:                 strategy,
+		ExpiryMinutes:            expiryMinutes,
+		CdnBaseUrl:               os.Getenv("MEDIA_CDN_BASE_URL"),
+		CloudFrontKeyPairId:      os.Getenv("CLOUDFRONT_KEY_PAIR_ID"),
+		CloudFrontPrivateKeyFile: os.Getenv("CLOUDFRONT_PRIVATE_KEY_FILE"),
+	}
+
+	switch strategy {
+	case PublicMediaUrlStrategy, PresignedMediaUrlStrategy:
+	case CdnMediaUrlStrategy:
+		if mediaUrlConfig.CdnBaseUrl == "" {
+			return nil, fmt.Errorf("MEDIA_CDN_BASE_URL must be set for the cdn media url strategy")
+		}
+	case CloudFrontMediaUrlStrategy:
+		if mediaUrlConfig.CdnBaseUrl == "" || mediaUrlConfig.CloudFrontKeyPairId == "" || mediaUrlConfig.CloudFrontPrivateKeyFile == "" {
+			return nil, fmt.Errorf("MEDIA_CDN_BASE_URL, CLOUDFRONT_KEY_PAIR_ID and CLOUDFRONT_PRIVATE_KEY_FILE must be set for the cloudfront media url strategy")
+		}
+	default:
+		return nil, fmt.Errorf("MEDIA_URL_STRATEGY must be one of %s, %s, %s, %s", PublicMediaUrlStrategy,
+			PresignedMediaUrlStrategy, CloudFrontMediaUrlStrategy, CdnMediaUrlStrategy)
+	}
+
+	return mediaUrlConfig, nil
+}
+
+// StableUrls tells whether media URLs stay valid without signing, which
+// pages linking to media, like storybooks, depend on.
+func (c *MediaUrlConfig) StableUrls() bool {
+	return c.Strategy == PublicMediaUrlStrategy || c.Strategy == CdnMediaUrlStrategy
+}
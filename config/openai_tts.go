@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+type OpenAiTtsConfig struct {
+	ApiUrl string
+	ApiKey string
+	Model  string
+}
+
+func GetOpenAiTtsConfig() (*OpenAiTtsConfig, error) {
+	apiUrl := os.Getenv("OPENAI_TTS_API_URL")
+	if apiUrl == "" {
+		return nil, fmt.Errorf("OPENAI_TTS_API_URL must be set")
+	}
+	apiKey := os.Getenv("OPENAI_TTS_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("OPENAI_TTS_API_KEY must be set")
+	}
+	model := os.Getenv("OPENAI_TTS_MODEL")
+	if model == "" {
+		return nil, fmt.Errorf("OPENAI_TTS_MODEL must be set")
+	}
+
+	return &OpenAiTtsConfig{
+		ApiUrl: apiUrl,
+		ApiKey: apiKey,
+		Model:  model,
+	}, nil
+}
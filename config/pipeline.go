@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// MaxMetadataBatchSize is the BatchWriteItem limit.
+const MaxMetadataBatchSize = 25
+
+type PipelineConfig struct {
+	SaveConcurrency       int
+	MetadataBatchSize     int
+	MetadataBatchWindowMs int
+}
+
+func GetPipelineConfig() (*PipelineConfig, error) {
+	saveConcurrency, err := getPositiveInt("PIPELINE_SAVE_CONCURRENCY", 4)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize, err := getPositiveInt("PIPELINE_METADATA_BATCH_SIZE", MaxMetadataBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	if batchSize > MaxMetadataBatchSize {
+		return nil, fmt.Errorf("PIPELINE_METADATA_BATCH_SIZE must be at most %d", MaxMetadataBatchSize)
+	}
+
+	batchWindowMs, err := getPositiveInt("PIPELINE_METADATA_BATCH_WINDOW_MS", 50)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PipelineConfig{
+		SaveConcurrency:       saveConcurrency,
+		MetadataBatchSize:     batchSize,
+		MetadataBatchWindowMs: batchWindowMs,
+	}, nil
+}
+
+func getPositiveInt(name string, defaultValue int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number", name)
+	}
+
+	return parsed, nil
+}
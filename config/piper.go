@@ -0,0 +1,21 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+type PiperConfig struct {
+	ApiUrl string
+}
+
+func GetPiperConfig() (*PiperConfig, error) {
+	apiUrl := os.Getenv("PIPER_API_URL")
+	if apiUrl == "" {
+		return nil, fmt.Errorf("PIPER_API_URL must be set")
+	}
+
+	return &PiperConfig{
+		ApiUrl: apiUrl,
+	}, nil
+}
@@ -3,11 +3,16 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 )
 
 type S3Config struct {
-	BucketName string
-	Region     string
+	BucketName           string
+	Region               string
+	CacheControl         string
+	MultipartThresholdMB int
+	Endpoint             string
+	ForcePathStyle       bool
 }
 
 func GetS3Config() (*S3Config, error) {
@@ -21,8 +26,37 @@ func GetS3Config() (*S3Config, error) {
 		return nil, fmt.Errorf("REGION must be set")
 	}
 
+	// Media is replaced in place when a segment is regenerated or edited, so
+	// caches have to revalidate it rather than keep it for good.
+	cacheControl := os.Getenv("S3_CACHE_CONTROL")
+	if cacheControl == "" {
+		cacheControl = "no-cache"
+	}
+
+	multipartThresholdMB := 16
+	if threshold := os.Getenv("S3_MULTIPART_THRESHOLD_MB"); threshold != "" {
+		parsed, err := strconv.Atoi(threshold)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("S3_MULTIPART_THRESHOLD_MB must be a positive integer")
+		}
+		multipartThresholdMB = parsed
+	}
+
+	forcePathStyle := false
+	if value := os.Getenv("S3_FORCE_PATH_STYLE"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("S3_FORCE_PATH_STYLE must be a boolean")
+		}
+		forcePathStyle = parsed
+	}
+
 	return &S3Config{
-		BucketName: bucketName,
-		Region:     region,
+		BucketName:           bucketName,
+		Region:               region,
+		CacheControl:         cacheControl,
+		MultipartThresholdMB: multipartThresholdMB,
+		Endpoint:             os.Getenv("S3_ENDPOINT"),
+		ForcePathStyle:       forcePathStyle,
 	}, nil
 }
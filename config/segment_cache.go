@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	DynamoSegmentCacheBackend = "dynamo"
+	RedisSegmentCacheBackend  = "redis"
+	MemorySegmentCacheBackend = "memory"
+)
+
+type SegmentCacheConfig struct {
+	Backend    string
+	RedisUrl   string
+	KeyPrefix  string
+	TtlMinutes int
+}
+
+// GetSegmentCacheConfig covers the non-Dynamo backends; the Dynamo backend
+// keeps reading DynamoConfig. SEGMENT_CACHE_TTL_MINUTES falls back to
+// DYNAMO_TTL_MINUTES so every backend expires segments alike.
+func GetSegmentCacheConfig() (*SegmentCacheConfig, error) {
+	backend := os.Getenv("SEGMENT_CACHE_BACKEND")
+	if backend == "" {
+		backend = DynamoSegmentCacheBackend
+	}
+	switch backend {
+	case DynamoSegmentCacheBackend, RedisSegmentCacheBackend, MemorySegmentCacheBackend:
+	default:
+		return nil, fmt.Errorf("SEGMENT_CACHE_BACKEND must be one of %s, %s, %s", DynamoSegmentCacheBackend,
+			RedisSegmentCacheBackend, MemorySegmentCacheBackend)
+	}
+
+	cacheConfig := &SegmentCacheConfig{
+		Backend:   backend,
+		RedisUrl:  os.Getenv("REDIS_URL"),
+		KeyPrefix: os.Getenv("SEGMENT_CACHE_KEY_PREFIX"),
+	}
+	if backend == DynamoSegmentCacheBackend {
+		return cacheConfig, nil
+	}
+
+	if backend == RedisSegmentCacheBackend && cacheConfig.RedisUrl == "" {
+		return nil, fmt.Errorf("REDIS_URL must be set")
+	}
+	if cacheConfig.KeyPrefix == "" {
+		cacheConfig.KeyPrefix = "segments"
+	}
+
+	ttlMinutes := os.Getenv("SEGMENT_CACHE_TTL_MINUTES")
+	if ttlMinutes == "" {
+		ttlMinutes = os.Getenv("DYNAMO_TTL_MINUTES")
+	}
+	if ttlMinutes == "" {
+		return nil, fmt.Errorf("SEGMENT_CACHE_TTL_MINUTES must be set")
+	}
+	ttlNumber, err := strconv.Atoi(ttlMinutes)
+	if err != nil || ttlNumber <= 0 {
+		return nil, fmt.Errorf("SEGMENT_CACHE_TTL_MINUTES must be a positive number")
+	}
+	cacheConfig.TtlMinutes = ttlNumber
+
+	return cacheConfig, nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	// ManifestStoryPayloadFormat sends the full story manifest. It keeps the
+	// legacy fields at the top level, so older Story API versions still
+	// accept it.
+	ManifestStoryPayloadFormat = "manifest"
+	// LegacyStoryPayloadFormat sends only the story id, user id and input.
+	LegacyStoryPayloadFormat = "legacy"
+)
+
+type StoryApiConfig struct {
+	Url           string
+	PayloadFormat string
+}
+
+func GetStoryApiConfig() (*StoryApiConfig, error) {
+	url := os.Getenv("STORY_API_URL")
+	if url == "" {
+		return nil, fmt.Errorf("STORY_API_URL must be set")
+	}
+
+	payloadFormat := os.Getenv("STORY_API_PAYLOAD_FORMAT")
+	if payloadFormat == "" {
+		payloadFormat = ManifestStoryPayloadFormat
+	}
+	if payloadFormat != ManifestStoryPayloadFormat && payloadFormat != LegacyStoryPayloadFormat {
+		return nil, fmt.Errorf("STORY_API_PAYLOAD_FORMAT must be one of %s, %s", ManifestStoryPayloadFormat,
+			LegacyStoryPayloadFormat)
+	}
+
+	return &StoryApiConfig{
+		Url:           url,
+		PayloadFormat: payloadFormat,
+	}, nil
+}
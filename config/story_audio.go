@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+type StoryAudioConfig struct {
+	AssemblyEnabled    bool
+	ParagraphSilenceMs int
+}
+
+func GetStoryAudioConfig() (*StoryAudioConfig, error) {
+	assemblyEnabled := true
+	if value := os.Getenv("STORY_AUDIO_ASSEMBLY_ENABLED"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("STORY_AUDIO_ASSEMBLY_ENABLED must be a boolean")
+		}
+		assemblyEnabled = parsed
+	}
+
+	paragraphSilenceMs := 0
+	if paragraphSilence := os.Getenv("STORY_AUDIO_PARAGRAPH_SILENCE_MS"); paragraphSilence != "" {
+		parsed, err := strconv.Atoi(paragraphSilence)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("STORY_AUDIO_PARAGRAPH_SILENCE_MS must be a non-negative number")
+		}
+		paragraphSilenceMs = parsed
+	}
+
+	return &StoryAudioConfig{
+		AssemblyEnabled:    assemblyEnabled,
+		ParagraphSilenceMs: paragraphSilenceMs,
+	}, nil
+}
+
+// CheckOutputFormat reports whether segment audio in the given format can be
+// assembled. Only MP3 segments can be joined into the full story audio.
+func (c *StoryAudioConfig) CheckOutputFormat(outputFormat string) error {
+	if c.AssemblyEnabled && outputFormat != "mp3" {
+		return fmt.Errorf("TTS_OUTPUT_FORMAT must be mp3 while STORY_AUDIO_ASSEMBLY_ENABLED is set, got %s", outputFormat)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+type StoryOutboxConfig struct {
+	Backend      string
+	TableName    string
+	DueIndex     string
+	SqlitePath   string
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	PollInterval time.Duration
+}
+
+// GetStoryOutboxConfig keeps the outbox next to the story history: the
+// backend defaults to STORY_REPOSITORY_BACKEND.
+func GetStoryOutboxConfig() (*StoryOutboxConfig, error) {
+	backend := os.Getenv("STORY_OUTBOX_BACKEND")
+	if backend == "" {
+		backend = os.Getenv("STORY_REPOSITORY_BACKEND")
+	}
+	if backend == "" {
+		backend = DynamoStoryRepositoryBackend
+	}
+
+	outboxConfig := &StoryOutboxConfig{
+		Backend:    backend,
+		TableName:  os.Getenv("STORY_OUTBOX_TABLE_NAME"),
+		DueIndex:   os.Getenv("STORY_OUTBOX_DUE_INDEX"),
+		SqlitePath: os.Getenv("STORY_OUTBOX_SQLITE_PATH"),
+	}
+
+	switch backend {
+	case DynamoStoryRepositoryBackend:
+		if outboxConfig.TableName == "" {
+			return nil, fmt.Errorf("STORY_OUTBOX_TABLE_NAME must be set")
+		}
+		if outboxConfig.DueIndex == "" {
+			outboxConfig.DueIndex = "status-next_attempt_at-index"
+		}
+	case SqliteStoryRepositoryBackend:
+		if outboxConfig.SqlitePath == "" {
+			outboxConfig.SqlitePath = "./story_outbox.db"
+		}
+	default:
+		return nil, fmt.Errorf("STORY_OUTBOX_BACKEND must be one of %s, %s", DynamoStoryRepositoryBackend,
+			SqliteStoryRepositoryBackend)
+	}
+
+	maxAttempts, err := getPositiveInt("STORY_OUTBOX_MAX_ATTEMPTS", 10)
+	if err != nil {
+		return nil, err
+	}
+	baseBackoffSeconds, err := getPositiveInt("STORY_OUTBOX_BASE_BACKOFF_SECONDS", 5)
+	if err != nil {
+		return nil, err
+	}
+	maxBackoffSeconds, err := getPositiveInt("STORY_OUTBOX_MAX_BACKOFF_SECONDS", 900)
+	if err != nil {
+		return nil, err
+	}
+	if maxBackoffSeconds < baseBackoffSeconds {
+		return nil, fmt.Errorf("STORY_OUTBOX_MAX_BACKOFF_SECONDS must not be below STORY_OUTBOX_BASE_BACKOFF_SECONDS")
+	}
+	pollIntervalSeconds, err := getPositiveInt("STORY_OUTBOX_POLL_INTERVAL_SECONDS", 5)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxConfig.MaxAttempts = maxAttempts
+	outboxConfig.BaseBackoff = time.Duration(baseBackoffSeconds) * time.Second
+	outboxConfig.MaxBackoff = time.Duration(maxBackoffSeconds) * time.Second
+	outboxConfig.PollInterval = time.Duration(pollIntervalSeconds) * time.Second
+
+	return outboxConfig, nil
+}
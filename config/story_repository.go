@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	DynamoStoryRepositoryBackend = "dynamo"
+	SqliteStoryRepositoryBackend = "sqlite"
+)
+
+type StoryRepositoryConfig struct {
+	Backend        string
+	TableName      string
+	CreatedAtIndex string
+	UpdatedAtIndex string
+	SqlitePath     string
+}
+
+func GetStoryRepositoryConfig() (*StoryRepositoryConfig, error) {
+	backend := os.Getenv("STORY_REPOSITORY_BACKEND")
+	if backend == "" {
+		backend = DynamoStoryRepositoryBackend
+	}
+
+	repositoryConfig := &StoryRepositoryConfig{
+		Backend:        backend,
+		TableName:      os.Getenv("STORY_TABLE_NAME"),
+		CreatedAtIndex: os.Getenv("STORY_TABLE_CREATED_AT_INDEX"),
+		UpdatedAtIndex: os.Getenv("STORY_TABLE_UPDATED_AT_INDEX"),
+		SqlitePath:     os.Getenv("STORY_SQLITE_PATH"),
+	}
+
+	switch backend {
+	case DynamoStoryRepositoryBackend:
+		if repositoryConfig.TableName == "" {
+			return nil, fmt.Errorf("STORY_TABLE_NAME must be set")
+		}
+		if repositoryConfig.CreatedAtIndex == "" {
+			repositoryConfig.CreatedAtIndex = "user_id-created_at-index"
+		}
+		if repositoryConfig.UpdatedAtIndex == "" {
+			repositoryConfig.UpdatedAtIndex = "user_id-updated_at-index"
+		}
+	case SqliteStoryRepositoryBackend:
+		if repositoryConfig.SqlitePath == "" {
+			repositoryConfig.SqlitePath = "./stories.db"
+		}
+	default:
+		return nil, fmt.Errorf("STORY_REPOSITORY_BACKEND must be one of %s, %s", DynamoStoryRepositoryBackend,
+			SqliteStoryRepositoryBackend)
+	}
+
+	return repositoryConfig, nil
+}
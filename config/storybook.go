@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+type StorybookConfig struct {
+	ThemesDir string
+}
+
+func GetStorybookConfig() (*StorybookConfig, error) {
+	themesDir := os.Getenv("STORYBOOK_THEMES_DIR")
+	if themesDir != "" {
+		info, err := os.Stat(themesDir)
+		if err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("STORYBOOK_THEMES_DIR must be an existing directory")
+		}
+	}
+
+	return &StorybookConfig{
+		ThemesDir: themesDir,
+	}, nil
+}
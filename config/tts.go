@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	ElevenLabsTtsProvider = "elevenlabs"
+	OpenAiTtsProvider     = "openai"
+	PiperTtsProvider      = "piper"
+)
+
+// ttsOutputFormats lists the TTS_OUTPUT_FORMAT values each provider can
+// produce. Piper always produces WAV and ignores the setting.
+var ttsOutputFormats = map[string][]string{
+	ElevenLabsTtsProvider: {"mp3", "pcm"},
+	OpenAiTtsProvider:     {"mp3", "wav", "opus", "pcm"},
+}
+
+type TtsConfig struct {
+	DefaultProvider        string
+	OutputFormat           string
+	DefaultStability       float64
+	DefaultSimilarityBoost float64
+}
+
+func GetTtsConfig() (*TtsConfig, error) {
+	defaultProvider := os.Getenv("TTS_DEFAULT_PROVIDER")
+	if defaultProvider == "" {
+		defaultProvider = ElevenLabsTtsProvider
+	}
+	switch defaultProvider {
+	case ElevenLabsTtsProvider, OpenAiTtsProvider, PiperTtsProvider:
+	default:
+		return nil, fmt.Errorf("TTS_DEFAULT_PROVIDER must be one of %s, %s, %s", ElevenLabsTtsProvider, OpenAiTtsProvider, PiperTtsProvider)
+	}
+
+	outputFormat := os.Getenv("TTS_OUTPUT_FORMAT")
+	if outputFormat == "" {
+		outputFormat = "mp3"
+	}
+	if err := checkOutputFormat(defaultProvider, outputFormat); err != nil {
+		return nil, err
+	}
+
+	stabilityVal := 0.5
+	if stability := os.Getenv("TTS_DEFAULT_STABILITY"); stability != "" {
+		parsed, err := strconv.ParseFloat(stability, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse tts default stability")
+		}
+		stabilityVal = parsed
+	}
+
+	similarityBoostVal := 0.75
+	if similarityBoost := os.Getenv("TTS_DEFAULT_SIMILARITY_BOOST"); similarityBoost != "" {
+		parsed, err := strconv.ParseFloat(similarityBoost, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse tts default similarity boost")
+		}
+		similarityBoostVal = parsed
+	}
+
+	return &TtsConfig{
+		DefaultProvider:        defaultProvider,
+		OutputFormat:           outputFormat,
+		DefaultStability:       stabilityVal,
+		DefaultSimilarityBoost: similarityBoostVal,
+	}, nil
+}
+
+// CheckProvider reports whether a configured provider can produce the
+// configured output format.
+func (c *TtsConfig) CheckProvider(provider string) error {
+	return checkOutputFormat(provider, c.OutputFormat)
+}
+
+func checkOutputFormat(provider string, outputFormat string) error {
+	formats, ok := ttsOutputFormats[provider]
+	if !ok {
+		return nil
+	}
+	for _, format := range formats {
+		if format == outputFormat {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("TTS_OUTPUT_FORMAT %s is not supported by %s, must be one of %s", outputFormat, provider,
+		strings.Join(formats, ", "))
+}
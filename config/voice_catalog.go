@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+type VoiceCatalogConfig struct {
+	StaticFile   string
+	CacheMinutes int
+}
+
+func GetVoiceCatalogConfig() (*VoiceCatalogConfig, error) {
+	cacheMinutes := 60
+	if value := os.Getenv("VOICE_CATALOG_CACHE_MINUTES"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("VOICE_CATALOG_CACHE_MINUTES must be a number")
+		}
+		cacheMinutes = parsed
+	}
+
+	return &VoiceCatalogConfig{
+		StaticFile:   os.Getenv("VOICE_CATALOG_FILE"),
+		CacheMinutes: cacheMinutes,
+	}, nil
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	EstimatedCharactersPerSecond = 14.0
+	sentencePauseWeight          = 4.0
+	clausePauseWeight            = 2.0
+)
+
+type CharacterTiming struct {
+	Character string  `json:"character"`
+	Start     float64 `json:"start"`
+	End       float64 `json:"end"`
+}
+
+type WordTiming struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+type Alignment struct {
+	Characters []CharacterTiming `json:"characters,omitempty"`
+	Words      []WordTiming      `json:"words"`
+	Estimated  bool              `json:"estimated"`
+}
+
+func (a Alignment) Duration() float64 {
+	if len(a.Characters) > 0 {
+		return a.Characters[len(a.Characters)-1].End
+	}
+	if len(a.Words) > 0 {
+		return a.Words[len(a.Words)-1].End
+	}
+	return 0
+}
+
+func NewAlignmentFromCharacters(characters []CharacterTiming) Alignment {
+	return Alignment{
+		Characters: characters,
+		Words:      wordsFromCharacters(characters),
+	}
+}
+
+// EstimateAlignment spreads the given duration over the characters of text,
+// weighting punctuation as pauses. Used for providers that return no timing
+// data; a non-positive duration falls back to a fixed speaking rate.
+func EstimateAlignment(text string, durationSeconds float64) Alignment {
+	characters := make([]string, 0, utf8.RuneCountInString(text))
+	weights := make([]float64, 0, cap(characters))
+	totalWeight := 0.0
+	for _, r := range text {
+		weight := 1.0
+		switch {
+		case strings.ContainsRune(".!?", r):
+			weight = sentencePauseWeight
+		case strings.ContainsRune(",;:", r):
+			weight = clausePauseWeight
+		}
+		characters = append(characters, string(r))
+		weights = append(weights, weight)
+		totalWeight += weight
+	}
+
+	if durationSeconds <= 0 {
+		durationSeconds = float64(len(characters)) / EstimatedCharactersPerSecond
+	}
+
+	timings := make([]CharacterTiming, len(characters))
+	position := 0.0
+	for i, character := range characters {
+		start := position
+		position += durationSeconds * weights[i] / totalWeight
+		timings[i] = CharacterTiming{
+			Character: character,
+			Start:     start,
+			End:       position,
+		}
+	}
+
+	alignment := NewAlignmentFromCharacters(timings)
+	alignment.Estimated = true
+
+	return alignment
+}
+
+func wordsFromCharacters(characters []CharacterTiming) []WordTiming {
+	words := make([]WordTiming, 0)
+
+	var builder strings.Builder
+	var current WordTiming
+	for _, character := range characters {
+		r, _ := utf8.DecodeRuneInString(character.Character)
+		if unicode.IsSpace(r) {
+			if builder.Len() > 0 {
+				current.Word = builder.String()
+				words = append(words, current)
+				builder.Reset()
+			}
+			continue
+		}
+		if builder.Len() == 0 {
+			current.Start = character.Start
+		}
+		builder.WriteString(character.Character)
+		current.End = character.End
+	}
+	if builder.Len() > 0 {
+		current.Word = builder.String()
+		words = append(words, current)
+	}
+
+	return words
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "errors"
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrNotSupported marks a request this deployment is not configured for.
+	ErrNotSupported = errors.New("not supported")
+	// ErrConflict marks a write that lost a race with a concurrent update.
+	ErrConflict = errors.New("conflict")
+)
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+const (
+	IpaAlphabet        = "ipa"
+	CmuArpabetAlphabet = "cmu-arpabet"
+)
+
+type LexiconEntry struct {
+	Grapheme string `json:"grapheme" dynamodbav:"grapheme"`
+	Alias    string `json:"alias,omitempty" dynamodbav:"alias,omitempty"`
+	Phoneme  string `json:"phoneme,omitempty" dynamodbav:"phoneme,omitempty"`
+	Alphabet string `json:"alphabet,omitempty" dynamodbav:"alphabet,omitempty"`
+}
+
+type Lexicon struct {
+	ID        string         `json:"id"`
+	UserID    string         `json:"user_id"`
+	StoryID   string         `json:"story_id,omitempty"`
+	Name      string         `json:"name"`
+	Entries   []LexiconEntry `json:"entries"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
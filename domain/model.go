@@ -1,17 +1,34 @@
 package domain
 
+import "time"
+
 type SegmentType string
 
 const (
-	AudioSegmentType SegmentType = "audio"
-	ImageSegmentType SegmentType = "image"
+	AudioSegmentType      SegmentType = "audio"
+	ImageSegmentType      SegmentType = "image"
+	StoryAudioSegmentType SegmentType = "story_audio"
+	StorybookSegmentType  SegmentType = "storybook"
 )
 
 type SegmentWithMedia struct {
 	MediaContent []byte
+	MediaURL     string
+	MimeType     string
+	Provider     string
+	Alignment    *Alignment
 	Segment
 }
 
+type AudioChunk struct {
+	StoryID   string `json:"story_id"`
+	SegmentId string `json:"segment_id"`
+	Ordinal   int    `json:"ordinal"`
+	Sequence  int    `json:"sequence"`
+	Data      []byte `json:"data,omitempty"`
+	Final     bool   `json:"final"`
+}
+
 func NewSegment(text string, segmentType SegmentType, id string, storyID string, ordinal int) Segment {
 	return Segment{
 		Text:    text,
@@ -28,21 +45,77 @@ type Segment struct {
 	ID      string
 	StoryID string
 	Ordinal int
+	Mood    Mood
+	Version int
+}
+
+// CurrentVersion numbers a segment's media from 1. Segments stored before
+// they could be regenerated carry no version and are on their first.
+func (s Segment) CurrentVersion() int {
+	if s.Version == 0 {
+		return 1
+	}
+
+	return s.Version
 }
 
 type SegmentEvent struct {
-	StoryID   string      `json:"story_id"`
-	SegmentId string      `json:"segment_id"`
-	Text      string      `json:"text"`
-	Type      SegmentType `json:"type"`
-	Ordinal   int         `json:"ordinal"`
-	Url       string      `json:"url"`
+	StoryID   string         `json:"story_id"`
+	SegmentId string         `json:"segment_id"`
+	Text      string         `json:"text"`
+	Type      SegmentType    `json:"type"`
+	Ordinal   int            `json:"ordinal"`
+	Version   int            `json:"version"`
+	Mood      Mood           `json:"mood,omitempty"`
+	Url       string         `json:"url"`
+	VttUrl    string         `json:"vtt_url,omitempty"`
+	SrtUrl    string         `json:"srt_url,omitempty"`
+	Words     []WordTiming   `json:"words,omitempty"`
+	Media     *MediaMetadata `json:"media,omitempty"`
+}
+
+type MediaMetadata struct {
+	MimeType   string  `json:"mime_type"`
+	Size       int     `json:"size"`
+	Checksum   string  `json:"sha256"`
+	Width      int     `json:"width,omitempty"`
+	Height     int     `json:"height,omitempty"`
+	Duration   float64 `json:"duration,omitempty"`
+	Bitrate    int     `json:"bitrate,omitempty"`
+	SampleRate int     `json:"sample_rate,omitempty"`
 }
 
 type EndGenerationEvent struct {
 	MessageEvent
 }
 
+type SubtitleURLs struct {
+	VttURL string
+	SrtURL string
+}
+
+type StoryAssets struct {
+	StoryID      string
+	FullAudioURL string
+	Subtitles    SubtitleURLs
+}
+
+type GenerationCompleteEvent struct {
+	StoryID      string `json:"story_id"`
+	FullAudioUrl string `json:"full_audio_url,omitempty"`
+	VttUrl       string `json:"vtt_url,omitempty"`
+	SrtUrl       string `json:"srt_url,omitempty"`
+}
+
+func (s StoryAssets) ToEvent() GenerationCompleteEvent {
+	return GenerationCompleteEvent{
+		StoryID:      s.StoryID,
+		FullAudioUrl: s.FullAudioURL,
+		VttUrl:       s.Subtitles.VttURL,
+		SrtUrl:       s.Subtitles.SrtURL,
+	}
+}
+
 type ErrorEvent struct {
 	MessageEvent
 }
@@ -53,17 +126,46 @@ type MessageEvent struct {
 }
 
 type SegmentWithMediaUrl struct {
-	MediaURL string
+	MediaURL  string
+	Metadata  *MediaMetadata
+	Alignment *Alignment
+	Subtitles SubtitleURLs
 	Segment
 }
 
 func (s SegmentWithMediaUrl) ToEvent() SegmentEvent {
-	return SegmentEvent{
+	event := SegmentEvent{
 		StoryID:   s.StoryID,
 		SegmentId: s.ID,
 		Text:      s.Text,
 		Type:      s.Type,
 		Ordinal:   s.Ordinal,
+		Version:   s.CurrentVersion(),
+		Mood:      s.Mood,
 		Url:       s.MediaURL,
+		VttUrl:    s.Subtitles.VttURL,
+		SrtUrl:    s.Subtitles.SrtURL,
+		Media:     s.Metadata,
+	}
+	if s.Alignment != nil {
+		event.Words = s.Alignment.Words
 	}
+
+	return event
+}
+
+type SegmentPage struct {
+	Segments   []SegmentEvent `json:"segments"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+type MediaLinks struct {
+	StoryID   string      `json:"story_id"`
+	SegmentID string      `json:"segment_id"`
+	Type      SegmentType `json:"type"`
+	Version   int         `json:"version,omitempty"`
+	URL       string      `json:"url"`
+	VttURL    string      `json:"vtt_url,omitempty"`
+	SrtURL    string      `json:"srt_url,omitempty"`
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"`
 }
@@ -0,0 +1,25 @@
+package domain
+
+import "strings"
+
+type Mood string
+
+const (
+	NeutralMood Mood = ""
+	CalmMood    Mood = "calm"
+	TenseMood   Mood = "tense"
+	JoyfulMood  Mood = "joyful"
+)
+
+var Moods = []Mood{CalmMood, TenseMood, JoyfulMood}
+
+func ParseMood(value string) Mood {
+	mood := Mood(strings.ToLower(strings.TrimSpace(value)))
+	for _, known := range Moods {
+		if mood == known {
+			return mood
+		}
+	}
+
+	return NeutralMood
+}
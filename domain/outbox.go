@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// OutboxDepth counts the story save requests the Story API has not
+// acknowledged yet. Dead-lettered requests ran out of attempts and wait for
+// an operator.
+type OutboxDepth struct {
+	Pending         int        `json:"pending"`
+	DeadLettered    int        `json:"dead_lettered"`
+	OldestPendingAt *time.Time `json:"oldest_pending_at,omitempty"`
+	MeasuredAt      *time.Time `json:"measured_at,omitempty"`
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type SpeechTokenType string
+
+const (
+	TextSpeechToken     SpeechTokenType = "text"
+	BreakSpeechToken    SpeechTokenType = "break"
+	EmphasisSpeechToken SpeechTokenType = "emphasis"
+	PhonemeSpeechToken  SpeechTokenType = "phoneme"
+)
+
+type SpeechToken struct {
+	Type     SpeechTokenType
+	Text     string
+	Alias    string
+	Phoneme  string
+	Alphabet string
+	Pause    time.Duration
+}
+
+// Speech is narration text with provider-neutral markup. Adapters render it
+// into whatever markup their TTS provider understands.
+type Speech []SpeechToken
+
+func (s Speech) PlainText() string {
+	var builder strings.Builder
+	for _, token := range s {
+		switch {
+		case token.Type == BreakSpeechToken:
+		case token.Alias != "":
+			builder.WriteString(token.Alias)
+		default:
+			builder.WriteString(token.Text)
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,297 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var imagePlaceholderRegexp = regexp.MustCompile(`\{([^}]*)}`)
+
+type StoryStatus string
+
+const (
+	GeneratingStoryStatus StoryStatus = "generating"
+	CompletedStoryStatus  StoryStatus = "completed"
+	FailedStoryStatus     StoryStatus = "failed"
+)
+
+// StorySource tells whether the story was written by the LLM from a prompt
+// or narrated from a manuscript the user brought.
+type StorySource string
+
+const (
+	PromptStorySource     StorySource = "prompt"
+	ManuscriptStorySource StorySource = "manuscript"
+)
+
+const (
+	DefaultStoryLanguage = "en"
+	maxStoryTitleLength  = 60
+)
+
+type StorySortField string
+
+const (
+	CreatedAtStorySortField StorySortField = "created_at"
+	UpdatedAtStorySortField StorySortField = "updated_at"
+)
+
+// Story is the durable record of a generation run, kept after the cached
+// segments expire.
+type Story struct {
+	ID                 string          `json:"id"`
+	UserID             string          `json:"user_id"`
+	Status             StoryStatus     `json:"status"`
+	Parameters         StoryParameters `json:"parameters"`
+	RemixOf            string          `json:"remix_of,omitempty"`
+	Provider           string          `json:"provider,omitempty"`
+	Model              string          `json:"model,omitempty"`
+	FullAudioURL       string          `json:"full_audio_url,omitempty"`
+	FullAudioMediaPath string          `json:"full_audio_media_path,omitempty"`
+	Segments           []StorySegment  `json:"segments"`
+	Edits              []SegmentEdit   `json:"edits,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+// Title names the story after the first line of its input, which is the
+// heading of a manuscript or the prompt the story was written from.
+func (s Story) Title() string {
+	for _, line := range strings.Split(s.Parameters.Input, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "#> \t"))
+		// Scene markers and HTML comments are not part of the text.
+		if line == "" || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "<") {
+			continue
+		}
+		if runes := []rune(line); len(runes) > maxStoryTitleLength {
+			line = strings.TrimSpace(string(runes[:maxStoryTitleLength])) + "…"
+		}
+		return line
+	}
+
+	return "Story " + s.ID
+}
+
+func (s Story) Language() string {
+	if s.Parameters.Language == "" {
+		return DefaultStoryLanguage
+	}
+
+	return s.Parameters.Language
+}
+
+type StoryParameters struct {
+	Input         string                 `json:"input"`
+	VoiceID       string                 `json:"voice_id"`
+	VoiceSettings *VoiceSettingsOverride `json:"voice_settings,omitempty"`
+	Language      string                 `json:"language,omitempty"`
+	ImageStyle    string                 `json:"image_style,omitempty"`
+	Source        StorySource            `json:"source,omitempty"`
+	LexiconID     string                 `json:"lexicon_id,omitempty"`
+}
+
+// StorySegment keeps what the story can be replayed from: the segment text,
+// its reading order and the narration timings. The URL is kept only while it
+// cannot expire; MediaPath always resolves the segment's media to fresh URLs.
+type StorySegment struct {
+	SegmentID string         `json:"segment_id"`
+	Type      SegmentType    `json:"type"`
+	Text      string         `json:"text"`
+	Ordinal   int            `json:"ordinal"`
+	Version   int            `json:"version"`
+	Mood      Mood           `json:"mood,omitempty"`
+	Words     []WordTiming   `json:"words,omitempty"`
+	Media     *MediaMetadata `json:"media,omitempty"`
+	URL       string         `json:"url,omitempty"`
+	MediaPath string         `json:"media_path"`
+}
+
+// ToSegment rebuilds the pipeline segment the story was completed with.
+func (s StorySegment) ToSegment(storyID string) SegmentWithMediaUrl {
+	segment := SegmentWithMediaUrl{
+		MediaURL: s.URL,
+		Metadata: s.Media,
+		Segment: Segment{
+			Text:    s.Text,
+			Type:    s.Type,
+			ID:      s.SegmentID,
+			StoryID: storyID,
+			Ordinal: s.Ordinal,
+			Mood:    s.Mood,
+			Version: s.Version,
+		},
+	}
+	if len(s.Words) > 0 {
+		segment.Alignment = &Alignment{Words: s.Words}
+	}
+
+	return segment
+}
+
+// SegmentMediaPath is the API path that resolves a segment's media, for
+// records that outlive signed media URLs. The full story audio is stored
+// under the story's own id.
+func SegmentMediaPath(storyID string, segmentID string) string {
+	return fmt.Sprintf("/stories/%s/media/%s", storyID, segmentID)
+}
+
+// SegmentEdit records a correction to a segment's text and the media version
+// it produced.
+type SegmentEdit struct {
+	SegmentID    string    `json:"segment_id"`
+	EditorID     string    `json:"editor_id"`
+	PreviousText string    `json:"previous_text"`
+	Text         string    `json:"text"`
+	Version      int       `json:"version"`
+	EditedAt     time.Time `json:"edited_at"`
+}
+
+// EditedSegment is a segment with corrected text. Its new media is in place
+// even when HistoryRecorded reports that the edit is missing from the story
+// record.
+type EditedSegment struct {
+	SegmentEvent
+	HistoryRecorded bool `json:"history_recorded"`
+}
+
+type StoryPage struct {
+	Stories    []Story `json:"stories"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+type MediaObject struct {
+	StoryID   string
+	SegmentID string
+	Type      SegmentType
+	Extension string
+	// Version is zero for the segment's current media and the archived
+	// version number otherwise.
+	Version int
+}
+
+type StoryManifest struct {
+	StoryID    string            `json:"story_id"`
+	Title      string            `json:"title"`
+	Language   string            `json:"language"`
+	StoryAudio *ManifestSegment  `json:"story_audio,omitempty"`
+	Segments   []ManifestSegment `json:"segments"`
+}
+
+type ManifestSegment struct {
+	SegmentID string         `json:"segment_id"`
+	Type      SegmentType    `json:"type"`
+	Ordinal   int            `json:"ordinal"`
+	Text      string         `json:"text,omitempty"`
+	Words     []WordTiming   `json:"words,omitempty"`
+	Media     *MediaMetadata `json:"media,omitempty"`
+	File      string         `json:"file,omitempty"`
+	VttFile   string         `json:"vtt_file,omitempty"`
+	SrtFile   string         `json:"srt_file,omitempty"`
+}
+
+// Storybook is the data handed to storybook templates, including the ones
+// tenants supply, so its fields should only ever be added to.
+type Storybook struct {
+	StoryID  string
+	Title    string
+	Language string
+	Pages    []StorybookPage
+}
+
+type StorybookPage struct {
+	Number     int
+	Image      string
+	ImageAlt   string
+	Paragraphs []StorybookParagraph
+}
+
+type StorybookParagraph struct {
+	Text  string
+	Audio string
+}
+
+type PublishedStorybook struct {
+	StoryID   string `json:"story_id"`
+	URL       string `json:"url"`
+	EmbedHTML string `json:"embed_html"`
+}
+
+// StripImagePlaceholders removes the {imageID} markers the text generator
+// leaves where an illustration belongs.
+func StripImagePlaceholders(text string) string {
+	return strings.TrimSpace(imagePlaceholderRegexp.ReplaceAllString(text, ""))
+}
+
+// ImagePlaceholders returns the {imageID} markers in text, braces included.
+func ImagePlaceholders(text string) []string {
+	return imagePlaceholderRegexp.FindAllString(text, -1)
+}
+
+// SortInReadingOrder interleaves images with the narration. Audio and image
+// ordinals are counted separately, so an image is placed right before the
+// audio segment whose text holds its placeholder; images nobody references
+// go last.
+func SortInReadingOrder(segments []SegmentWithMediaUrl) []SegmentWithMediaUrl {
+	audio := make([]SegmentWithMediaUrl, 0, len(segments))
+	images := make(map[string]SegmentWithMediaUrl)
+	unplaced := make([]SegmentWithMediaUrl, 0)
+	for _, segment := range segments {
+		switch segment.Type {
+		case AudioSegmentType:
+			audio = append(audio, segment)
+		case ImageSegmentType:
+			images[segment.ID] = segment
+		default:
+			unplaced = append(unplaced, segment)
+		}
+	}
+	sort.SliceStable(audio, func(i, j int) bool {
+		return audio[i].Ordinal < audio[j].Ordinal
+	})
+
+	ordered := make([]SegmentWithMediaUrl, 0, len(segments))
+	for _, segment := range audio {
+		for _, match := range imagePlaceholderRegexp.FindAllStringSubmatch(segment.Text, -1) {
+			if image, ok := images[match[1]]; ok {
+				ordered = append(ordered, image)
+				delete(images, match[1])
+			}
+		}
+		ordered = append(ordered, segment)
+	}
+
+	remaining := make([]SegmentWithMediaUrl, 0, len(images))
+	for _, image := range images {
+		remaining = append(remaining, image)
+	}
+	sort.Slice(remaining, func(i, j int) bool {
+		return remaining[i].Ordinal < remaining[j].Ordinal
+	})
+
+	return append(append(ordered, remaining...), unplaced...)
+}
+
+// SortEventsInReadingOrder orders segment events like SortInReadingOrder.
+func SortEventsInReadingOrder(events []SegmentEvent) []SegmentEvent {
+	segments := make([]SegmentWithMediaUrl, 0, len(events))
+	byID := make(map[string]SegmentEvent, len(events))
+	for _, event := range events {
+		segments = append(segments, SegmentWithMediaUrl{Segment: Segment{
+			ID:      event.SegmentId,
+			Type:    event.Type,
+			Text:    event.Text,
+			Ordinal: event.Ordinal,
+		}})
+		byID[event.SegmentId] = event
+	}
+
+	ordered := make([]SegmentEvent, 0, len(events))
+	for _, segment := range SortInReadingOrder(segments) {
+		ordered = append(ordered, byID[segment.ID])
+	}
+
+	return ordered
+}
@@ -0,0 +1,64 @@
+package domain
+
+import "strings"
+
+const voiceProviderSeparator = ":"
+
+type Voice struct {
+	ID         string `json:"voice_id"`
+	Provider   string `json:"provider"`
+	Name       string `json:"name"`
+	Language   string `json:"language,omitempty"`
+	Gender     string `json:"gender,omitempty"`
+	Age        string `json:"age,omitempty"`
+	PreviewURL string `json:"preview_url,omitempty"`
+}
+
+// SplitVoiceID separates an optional "provider:" prefix from a voice id,
+// falling back to defaultProvider when the id carries no prefix.
+func SplitVoiceID(voiceID string, defaultProvider string) (provider string, id string) {
+	prefix, rest, found := strings.Cut(voiceID, voiceProviderSeparator)
+	if !found {
+		return defaultProvider, voiceID
+	}
+
+	return prefix, rest
+}
+
+func JoinVoiceID(provider string, id string) string {
+	return provider + voiceProviderSeparator + id
+}
+
+type VoiceSettings struct {
+	Stability       float64 `json:"stability"`
+	SimilarityBoost float64 `json:"similarity_boost"`
+	Style           float64 `json:"style"`
+	Speed           float64 `json:"speed,omitempty"`
+}
+
+// VoiceSettingsOverride holds the settings a caller asked for; unset fields
+// keep the configured defaults.
+type VoiceSettingsOverride struct {
+	Stability       *float64 `json:"stability,omitempty"`
+	SimilarityBoost *float64 `json:"similarity_boost,omitempty"`
+	Style           *float64 `json:"style,omitempty"`
+	Speed           *float64 `json:"speed,omitempty"`
+}
+
+func (o VoiceSettingsOverride) ApplyTo(defaults VoiceSettings) VoiceSettings {
+	settings := defaults
+	if o.Stability != nil {
+		settings.Stability = *o.Stability
+	}
+	if o.SimilarityBoost != nil {
+		settings.SimilarityBoost = *o.SimilarityBoost
+	}
+	if o.Style != nil {
+		settings.Style = *o.Style
+	}
+	if o.Speed != nil {
+		settings.Speed = *o.Speed
+	}
+
+	return settings
+}
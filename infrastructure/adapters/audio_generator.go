@@ -3,21 +3,49 @@ package adapters
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"generate-script-lambda/application/ports/outbound"
 	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
 	"net/http"
+	"net/url"
 )
 
 type ElevenLabsRequest struct {
 	Text          string        `json:"text"`
 	ModelId       string        `json:"model_id"`
+	LanguageCode  string        `json:"language_code,omitempty"`
 	VoiceSettings VoiceSettings `json:"voice_settings"`
 }
 
+type ElevenLabsTimestampsResponse struct {
+	AudioBase64 string              `json:"audio_base64"`
+	Alignment   ElevenLabsAlignment `json:"alignment"`
+}
+
+type ElevenLabsAlignment struct {
+	Characters                 []string  `json:"characters"`
+	CharacterStartTimesSeconds []float64 `json:"character_start_times_seconds"`
+	CharacterEndTimesSeconds   []float64 `json:"character_end_times_seconds"`
+}
+
 type VoiceSettings struct {
 	Stability       float64 `json:"stability"`
 	SimilarityBoost float64 `json:"similarity_boost"`
+	Style           float64 `json:"style,omitempty"`
+	Speed           float64 `json:"speed,omitempty"`
+}
+
+var elevenLabsOutputFormats = map[outbound.AudioFormat]string{
+	outbound.AudioFormatMP3: "mp3_44100_128",
+	outbound.AudioFormatPCM: "pcm_44100",
+}
+
+var elevenLabsAcceptHeaders = map[outbound.AudioFormat]string{
+	outbound.AudioFormatMP3: "audio/mpeg",
+	outbound.AudioFormatPCM: "audio/pcm",
 }
 
 type audioGenerator struct {
@@ -34,8 +62,13 @@ func NewAudioGenerator(contentFetcher ContentFetcher, elevenLabsConfig *config.E
 	}
 }
 
-func (a *audioGenerator) Generate(ctx context.Context, generateAudioParams outbound.GenerateAudioParams) ([]byte, error) {
-	req, err := a.getRequest(ctx, generateAudioParams.Text, generateAudioParams.VoiceID)
+func (a *audioGenerator) Generate(ctx context.Context, generateAudioParams outbound.GenerateAudioParams) (outbound.GeneratedAudio, error) {
+	format, err := a.getOutputFormat(generateAudioParams)
+	if err != nil {
+		return outbound.GeneratedAudio{}, err
+	}
+
+	req, err := a.getRequest(ctx, generateAudioParams, format, "/with-timestamps", "application/json")
 	if err != nil {
 		a.logger.ErrorWithFields(err,
 			"Failed to create the HTTP request",
@@ -43,20 +76,94 @@ func (a *audioGenerator) Generate(ctx context.Context, generateAudioParams outbo
 				"action": "Creating HTTP Request",
 				"URL":    a.elevenLabsConfig.ApiUrl + "/" + generateAudioParams.VoiceID,
 			})
-		return nil, err
+		return outbound.GeneratedAudio{}, err
+
+	}
+
+	rawRes, err := a.FetchContent(req)
+	if err != nil {
+		return outbound.GeneratedAudio{}, err
+	}
+
+	var timestampsRes ElevenLabsTimestampsResponse
+	err = json.Unmarshal(rawRes, &timestampsRes)
+	if err != nil {
+		a.logger.Error(err, "Failed to unmarshal the response")
+		return outbound.GeneratedAudio{}, err
+	}
+
+	content, err := base64.StdEncoding.DecodeString(timestampsRes.AudioBase64)
+	if err != nil {
+		a.logger.Error(err, "Failed to decode the audio")
+		return outbound.GeneratedAudio{}, err
+	}
+
+	alignment := a.toAlignment(timestampsRes.Alignment)
+
+	return outbound.GeneratedAudio{
+		Content:   content,
+		Format:    format,
+		Alignment: &alignment,
+	}, nil
+}
+
+func (a *audioGenerator) GenerateStream(ctx context.Context, generateAudioParams outbound.GenerateAudioParams) (outbound.AudioStream, error) {
+	format, err := a.getOutputFormat(generateAudioParams)
+	if err != nil {
+		return outbound.AudioStream{}, err
+	}
+
+	req, err := a.getRequest(ctx, generateAudioParams, format, "/stream", elevenLabsAcceptHeaders[format])
+	if err != nil {
+		return outbound.AudioStream{}, err
+	}
+
+	content, err := a.StreamContent(req)
+	if err != nil {
+		return outbound.AudioStream{}, err
+	}
+
+	return outbound.AudioStream{
+		Content: content,
+		Format:  format,
+	}, nil
+}
 
+func (a *audioGenerator) getOutputFormat(generateAudioParams outbound.GenerateAudioParams) (outbound.AudioFormat, error) {
+	format := generateAudioParams.OutputFormat
+	if format == "" {
+		format = outbound.AudioFormatMP3
+	}
+	if _, ok := elevenLabsOutputFormats[format]; !ok {
+		return "", fmt.Errorf("eleven labs does not support output format %s", format)
 	}
-	return a.FetchContent(req)
+
+	return format, nil
 }
 
-func (a *audioGenerator) getRequest(ctx context.Context, text string, voiceID string) (*http.Request, error) {
+func (a *audioGenerator) toAlignment(res ElevenLabsAlignment) domain.Alignment {
+	characters := make([]domain.CharacterTiming, 0, len(res.Characters))
+	for i, character := range res.Characters {
+		if i >= len(res.CharacterStartTimesSeconds) || i >= len(res.CharacterEndTimesSeconds) {
+			break
+		}
+		characters = append(characters, domain.CharacterTiming{
+			Character: character,
+			Start:     res.CharacterStartTimesSeconds[i],
+			End:       res.CharacterEndTimesSeconds[i],
+		})
+	}
+
+	return domain.NewAlignmentFromCharacters(characters)
+}
+
+func (a *audioGenerator) getRequest(ctx context.Context, params outbound.GenerateAudioParams, format outbound.AudioFormat,
+	endpoint string, accept string) (*http.Request, error) {
 	reqBody := ElevenLabsRequest{
-		Text:    text,
-		ModelId: a.elevenLabsConfig.ModelId,
-		VoiceSettings: VoiceSettings{
-			Stability:       a.elevenLabsConfig.Stability,
-			SimilarityBoost: a.elevenLabsConfig.SimilarityBoost,
-		},
+		Text:          renderElevenLabsText(params),
+		ModelId:       a.elevenLabsConfig.ModelId,
+		LanguageCode:  params.Language,
+		VoiceSettings: a.getVoiceSettings(params.VoiceSettings),
 	}
 
 	jsonPayload, err := json.Marshal(reqBody)
@@ -65,17 +172,19 @@ func (a *audioGenerator) getRequest(ctx context.Context, text string, voiceID st
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", a.elevenLabsConfig.ApiUrl+"/"+voiceID, bytes.NewBuffer(jsonPayload))
+	reqUrl := a.elevenLabsConfig.ApiUrl + "/" + params.VoiceID + endpoint + "?output_format=" + url.QueryEscape(elevenLabsOutputFormats[format])
+
+	req, err := http.NewRequestWithContext(ctx, "POST", reqUrl, bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		a.logger.ErrorWithFields(err, "Failed to create the HTTP request", map[string]interface{}{
 			"action": "Creating HTTP Request",
-			"URL":    a.elevenLabsConfig.ApiUrl + "/" + voiceID,
+			"URL":    reqUrl,
 		})
 		return nil, err
 	}
 
 	reqHeaders := map[string]string{
-		"Accept":       "audio/mpeg",
+		"Accept":       accept,
 		"xi-api-key":   a.elevenLabsConfig.ApiKey,
 		"Content-Type": "application/json",
 	}
@@ -85,3 +194,19 @@ func (a *audioGenerator) getRequest(ctx context.Context, text string, voiceID st
 
 	return req, nil
 }
+
+func (a *audioGenerator) getVoiceSettings(settings *domain.VoiceSettings) VoiceSettings {
+	if settings == nil {
+		return VoiceSettings{
+			Stability:       a.elevenLabsConfig.Stability,
+			SimilarityBoost: a.elevenLabsConfig.SimilarityBoost,
+		}
+	}
+
+	return VoiceSettings{
+		Stability:       settings.Stability,
+		SimilarityBoost: settings.SimilarityBoost,
+		Style:           settings.Style,
+		Speed:           settings.Speed,
+	}
+}
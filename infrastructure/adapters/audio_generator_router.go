@@ -0,0 +1,67 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+)
+
+type audioGeneratorRouter struct {
+	logger          outbound.LoggerPort
+	providers       map[string]outbound.AudioGeneratorPort
+	defaultProvider string
+}
+
+func NewAudioGeneratorRouter(providers map[string]outbound.AudioGeneratorPort, defaultProvider string, logger outbound.LoggerPort) (outbound.AudioGeneratorPort, error) {
+	if _, ok := providers[defaultProvider]; !ok {
+		return nil, fmt.Errorf("default tts provider %s is not configured", defaultProvider)
+	}
+
+	return &audioGeneratorRouter{
+		logger:          logger,
+		providers:       providers,
+		defaultProvider: defaultProvider,
+	}, nil
+}
+
+func (r *audioGeneratorRouter) Generate(ctx context.Context, generateAudioParams outbound.GenerateAudioParams) (outbound.GeneratedAudio, error) {
+	provider, generator, params, err := r.route(generateAudioParams)
+	if err != nil {
+		return outbound.GeneratedAudio{}, err
+	}
+
+	audio, err := generator.Generate(ctx, params)
+	audio.Provider = provider
+
+	return audio, err
+}
+
+func (r *audioGeneratorRouter) GenerateStream(ctx context.Context, generateAudioParams outbound.GenerateAudioParams) (outbound.AudioStream, error) {
+	provider, generator, params, err := r.route(generateAudioParams)
+	if err != nil {
+		return outbound.AudioStream{}, err
+	}
+
+	stream, err := generator.GenerateStream(ctx, params)
+	stream.Provider = provider
+
+	return stream, err
+}
+
+func (r *audioGeneratorRouter) route(generateAudioParams outbound.GenerateAudioParams) (string, outbound.AudioGeneratorPort, outbound.GenerateAudioParams, error) {
+	provider, voiceID := domain.SplitVoiceID(generateAudioParams.VoiceID, r.defaultProvider)
+
+	generator, ok := r.providers[provider]
+	if !ok {
+		r.logger.WarnWithFields("Requested tts provider is not configured", map[string]interface{}{
+			"provider": provider,
+			"voice_id": generateAudioParams.VoiceID,
+		})
+		return provider, nil, generateAudioParams, fmt.Errorf("tts provider %s is not configured", provider)
+	}
+
+	generateAudioParams.VoiceID = voiceID
+
+	return provider, generator, generateAudioParams, nil
+}
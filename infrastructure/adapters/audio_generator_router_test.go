@@ -0,0 +1,52 @@
+package adapters
+
+import (
+	"context"
+	"generate-script-lambda/application/ports/outbound"
+	"testing"
+)
+
+type recordingAudioGenerator struct {
+	voiceID string
+}
+
+func (r *recordingAudioGenerator) Generate(_ context.Context, params outbound.GenerateAudioParams) (outbound.GeneratedAudio, error) {
+	r.voiceID = params.VoiceID
+	return outbound.GeneratedAudio{}, nil
+}
+
+func (r *recordingAudioGenerator) GenerateStream(_ context.Context, params outbound.GenerateAudioParams) (outbound.AudioStream, error) {
+	r.voiceID = params.VoiceID
+	return outbound.AudioStream{}, nil
+}
+
+func TestAudioGeneratorRouter_Generate(t *testing.T) {
+	elevenLabs := &recordingAudioGenerator{}
+	piper := &recordingAudioGenerator{}
+
+	router, err := NewAudioGeneratorRouter(map[string]outbound.AudioGeneratorPort{
+		"elevenlabs": elevenLabs,
+		"piper":      piper,
+	}, "elevenlabs", NewZerologWrapper())
+	if err != nil {
+		t.Fatal("Failed to create router:", err)
+	}
+
+	if _, err = router.Generate(context.Background(), outbound.GenerateAudioParams{VoiceID: "2EiwWnXFnvU5JabPnv8n"}); err != nil {
+		t.Fatal("Failed to generate audio:", err)
+	}
+	if elevenLabs.voiceID != "2EiwWnXFnvU5JabPnv8n" {
+		t.Fatalf("Expected default provider to receive voice, got %q", elevenLabs.voiceID)
+	}
+
+	if _, err = router.Generate(context.Background(), outbound.GenerateAudioParams{VoiceID: "piper:en_US-lessac-medium"}); err != nil {
+		t.Fatal("Failed to generate audio:", err)
+	}
+	if piper.voiceID != "en_US-lessac-medium" {
+		t.Fatalf("Expected piper to receive unprefixed voice, got %q", piper.voiceID)
+	}
+
+	if _, err = router.Generate(context.Background(), outbound.GenerateAudioParams{VoiceID: "openai:alloy"}); err == nil {
+		t.Fatal("Expected an error for an unconfigured provider")
+	}
+}
@@ -0,0 +1,69 @@
+package adapters
+
+import (
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+)
+
+// cachedSegment is the serialised form shared by the Redis and in-memory
+// segment caches; it mirrors dynamoSegmentItem.
+type cachedSegment struct {
+	StoryID   string                `json:"story_id"`
+	SegmentID string                `json:"segment_id"`
+	UserID    string                `json:"user_id"`
+	Text      string                `json:"text"`
+	Type      domain.SegmentType    `json:"type"`
+	Ordinal   int                   `json:"ordinal"`
+	Version   int                   `json:"version,omitempty"`
+	Mood      domain.Mood           `json:"mood,omitempty"`
+	MediaURL  string                `json:"media_url"`
+	VttURL    string                `json:"vtt_url,omitempty"`
+	SrtURL    string                `json:"srt_url,omitempty"`
+	Words     []domain.WordTiming   `json:"words,omitempty"`
+	Media     *domain.MediaMetadata `json:"media,omitempty"`
+}
+
+func newCachedSegment(segment domain.SegmentWithMediaUrl, userID string) cachedSegment {
+	cached := cachedSegment{
+		StoryID:   segment.StoryID,
+		SegmentID: segment.ID,
+		UserID:    userID,
+		Text:      segment.Text,
+		Type:      segment.Type,
+		Ordinal:   segment.Ordinal,
+		Version:   segment.Version,
+		Mood:      segment.Mood,
+		MediaURL:  segment.MediaURL,
+		VttURL:    segment.Subtitles.VttURL,
+		SrtURL:    segment.Subtitles.SrtURL,
+		Media:     segment.Metadata,
+	}
+	if segment.Alignment != nil {
+		cached.Words = segment.Alignment.Words
+	}
+
+	return cached
+}
+
+func (c cachedSegment) matches(query outbound.SegmentQuery) bool {
+	return c.UserID == query.UserID && (query.Type == "" || c.Type == query.Type)
+}
+
+func (c cachedSegment) toSegment() domain.SegmentWithMediaUrl {
+	segment := domain.SegmentWithMediaUrl{
+		MediaURL: c.MediaURL,
+		Metadata: c.Media,
+		Subtitles: domain.SubtitleURLs{
+			VttURL: c.VttURL,
+			SrtURL: c.SrtURL,
+		},
+		Segment: domain.NewSegment(c.Text, c.Type, c.SegmentID, c.StoryID, c.Ordinal),
+	}
+	segment.Mood = c.Mood
+	segment.Version = c.Version
+	if len(c.Words) > 0 {
+		segment.Alignment = &domain.Alignment{Words: c.Words}
+	}
+
+	return segment
+}
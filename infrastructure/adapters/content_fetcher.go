@@ -10,6 +10,7 @@ import (
 
 type ContentFetcher interface {
 	FetchContent(req *http.Request) ([]byte, error)
+	StreamContent(req *http.Request) (io.ReadCloser, error)
 }
 
 type contentFetcher struct {
@@ -23,12 +24,47 @@ func NewContentFetcher(logger outbound.LoggerPort) ContentFetcher {
 }
 
 func (c *contentFetcher) FetchContent(req *http.Request) ([]byte, error) {
+	res, err := c.doWithRetries(req)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := c.readResponseBodyPayload(res)
+	if err != nil {
+		c.logger.ErrorWithFields(err, "Failed to read the response body", map[string]interface{}{
+			"method": req.Method,
+			"URL":    req.URL.String(),
+		})
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+func (c *contentFetcher) StreamContent(req *http.Request) (io.ReadCloser, error) {
+	res, err := c.doWithRetries(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
+func (c *contentFetcher) doWithRetries(req *http.Request) (*http.Response, error) {
 	const maxRetries int = 3
 	const retryDelay = 5 * time.Second
 
 	client := &http.Client{}
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
 		res, err := client.Do(req)
 		if err != nil {
 			c.logger.ErrorWithFields(err, "Failed to send the HTTP request", map[string]interface{}{
@@ -39,6 +75,7 @@ func (c *contentFetcher) FetchContent(req *http.Request) ([]byte, error) {
 		}
 
 		if res.StatusCode == http.StatusTooManyRequests {
+			_ = res.Body.Close()
 			if attempt == maxRetries {
 				return nil, fmt.Errorf("max retries reached for 429 Too Many Requests")
 			}
@@ -67,16 +104,7 @@ func (c *contentFetcher) FetchContent(req *http.Request) ([]byte, error) {
 			return nil, fmt.Errorf("HTTP request returned non-OK status code: %d", res.StatusCode)
 		}
 
-		payload, err := c.readResponseBodyPayload(res)
-		if err != nil {
-			c.logger.ErrorWithFields(err, "Failed to read the response body", map[string]interface{}{
-				"method": req.Method,
-				"URL":    req.URL.String(),
-			})
-			return nil, err
-		}
-
-		return payload, nil
+		return res, nil
 	}
 
 	return nil, fmt.Errorf("failed to fetch content after retries")
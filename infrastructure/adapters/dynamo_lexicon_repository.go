@@ -0,0 +1,163 @@
+package adapters
+
+import (
+	"context"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"time"
+)
+
+type dynamoLexiconItem struct {
+	UserId    string                `dynamodbav:"user_id"`
+	LexiconId string                `dynamodbav:"lexicon_id"`
+	StoryId   string                `dynamodbav:"story_id,omitempty"`
+	Name      string                `dynamodbav:"name"`
+	Entries   []domain.LexiconEntry `dynamodbav:"entries"`
+	CreatedAt time.Time             `dynamodbav:"created_at"`
+	UpdatedAt time.Time             `dynamodbav:"updated_at"`
+}
+
+type dynamoLexiconRepository struct {
+	logger        outbound.LoggerPort
+	dynamoSvc     *dynamodb.DynamoDB
+	lexiconConfig *config.LexiconConfig
+}
+
+func NewDynamoLexiconRepository(logger outbound.LoggerPort, dynamoSvc *dynamodb.DynamoDB, lexiconConfig *config.LexiconConfig) outbound.LexiconRepositoryPort {
+	return &dynamoLexiconRepository{
+		logger:        logger,
+		dynamoSvc:     dynamoSvc,
+		lexiconConfig: lexiconConfig,
+	}
+}
+
+func (r *dynamoLexiconRepository) Save(ctx context.Context, lexicon domain.Lexicon) error {
+	item := dynamoLexiconItem{
+		UserId:    lexicon.UserID,
+		LexiconId: lexicon.ID,
+		StoryId:   lexicon.StoryID,
+		Name:      lexicon.Name,
+		Entries:   lexicon.Entries,
+		CreatedAt: lexicon.CreatedAt,
+		UpdatedAt: lexicon.UpdatedAt,
+	}
+	av, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to marshal lexicon item", map[string]interface{}{
+			"lexicon_id": lexicon.ID,
+		})
+		return err
+	}
+
+	_, err = r.dynamoSvc.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(r.lexiconConfig.TableName),
+	})
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to save lexicon item", map[string]interface{}{
+			"lexicon_id": lexicon.ID,
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (r *dynamoLexiconRepository) Get(ctx context.Context, userID string, lexiconID string) (domain.Lexicon, error) {
+	res, err := r.dynamoSvc.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.lexiconConfig.TableName),
+		Key:       r.key(userID, lexiconID),
+	})
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to get lexicon item", map[string]interface{}{
+			"lexicon_id": lexiconID,
+		})
+		return domain.Lexicon{}, err
+	}
+	if res.Item == nil {
+		return domain.Lexicon{}, domain.ErrNotFound
+	}
+
+	var item dynamoLexiconItem
+	err = dynamodbattribute.UnmarshalMap(res.Item, &item)
+	if err != nil {
+		r.logger.Error(err, "Failed to unmarshal lexicon item")
+		return domain.Lexicon{}, err
+	}
+
+	return item.toLexicon(), nil
+}
+
+func (r *dynamoLexiconRepository) List(ctx context.Context, userID string) ([]domain.Lexicon, error) {
+	lexicons := make([]domain.Lexicon, 0)
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.lexiconConfig.TableName),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":user_id": {S: aws.String(userID)},
+		},
+	}
+
+	var unmarshalErr error
+	err := r.dynamoSvc.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		var items []dynamoLexiconItem
+		unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items)
+		if unmarshalErr != nil {
+			return false
+		}
+		for _, item := range items {
+			lexicons = append(lexicons, item.toLexicon())
+		}
+		return true
+	})
+	if err == nil {
+		err = unmarshalErr
+	}
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to list lexicons", map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	return lexicons, nil
+}
+
+func (r *dynamoLexiconRepository) Delete(ctx context.Context, userID string, lexiconID string) error {
+	_, err := r.dynamoSvc.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.lexiconConfig.TableName),
+		Key:       r.key(userID, lexiconID),
+	})
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to delete lexicon item", map[string]interface{}{
+			"lexicon_id": lexiconID,
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (r *dynamoLexiconRepository) key(userID string, lexiconID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"user_id":    {S: aws.String(userID)},
+		"lexicon_id": {S: aws.String(lexiconID)},
+	}
+}
+
+func (i dynamoLexiconItem) toLexicon() domain.Lexicon {
+	return domain.Lexicon{
+		ID:        i.LexiconId,
+		UserID:    i.UserId,
+		StoryID:   i.StoryId,
+		Name:      i.Name,
+		Entries:   i.Entries,
+		CreatedAt: i.CreatedAt,
+		UpdatedAt: i.UpdatedAt,
+	}
+}
@@ -12,13 +12,20 @@ import (
 )
 
 type dynamoSegmentItem struct {
-	StoryId        string             `dynamodbav:"story_id"`
-	SegmentId      string             `dynamodbav:"segment_id"`
-	Text           string             `dynamodbav:"text"`
-	S3Url          string             `dynamodbav:"s3_url"`
-	Type           domain.SegmentType `dynamodbav:"type"`
-	SegmentOrdinal int                `dynamodbav:"segment_ordinal"`
-	TTL            int64              `dynamodbav:"ttl"`
+	StoryId        string                `dynamodbav:"story_id"`
+	SegmentId      string                `dynamodbav:"segment_id"`
+	UserId         string                `dynamodbav:"user_id,omitempty"`
+	Text           string                `dynamodbav:"text"`
+	S3Url          string                `dynamodbav:"s3_url"`
+	VttUrl         string                `dynamodbav:"vtt_url,omitempty"`
+	SrtUrl         string                `dynamodbav:"srt_url,omitempty"`
+	Words          []domain.WordTiming   `dynamodbav:"words,omitempty"`
+	Media          *domain.MediaMetadata `dynamodbav:"media,omitempty"`
+	Type           domain.SegmentType    `dynamodbav:"type"`
+	SegmentOrdinal int                   `dynamodbav:"segment_ordinal"`
+	Version        int                   `dynamodbav:"version,omitempty"`
+	Mood           domain.Mood           `dynamodbav:"mood,omitempty"`
+	TTL            int64                 `dynamodbav:"ttl"`
 }
 
 type dynamoCache struct {
@@ -35,16 +42,8 @@ func NewDynamoCache(logger outbound.LoggerPort, dynamoSvc *dynamodb.DynamoDB, dy
 	}
 }
 
-func (c *dynamoCache) Save(ctx context.Context, segment domain.SegmentWithMediaUrl) error {
-	item := dynamoSegmentItem{
-		StoryId:        segment.StoryID,
-		SegmentId:      segment.ID,
-		Text:           segment.Text,
-		S3Url:          segment.MediaURL,
-		Type:           segment.Type,
-		SegmentOrdinal: segment.Ordinal,
-		TTL:            time.Now().Add(time.Duration(c.dynamoConfig.TtlMinutes) * time.Minute).Unix(),
-	}
+func (c *dynamoCache) Save(ctx context.Context, segment domain.SegmentWithMediaUrl, userID string) error {
+	item := c.newItem(segment, userID)
 	av, err := dynamodbattribute.MarshalMap(item)
 	if err != nil {
 		c.logger.ErrorWithFields(err, "Failed to marshal segment item", map[string]interface{}{
@@ -68,3 +67,154 @@ func (c *dynamoCache) Save(ctx context.Context, segment domain.SegmentWithMediaU
 
 	return err
 }
+
+func (c *dynamoCache) SaveBatch(ctx context.Context, segments []domain.SegmentWithMediaUrl, userID string) ([]domain.SegmentWithMediaUrl, error) {
+	bySegmentID := make(map[string]domain.SegmentWithMediaUrl, len(segments))
+	unprocessed := make([]domain.SegmentWithMediaUrl, 0)
+
+	for start := 0; start < len(segments); start += config.MaxMetadataBatchSize {
+		end := start + config.MaxMetadataBatchSize
+		if end > len(segments) {
+			end = len(segments)
+		}
+
+		requests := make([]*dynamodb.WriteRequest, 0, end-start)
+		for _, segment := range segments[start:end] {
+			av, err := dynamodbattribute.MarshalMap(c.newItem(segment, userID))
+			if err != nil {
+				c.logger.ErrorWithFields(err, "Failed to marshal segment item", map[string]interface{}{
+					"segment_id": segment.ID,
+				})
+				return nil, err
+			}
+			bySegmentID[segment.ID] = segment
+			requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: av}})
+		}
+
+		res, err := c.dynamoSvc.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]*dynamodb.WriteRequest{c.dynamoConfig.TableName: requests},
+		})
+		if err != nil {
+			c.logger.ErrorWithFields(err, "Failed to batch save segment items", map[string]interface{}{
+				"count": len(requests),
+			})
+			return nil, err
+		}
+
+		for _, request := range res.UnprocessedItems[c.dynamoConfig.TableName] {
+			if request.PutRequest == nil {
+				continue
+			}
+			segmentID := aws.StringValue(request.PutRequest.Item["segment_id"].S)
+			if segment, ok := bySegmentID[segmentID]; ok {
+				unprocessed = append(unprocessed, segment)
+			}
+		}
+	}
+
+	return unprocessed, nil
+}
+
+func (c *dynamoCache) newItem(segment domain.SegmentWithMediaUrl, userID string) dynamoSegmentItem {
+	item := dynamoSegmentItem{
+		StoryId:        segment.StoryID,
+		SegmentId:      segment.ID,
+		UserId:         userID,
+		Text:           segment.Text,
+		S3Url:          segment.MediaURL,
+		VttUrl:         segment.Subtitles.VttURL,
+		SrtUrl:         segment.Subtitles.SrtURL,
+		Media:          segment.Metadata,
+		Type:           segment.Type,
+		SegmentOrdinal: segment.Ordinal,
+		Version:        segment.Version,
+		Mood:           segment.Mood,
+		TTL:            time.Now().Add(time.Duration(c.dynamoConfig.TtlMinutes) * time.Minute).Unix(),
+	}
+	if segment.Alignment != nil {
+		item.Words = segment.Alignment.Words
+	}
+
+	return item
+}
+
+func (c *dynamoCache) List(ctx context.Context, storyID string) ([]domain.SegmentWithMediaUrl, error) {
+	return c.query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(c.dynamoConfig.TableName),
+		KeyConditionExpression: aws.String("story_id = :story_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":story_id": {S: aws.String(storyID)},
+		},
+	}, storyID)
+}
+
+// Query filters on the owner server side. Items written before user_id was
+// stored have no owner and never match.
+func (c *dynamoCache) Query(ctx context.Context, query outbound.SegmentQuery) ([]domain.SegmentWithMediaUrl, error) {
+	filter := "user_id = :user_id"
+	values := map[string]*dynamodb.AttributeValue{
+		":story_id": {S: aws.String(query.StoryID)},
+		":user_id":  {S: aws.String(query.UserID)},
+	}
+	if query.Type != "" {
+		filter += " AND #type = :type"
+		values[":type"] = &dynamodb.AttributeValue{S: aws.String(string(query.Type))}
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(c.dynamoConfig.TableName),
+		KeyConditionExpression:    aws.String("story_id = :story_id"),
+		FilterExpression:          aws.String(filter),
+		ExpressionAttributeValues: values,
+	}
+	if query.Type != "" {
+		input.ExpressionAttributeNames = map[string]*string{"#type": aws.String("type")}
+	}
+
+	return c.query(ctx, input, query.StoryID)
+}
+
+func (c *dynamoCache) query(ctx context.Context, input *dynamodb.QueryInput, storyID string) ([]domain.SegmentWithMediaUrl, error) {
+	segments := make([]domain.SegmentWithMediaUrl, 0)
+	var unmarshalErr error
+	err := c.dynamoSvc.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		items := make([]dynamoSegmentItem, 0, len(page.Items))
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); unmarshalErr != nil {
+			return false
+		}
+		for _, item := range items {
+			segments = append(segments, item.toSegment())
+		}
+		return true
+	})
+	if err == nil {
+		err = unmarshalErr
+	}
+	if err != nil {
+		c.logger.ErrorWithFields(err, "Failed to query segment items", map[string]interface{}{
+			"story_id": storyID,
+		})
+		return nil, err
+	}
+
+	return segments, nil
+}
+
+func (i dynamoSegmentItem) toSegment() domain.SegmentWithMediaUrl {
+	segment := domain.SegmentWithMediaUrl{
+		MediaURL: i.S3Url,
+		Metadata: i.Media,
+		Subtitles: domain.SubtitleURLs{
+			VttURL: i.VttUrl,
+			SrtURL: i.SrtUrl,
+		},
+		Segment: domain.NewSegment(i.Text, i.Type, i.SegmentId, i.StoryId, i.SegmentOrdinal),
+	}
+	segment.Mood = i.Mood
+	segment.Version = i.Version
+	if len(i.Words) > 0 {
+		segment.Alignment = &domain.Alignment{Words: i.Words}
+	}
+
+	return segment
+}
@@ -0,0 +1,258 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"time"
+)
+
+const (
+	pendingOutboxStatus      = "pending"
+	deadLetteredOutboxStatus = "dead_lettered"
+)
+
+// dynamoStoryOutboxItem keeps the save request as JSON; the Story API
+// payload is never queried, only replayed. The due index is keyed on status
+// and next_attempt_at, so pending entries are read in order without touching
+// dead-lettered ones.
+type dynamoStoryOutboxItem struct {
+	ID            string `dynamodbav:"id"`
+	Story         string `dynamodbav:"story"`
+	Attempts      int    `dynamodbav:"attempts"`
+	NextAttemptAt int64  `dynamodbav:"next_attempt_at"`
+	LastError     string `dynamodbav:"last_error,omitempty"`
+	Status        string `dynamodbav:"status"`
+	CreatedAt     int64  `dynamodbav:"created_at"`
+}
+
+type dynamoStoryOutbox struct {
+	logger       outbound.LoggerPort
+	dynamoSvc    *dynamodb.DynamoDB
+	outboxConfig *config.StoryOutboxConfig
+}
+
+func NewDynamoStoryOutbox(logger outbound.LoggerPort, dynamoSvc *dynamodb.DynamoDB,
+	outboxConfig *config.StoryOutboxConfig) outbound.StoryOutboxPort {
+	return &dynamoStoryOutbox{
+		logger:       logger,
+		dynamoSvc:    dynamoSvc,
+		outboxConfig: outboxConfig,
+	}
+}
+
+func (o *dynamoStoryOutbox) Add(ctx context.Context, entry outbound.StoryOutboxEntry) error {
+	return o.put(ctx, entry, "attribute_not_exists(id)")
+}
+
+func (o *dynamoStoryOutbox) Update(ctx context.Context, entry outbound.StoryOutboxEntry) error {
+	err := o.put(ctx, entry, "attribute_exists(id)")
+	if isConditionalCheckFailed(err) {
+		return fmt.Errorf("%w: outbox entry %s", domain.ErrNotFound, entry.ID)
+	}
+
+	return err
+}
+
+func (o *dynamoStoryOutbox) put(ctx context.Context, entry outbound.StoryOutboxEntry, condition string) error {
+	story, err := json.Marshal(entry.Story)
+	if err != nil {
+		return err
+	}
+	status := pendingOutboxStatus
+	if entry.DeadLettered {
+		status = deadLetteredOutboxStatus
+	}
+	av, err := dynamodbattribute.MarshalMap(dynamoStoryOutboxItem{
+		ID:            entry.ID,
+		Story:         string(story),
+		Attempts:      entry.Attempts,
+		NextAttemptAt: entry.NextAttemptAt.UnixMilli(),
+		LastError:     entry.LastError,
+		Status:        status,
+		CreatedAt:     entry.CreatedAt.UnixMilli(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = o.dynamoSvc.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:                av,
+		TableName:           aws.String(o.outboxConfig.TableName),
+		ConditionExpression: aws.String(condition),
+	})
+	if err != nil && !isConditionalCheckFailed(err) {
+		o.logger.ErrorWithFields(err, "Failed to save story outbox item", map[string]interface{}{
+			"story_id": entry.Story.ID,
+		})
+	}
+
+	return err
+}
+
+func (o *dynamoStoryOutbox) Due(ctx context.Context, now time.Time, limit int) ([]outbound.StoryOutboxEntry, error) {
+	res, err := o.dynamoSvc.QueryWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(o.outboxConfig.TableName),
+		IndexName:              aws.String(o.outboxConfig.DueIndex),
+		KeyConditionExpression: aws.String("#status = :pending AND next_attempt_at <= :now"),
+		ExpressionAttributeNames: map[string]*string{
+			"#status": aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pending": {S: aws.String(pendingOutboxStatus)},
+			":now":     {N: aws.String(fmt.Sprint(now.UnixMilli()))},
+		},
+		Limit: aws.Int64(int64(limit)),
+	})
+	if err != nil {
+		o.logger.Error(err, "Failed to query due story outbox items")
+		return nil, err
+	}
+
+	var items []dynamoStoryOutboxItem
+	if err = dynamodbattribute.UnmarshalListOfMaps(res.Items, &items); err != nil {
+		o.logger.Error(err, "Failed to unmarshal story outbox items")
+		return nil, err
+	}
+
+	entries := make([]outbound.StoryOutboxEntry, 0, len(items))
+	for _, item := range items {
+		entry := outbound.StoryOutboxEntry{
+			ID:            item.ID,
+			Attempts:      item.Attempts,
+			NextAttemptAt: time.UnixMilli(item.NextAttemptAt).UTC(),
+			LastError:     item.LastError,
+			DeadLettered:  item.Status == deadLetteredOutboxStatus,
+			CreatedAt:     time.UnixMilli(item.CreatedAt).UTC(),
+		}
+		if err = json.Unmarshal([]byte(item.Story), &entry.Story); err != nil {
+			o.logger.Error(err, "Failed to unmarshal story outbox item")
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (o *dynamoStoryOutbox) Claim(ctx context.Context, entry outbound.StoryOutboxEntry, leaseUntil time.Time) error {
+	_, err := o.dynamoSvc.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(o.outboxConfig.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(entry.ID)},
+		},
+		UpdateExpression:    aws.String("SET next_attempt_at = :lease"),
+		ConditionExpression: aws.String("#status = :pending AND next_attempt_at = :listed"),
+		ExpressionAttributeNames: map[string]*string{
+			"#status": aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":lease":   {N: aws.String(fmt.Sprint(leaseUntil.UnixMilli()))},
+			":pending": {S: aws.String(pendingOutboxStatus)},
+			":listed":  {N: aws.String(fmt.Sprint(entry.NextAttemptAt.UnixMilli()))},
+		},
+	})
+	if isConditionalCheckFailed(err) {
+		return fmt.Errorf("%w: unclaimed outbox entry %s", domain.ErrNotFound, entry.ID)
+	}
+	if err != nil {
+		o.logger.ErrorWithFields(err, "Failed to claim story outbox item", map[string]interface{}{
+			"story_id": entry.Story.ID,
+		})
+	}
+
+	return err
+}
+
+func (o *dynamoStoryOutbox) Remove(ctx context.Context, id string) error {
+	_, err := o.dynamoSvc.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(o.outboxConfig.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+	})
+	if err != nil {
+		o.logger.ErrorWithFields(err, "Failed to remove story outbox item", map[string]interface{}{
+			"entry_id": id,
+		})
+	}
+
+	return err
+}
+
+// Depth reads the pending entries' creation times and only counts the
+// dead-lettered ones, both through the due index.
+func (o *dynamoStoryOutbox) Depth(ctx context.Context) (domain.OutboxDepth, error) {
+	var depth domain.OutboxDepth
+	err := o.queryStatus(ctx, pendingOutboxStatus, "created_at", func(page *dynamodb.QueryOutput) error {
+		var items []dynamoStoryOutboxItem
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return err
+		}
+		for _, item := range items {
+			depth.Pending++
+			createdAt := time.UnixMilli(item.CreatedAt).UTC()
+			if depth.OldestPendingAt == nil || createdAt.Before(*depth.OldestPendingAt) {
+				depth.OldestPendingAt = &createdAt
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.OutboxDepth{}, err
+	}
+
+	err = o.queryStatus(ctx, deadLetteredOutboxStatus, "", func(page *dynamodb.QueryOutput) error {
+		depth.DeadLettered += int(aws.Int64Value(page.Count))
+		return nil
+	})
+	if err != nil {
+		return domain.OutboxDepth{}, err
+	}
+
+	return depth, nil
+}
+
+// queryStatus pages through the entries with the given status, projecting
+// the given attributes or only counting them when there are none.
+func (o *dynamoStoryOutbox) queryStatus(ctx context.Context, status string, projection string,
+	handlePage func(page *dynamodb.QueryOutput) error) error {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(o.outboxConfig.TableName),
+		IndexName:              aws.String(o.outboxConfig.DueIndex),
+		KeyConditionExpression: aws.String("#status = :status"),
+		ExpressionAttributeNames: map[string]*string{
+			"#status": aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":status": {S: aws.String(status)},
+		},
+		Select: aws.String(dynamodb.SelectCount),
+	}
+	if projection != "" {
+		input.Select = aws.String(dynamodb.SelectSpecificAttributes)
+		input.ProjectionExpression = aws.String(projection)
+	}
+
+	var handleErr error
+	err := o.dynamoSvc.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, _ bool) bool {
+		handleErr = handlePage(page)
+		return handleErr == nil
+	})
+	if err == nil {
+		err = handleErr
+	}
+	if err != nil {
+		o.logger.ErrorWithFields(err, "Failed to count story outbox items", map[string]interface{}{
+			"status": status,
+		})
+	}
+
+	return err
+}
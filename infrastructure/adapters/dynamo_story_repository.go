@@ -0,0 +1,238 @@
+package adapters
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"time"
+)
+
+// dynamoStoryItem keeps timestamps as epoch milliseconds so the per-user
+// indexes on created_at and updated_at sort them numerically.
+type dynamoStoryItem struct {
+	UserId             string                 `dynamodbav:"user_id"`
+	StoryId            string                 `dynamodbav:"story_id"`
+	Status             domain.StoryStatus     `dynamodbav:"status"`
+	Parameters         domain.StoryParameters `dynamodbav:"parameters"`
+	RemixOf            string                 `dynamodbav:"remix_of,omitempty"`
+	Provider           string                 `dynamodbav:"provider,omitempty"`
+	Model              string                 `dynamodbav:"model,omitempty"`
+	FullAudioUrl       string                 `dynamodbav:"full_audio_url,omitempty"`
+	FullAudioMediaPath string                 `dynamodbav:"full_audio_media_path,omitempty"`
+	Segments           []domain.StorySegment  `dynamodbav:"segments"`
+	Edits              []domain.SegmentEdit   `dynamodbav:"edits,omitempty"`
+	CreatedAt          int64                  `dynamodbav:"created_at"`
+	UpdatedAt          int64                  `dynamodbav:"updated_at"`
+}
+
+type dynamoStoryRepository struct {
+	logger           outbound.LoggerPort
+	dynamoSvc        *dynamodb.DynamoDB
+	repositoryConfig *config.StoryRepositoryConfig
+}
+
+func NewDynamoStoryRepository(logger outbound.LoggerPort, dynamoSvc *dynamodb.DynamoDB,
+	repositoryConfig *config.StoryRepositoryConfig) outbound.StoryRepositoryPort {
+	return &dynamoStoryRepository{
+		logger:           logger,
+		dynamoSvc:        dynamoSvc,
+		repositoryConfig: repositoryConfig,
+	}
+}
+
+func (r *dynamoStoryRepository) Save(ctx context.Context, story domain.Story) error {
+	return r.put(ctx, story, &dynamodb.PutItemInput{})
+}
+
+func (r *dynamoStoryRepository) Update(ctx context.Context, story domain.Story, previousUpdatedAt time.Time) error {
+	err := r.put(ctx, story, &dynamodb.PutItemInput{
+		ConditionExpression: aws.String("updated_at = :previous"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":previous": {N: aws.String(fmt.Sprint(previousUpdatedAt.UnixMilli()))},
+		},
+	})
+	if isConditionalCheckFailed(err) {
+		return fmt.Errorf("%w: story %s changed concurrently", domain.ErrConflict, story.ID)
+	}
+
+	return err
+}
+
+// put writes the story with the condition, if any, already set on input.
+func (r *dynamoStoryRepository) put(ctx context.Context, story domain.Story, input *dynamodb.PutItemInput) error {
+	item := dynamoStoryItem{
+		UserId:             story.UserID,
+		StoryId:            story.ID,
+		Status:             story.Status,
+		Parameters:         story.Parameters,
+		RemixOf:            story.RemixOf,
+		Provider:           story.Provider,
+		Model:              story.Model,
+		FullAudioUrl:       story.FullAudioURL,
+		FullAudioMediaPath: story.FullAudioMediaPath,
+		Segments:           story.Segments,
+		Edits:              story.Edits,
+		CreatedAt:          story.CreatedAt.UnixMilli(),
+		UpdatedAt:          story.UpdatedAt.UnixMilli(),
+	}
+	av, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to marshal story item", map[string]interface{}{
+			"story_id": story.ID,
+		})
+		return err
+	}
+
+	input.Item = av
+	input.TableName = aws.String(r.repositoryConfig.TableName)
+	_, err = r.dynamoSvc.PutItemWithContext(ctx, input)
+	if err != nil && !isConditionalCheckFailed(err) {
+		r.logger.ErrorWithFields(err, "Failed to save story item", map[string]interface{}{
+			"story_id": story.ID,
+		})
+	}
+
+	return err
+}
+
+func (r *dynamoStoryRepository) Get(ctx context.Context, userID string, storyID string) (domain.Story, error) {
+	res, err := r.dynamoSvc.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.repositoryConfig.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"user_id":  {S: aws.String(userID)},
+			"story_id": {S: aws.String(storyID)},
+		},
+	})
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to get story item", map[string]interface{}{
+			"story_id": storyID,
+		})
+		return domain.Story{}, err
+	}
+	if res.Item == nil {
+		return domain.Story{}, fmt.Errorf("%w: story %s", domain.ErrNotFound, storyID)
+	}
+
+	var item dynamoStoryItem
+	err = dynamodbattribute.UnmarshalMap(res.Item, &item)
+	if err != nil {
+		r.logger.Error(err, "Failed to unmarshal story item")
+		return domain.Story{}, err
+	}
+
+	return item.toStory(), nil
+}
+
+func (r *dynamoStoryRepository) List(ctx context.Context, query outbound.StoryListQuery) (domain.StoryPage, error) {
+	indexName := r.repositoryConfig.CreatedAtIndex
+	if query.SortBy == domain.UpdatedAtStorySortField {
+		indexName = r.repositoryConfig.UpdatedAtIndex
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.repositoryConfig.TableName),
+		IndexName:              aws.String(indexName),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":user_id": {S: aws.String(query.UserID)},
+		},
+		ScanIndexForward: aws.Bool(query.Ascending),
+		Limit:            aws.Int64(int64(query.Limit)),
+	}
+	if query.Cursor != "" {
+		startKey, err := decodeDynamoCursor(query.Cursor)
+		if err != nil || aws.StringValue(startKey["user_id"].S) != query.UserID {
+			return domain.StoryPage{}, fmt.Errorf("%w: invalid cursor", domain.ErrInvalidInput)
+		}
+		input.ExclusiveStartKey = startKey
+	}
+
+	res, err := r.dynamoSvc.QueryWithContext(ctx, input)
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to list stories", map[string]interface{}{
+			"user_id": query.UserID,
+		})
+		return domain.StoryPage{}, err
+	}
+
+	var items []dynamoStoryItem
+	if err = dynamodbattribute.UnmarshalListOfMaps(res.Items, &items); err != nil {
+		r.logger.Error(err, "Failed to unmarshal story items")
+		return domain.StoryPage{}, err
+	}
+
+	page := domain.StoryPage{Stories: make([]domain.Story, 0, len(items))}
+	for _, item := range items {
+		page.Stories = append(page.Stories, item.toStory())
+	}
+	if len(res.LastEvaluatedKey) > 0 {
+		page.NextCursor, err = encodeDynamoCursor(res.LastEvaluatedKey)
+		if err != nil {
+			return domain.StoryPage{}, err
+		}
+	}
+
+	return page, nil
+}
+
+func encodeDynamoCursor(key map[string]*dynamodb.AttributeValue) (string, error) {
+	encoded, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+func decodeDynamoCursor(cursor string) (map[string]*dynamodb.AttributeValue, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var key map[string]*dynamodb.AttributeValue
+	if err = json.Unmarshal(decoded, &key); err != nil {
+		return nil, err
+	}
+	if key["user_id"] == nil {
+		return nil, fmt.Errorf("cursor has no user_id")
+	}
+
+	return key, nil
+}
+
+func (i dynamoStoryItem) toStory() domain.Story {
+	segments := i.Segments
+	if segments == nil {
+		segments = make([]domain.StorySegment, 0)
+	}
+
+	return domain.Story{
+		ID:                 i.StoryId,
+		UserID:             i.UserId,
+		Status:             i.Status,
+		Parameters:         i.Parameters,
+		RemixOf:            i.RemixOf,
+		Provider:           i.Provider,
+		Model:              i.Model,
+		FullAudioURL:       i.FullAudioUrl,
+		FullAudioMediaPath: i.FullAudioMediaPath,
+		Segments:           segments,
+		Edits:              i.Edits,
+		CreatedAt:          time.UnixMilli(i.CreatedAt).UTC(),
+		UpdatedAt:          time.UnixMilli(i.UpdatedAt).UTC(),
+	}
+}
+
+func isConditionalCheckFailed(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
@@ -0,0 +1,186 @@
+package adapters
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDynamoTable answers PutItem and GetItem for a single story table,
+// honouring the updated_at condition used by Update.
+type fakeDynamoTable struct {
+	mu    sync.Mutex
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func (f *fakeDynamoTable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	switch strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.") {
+	case "PutItem":
+		var input dynamodb.PutItemInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		key := aws.StringValue(input.Item["story_id"].S)
+		if input.ConditionExpression != nil {
+			stored := f.items[key]
+			if stored == nil || aws.StringValue(stored["updated_at"].N) != aws.StringValue(input.ExpressionAttributeValues[":previous"].N) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`))
+				return
+			}
+		}
+		f.items[key] = input.Item
+		_, _ = w.Write([]byte(`{}`))
+	case "GetItem":
+		var input dynamodb.GetItemInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		_ = json.NewEncoder(w).Encode(dynamodb.GetItemOutput{Item: f.items[aws.StringValue(input.Key["story_id"].S)]})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ValidationException","message":"unsupported"}`))
+	}
+}
+
+func newTestDynamoStoryRepository(t *testing.T) outbound.StoryRepositoryPort {
+	server := httptest.NewServer(&fakeDynamoTable{items: make(map[string]map[string]*dynamodb.AttributeValue)})
+	t.Cleanup(server.Close)
+
+	dynamoSvc := dynamodb.New(session.Must(session.NewSession(&aws.Config{
+		Endpoint:    aws.String(server.URL),
+		Region:      aws.String("eu-west-1"),
+		Credentials: credentials.NewStaticCredentials("AKIDEXAMPLE", "secret", ""),
+		MaxRetries:  aws.Int(0),
+	})))
+
+	return NewDynamoStoryRepository(NewZerologWrapper(), dynamoSvc, &config.StoryRepositoryConfig{
+		TableName: "stories", CreatedAtIndex: "created_at", UpdatedAtIndex: "updated_at",
+	})
+}
+
+func TestDynamoStoryRepository_Get(t *testing.T) {
+	ctx := context.Background()
+	repository := newTestDynamoStoryRepository(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	story := domain.Story{
+		ID:        "story-1",
+		UserID:    "user-1",
+		Status:    domain.CompletedStoryStatus,
+		CreatedAt: base,
+		UpdatedAt: base.Add(time.Second),
+		Parameters: domain.StoryParameters{Input: "a castle", VoiceID: "voice", LexiconID: "lexicon-1",
+			VoiceSettings: &domain.VoiceSettingsOverride{Speed: aws.Float64(0.9)}},
+		Segments: []domain.StorySegment{{SegmentID: "audio-1", Type: domain.AudioSegmentType, Text: "Once.", Ordinal: 1,
+			Version: 1, Mood: domain.CalmMood, Words: []domain.WordTiming{{Word: "Once.", End: 0.5}}, URL: "https://media/audio-1"}},
+	}
+	if err := repository.Save(ctx, story); err != nil {
+		t.Fatal("Failed to save story:", err)
+	}
+
+	stored, err := repository.Get(ctx, "user-1", "story-1")
+	if err != nil {
+		t.Fatal("Failed to get story:", err)
+	}
+	if stored.Status != story.Status || !stored.CreatedAt.Equal(story.CreatedAt) || !stored.UpdatedAt.Equal(story.UpdatedAt) {
+		t.Errorf("expected %+v, got %+v", story, stored)
+	}
+	if stored.Parameters.LexiconID != "lexicon-1" || *stored.Parameters.VoiceSettings.Speed != 0.9 {
+		t.Errorf("expected parameters to round trip, got %+v", stored.Parameters)
+	}
+	if len(stored.Segments) != 1 || stored.Segments[0].Text != "Once." || stored.Segments[0].Mood != domain.CalmMood ||
+		len(stored.Segments[0].Words) != 1 || stored.Segments[0].URL != "https://media/audio-1" {
+		t.Errorf("expected segments to round trip, got %+v", stored.Segments)
+	}
+
+	if _, err = repository.Get(ctx, "user-1", "story-2"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected a missing story to be not found, got %v", err)
+	}
+}
+
+func TestDynamoStoryRepository_Update(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		previousUpdatedAt time.Time
+		expected          error
+	}{
+		{name: "unchanged", previousUpdatedAt: base},
+		{name: "changed concurrently", previousUpdatedAt: base.Add(-time.Millisecond), expected: domain.ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := newTestDynamoStoryRepository(t)
+			story := domain.Story{ID: "story-1", UserID: "user-1", Status: domain.CompletedStoryStatus, CreatedAt: base, UpdatedAt: base}
+			if err := repository.Save(ctx, story); err != nil {
+				t.Fatal("Failed to save story:", err)
+			}
+
+			updated := story
+			updated.Edits = []domain.SegmentEdit{{SegmentID: "audio-1", EditorID: "user-1", Text: "Twice.", Version: 2}}
+			updated.UpdatedAt = base.Add(time.Second)
+			if err := repository.Update(ctx, updated, tt.previousUpdatedAt); !errors.Is(err, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+
+			stored, err := repository.Get(ctx, "user-1", "story-1")
+			if err != nil {
+				t.Fatal("Failed to get story:", err)
+			}
+			expected := updated
+			if tt.expected != nil {
+				expected = story
+			}
+			if !stored.UpdatedAt.Equal(expected.UpdatedAt) || len(stored.Edits) != len(expected.Edits) {
+				t.Errorf("expected %+v, got %+v", expected, stored)
+			}
+		})
+	}
+}
+
+func TestDynamoStoryRepository_ListRejectsCursor(t *testing.T) {
+	repository := newTestDynamoStoryRepository(t)
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	foreign, err := encodeDynamoCursor(map[string]*dynamodb.AttributeValue{
+		"user_id": {S: aws.String("user-2")}, "story_id": {S: aws.String("story-9")},
+	})
+	if err != nil {
+		t.Fatal("Failed to encode cursor:", err)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "%%%"},
+		{name: "not json", cursor: encode("nope")},
+		{name: "no user", cursor: encode(`{"story_id":{"S":"story-9"}}`)},
+		{name: "other user", cursor: foreign},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repository.List(context.Background(), outbound.StoryListQuery{UserID: "user-1", Limit: 10, Cursor: tt.cursor})
+			if !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("expected an invalid cursor error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
+	"net/http"
+)
+
+type ElevenLabsVoicesResponse struct {
+	Voices []ElevenLabsVoice `json:"voices"`
+}
+
+type ElevenLabsVoice struct {
+	VoiceID           string            `json:"voice_id"`
+	Name              string            `json:"name"`
+	Labels            map[string]string `json:"labels"`
+	PreviewURL        string            `json:"preview_url"`
+	VerifiedLanguages []struct {
+		Language string `json:"language"`
+	} `json:"verified_languages"`
+}
+
+type elevenLabsVoiceCatalog struct {
+	ContentFetcher
+	logger           outbound.LoggerPort
+	elevenLabsConfig *config.ElevenLabsConfig
+}
+
+func NewElevenLabsVoiceCatalog(contentFetcher ContentFetcher, elevenLabsConfig *config.ElevenLabsConfig, logger outbound.LoggerPort) outbound.VoiceCatalogPort {
+	return &elevenLabsVoiceCatalog{
+		ContentFetcher:   contentFetcher,
+		logger:           logger,
+		elevenLabsConfig: elevenLabsConfig,
+	}
+}
+
+func (e *elevenLabsVoiceCatalog) ListVoices(ctx context.Context) ([]domain.Voice, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", e.elevenLabsConfig.VoicesApiUrl, nil)
+	if err != nil {
+		e.logger.ErrorWithFields(err, "Failed to create the HTTP request", map[string]interface{}{
+			"action": "Creating HTTP Request",
+			"URL":    e.elevenLabsConfig.VoicesApiUrl,
+		})
+		return nil, err
+	}
+	req.Header.Set("xi-api-key", e.elevenLabsConfig.ApiKey)
+	req.Header.Set("Accept", "application/json")
+
+	rawRes, err := e.FetchContent(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var voicesRes ElevenLabsVoicesResponse
+	err = json.Unmarshal(rawRes, &voicesRes)
+	if err != nil {
+		e.logger.Error(err, "Failed to unmarshal the response")
+		return nil, err
+	}
+
+	voices := make([]domain.Voice, len(voicesRes.Voices))
+	for i, voice := range voicesRes.Voices {
+		language := voice.Labels["language"]
+		if language == "" && len(voice.VerifiedLanguages) > 0 {
+			language = voice.VerifiedLanguages[0].Language
+		}
+		voices[i] = domain.Voice{
+			ID:         domain.JoinVoiceID(config.ElevenLabsTtsProvider, voice.VoiceID),
+			Provider:   config.ElevenLabsTtsProvider,
+			Name:       voice.Name,
+			Language:   language,
+			Gender:     voice.Labels["gender"],
+			Age:        voice.Labels["age"],
+			PreviewURL: voice.PreviewURL,
+		}
+	}
+
+	return voices, nil
+}
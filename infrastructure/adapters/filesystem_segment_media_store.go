@@ -0,0 +1,246 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type filesystemSegmentMediaStore struct {
+	logger  outbound.LoggerPort
+	rootDir string
+	baseUrl string
+}
+
+func NewFilesystemSegmentMediaStore(mediaStoreConfig *config.MediaStoreConfig, logger outbound.LoggerPort) (outbound.SegmentMediaStorePort, error) {
+	err := os.MkdirAll(mediaStoreConfig.RootDir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create media store root: %w", err)
+	}
+
+	return &filesystemSegmentMediaStore{
+		logger:  logger,
+		rootDir: mediaStoreConfig.RootDir,
+		baseUrl: strings.TrimSuffix(mediaStoreConfig.BaseUrl, "/"),
+	}, nil
+}
+
+func (f *filesystemSegmentMediaStore) Save(ctx context.Context, segment domain.SegmentWithMedia, userID string) (string, error) {
+	return f.write(mediaItemPath(segment.Segment, userID), func(file *os.File) error {
+		_, err := file.Write(segment.MediaContent)
+		return err
+	})
+}
+
+func (f *filesystemSegmentMediaStore) SaveStream(ctx context.Context, segment domain.SegmentWithMedia, content io.Reader, userID string) (string, error) {
+	return f.write(mediaItemPath(segment.Segment, userID), func(file *os.File) error {
+		_, err := io.Copy(file, content)
+		return err
+	})
+}
+
+func (f *filesystemSegmentMediaStore) SaveAttachment(ctx context.Context, segment domain.Segment, extension string, content []byte, userID string) (string, error) {
+	return f.write(mediaItemPath(segment, userID)+"."+extension, func(file *os.File) error {
+		_, err := file.Write(content)
+		return err
+	})
+}
+
+func (f *filesystemSegmentMediaStore) FindMedia(ctx context.Context, storyID string, segmentID string, userID string) (domain.MediaLinks, error) {
+	return f.find(mediaStoryPath(storyID, userID), storyID, newStoredSegmentMedia(segmentID, 0))
+}
+
+func (f *filesystemSegmentMediaStore) FindMediaVersion(ctx context.Context, storyID string, segmentID string, version int,
+	userID string) (domain.MediaLinks, error) {
+	return f.find(mediaVersionPath(storyID, userID, version), storyID, newStoredSegmentMedia(segmentID, version))
+}
+
+func (f *filesystemSegmentMediaStore) find(prefix string, storyID string, stored *storedSegmentMedia) (domain.MediaLinks, error) {
+	dir, err := LocalMediaPath(f.rootDir, prefix)
+	if err != nil {
+		return domain.MediaLinks{}, err
+	}
+
+	err = filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			relative, err := filepath.Rel(f.rootDir, filePath)
+			if err != nil {
+				return err
+			}
+			stored.add(filepath.ToSlash(relative))
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		f.logger.ErrorWithFields(err, "Failed to list story files", map[string]interface{}{
+			"story_id":   storyID,
+			"segment_id": stored.segmentID,
+		})
+		return domain.MediaLinks{}, err
+	}
+
+	return stored.links(storyID, f.url)
+}
+
+func (f *filesystemSegmentMediaStore) ArchiveMedia(ctx context.Context, segment domain.Segment, userID string) error {
+	objects, err := f.ListMedia(ctx, segment.StoryID, userID)
+	if err != nil {
+		return err
+	}
+	current := currentSegmentObjects(objects, segment)
+	if current == nil {
+		return fmt.Errorf("%w: media for segment %s", domain.ErrNotFound, segment.ID)
+	}
+
+	for _, object := range current {
+		archived := object
+		archived.Version = segment.CurrentVersion()
+		err := f.copy(mediaObjectPath(object, userID), mediaObjectPath(archived, userID))
+		if err != nil {
+			f.logger.ErrorWithFields(err, "Failed to archive media file", map[string]interface{}{
+				"story_id":   segment.StoryID,
+				"segment_id": segment.ID,
+			})
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *filesystemSegmentMediaStore) copy(sourcePath string, targetPath string) error {
+	filePath, err := LocalMediaPath(f.rootDir, sourcePath)
+	if err != nil {
+		return err
+	}
+	source, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = source.Close()
+	}()
+
+	_, err = f.write(targetPath, func(file *os.File) error {
+		_, err := io.Copy(file, source)
+		return err
+	})
+
+	return err
+}
+
+func (f *filesystemSegmentMediaStore) ListMedia(ctx context.Context, storyID string, userID string) ([]domain.MediaObject, error) {
+	storyDir, err := LocalMediaPath(f.rootDir, mediaStoryPath(storyID, userID))
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]domain.MediaObject, 0)
+	err = filepath.WalkDir(storyDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			return nil
+		}
+		relative, err := filepath.Rel(f.rootDir, filePath)
+		if err != nil {
+			return err
+		}
+		if object, ok := parseMediaObject(filepath.ToSlash(relative)); ok {
+			objects = append(objects, object)
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		f.logger.ErrorWithFields(err, "Failed to list story files", map[string]interface{}{
+			"story_id": storyID,
+		})
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+func (f *filesystemSegmentMediaStore) OpenMedia(ctx context.Context, object domain.MediaObject, userID string) (io.ReadCloser, error) {
+	filePath, err := LocalMediaPath(f.rootDir, mediaObjectPath(object, userID))
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: media for segment %s", domain.ErrNotFound, object.SegmentID)
+	}
+
+	return file, err
+}
+
+// write goes through a temporary file so the static route never serves a
+// partially written file.
+func (f *filesystemSegmentMediaStore) write(itemPath string, writeContent func(file *os.File) error) (string, error) {
+	filePath, err := LocalMediaPath(f.rootDir, itemPath)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(filepath.Dir(filePath), 0o755)
+	if err != nil {
+		f.logger.Error(err, "Failed to create media directory")
+		return "", err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		f.logger.Error(err, "Failed to create media file")
+		return "", err
+	}
+	defer func() {
+		_ = os.Remove(file.Name())
+	}()
+
+	err = writeContent(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		f.logger.Error(err, "Failed to write media file")
+		return "", err
+	}
+
+	err = os.Rename(file.Name(), filePath)
+	if err != nil {
+		f.logger.Error(err, "Failed to move media file into place")
+		return "", err
+	}
+
+	url, _, err := f.url(itemPath)
+
+	return url, err
+}
+
+func (f *filesystemSegmentMediaStore) url(itemPath string) (string, time.Time, error) {
+	return f.baseUrl + "/" + itemPath, time.Time{}, nil
+}
+
+// LocalMediaPath resolves a media item path below rootDir, rejecting paths
+// that would escape it.
+func LocalMediaPath(rootDir string, itemPath string) (string, error) {
+	cleaned := path.Clean("/" + itemPath)
+	if cleaned != "/"+strings.TrimSuffix(itemPath, "/") {
+		return "", fmt.Errorf("%w: invalid media path %s", domain.ErrInvalidInput, itemPath)
+	}
+
+	return filepath.Join(rootDir, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,48 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+const storybookThemeFile = "storybook.html"
+
+var tenantNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type filesystemStorybookThemes struct {
+	logger    outbound.LoggerPort
+	themesDir string
+}
+
+// NewFilesystemStorybookThemes reads tenant templates from
+// <themesDir>/<tenant>/storybook.html. An empty themesDir disables theming.
+func NewFilesystemStorybookThemes(themesDir string, logger outbound.LoggerPort) outbound.StorybookThemePort {
+	return &filesystemStorybookThemes{
+		logger:    logger,
+		themesDir: themesDir,
+	}
+}
+
+func (f *filesystemStorybookThemes) FindTemplate(ctx context.Context, tenant string) (string, error) {
+	if f.themesDir == "" || !tenantNameRegexp.MatchString(tenant) {
+		return "", fmt.Errorf("%w: storybook theme for tenant %q", domain.ErrNotFound, tenant)
+	}
+
+	content, err := os.ReadFile(filepath.Join(f.themesDir, tenant, storybookThemeFile))
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%w: storybook theme for tenant %q", domain.ErrNotFound, tenant)
+	}
+	if err != nil {
+		f.logger.ErrorWithFields(err, "Failed to read storybook theme", map[string]interface{}{
+			"tenant": tenant,
+		})
+		return "", err
+	}
+
+	return string(content), nil
+}
@@ -24,6 +24,8 @@ type DalleApiResponse struct {
 	} `json:"data"`
 }
 
+const dalleImageProvider = "dalle"
+
 type imageGenerator struct {
 	ContentFetcher
 	logger      outbound.LoggerPort
@@ -38,11 +40,11 @@ func NewImageGenerator(contentFetcher ContentFetcher, dalleConfig *config.DaLLeC
 	}
 }
 
-func (i *imageGenerator) Generate(ctx context.Context, description string) ([]byte, error) {
-	req, err := i.getRequest(ctx, description)
+func (i *imageGenerator) Generate(ctx context.Context, generateImageParams outbound.GenerateImageParams) (outbound.GeneratedImage, error) {
+	req, err := i.getRequest(ctx, generateImageParams)
 	if err != nil {
 		i.logger.Error(err, "Failed to create the HTTP request")
-		return nil, err
+		return outbound.GeneratedImage{}, err
 	}
 
 	var dalleRes DalleApiResponse
@@ -50,27 +52,34 @@ func (i *imageGenerator) Generate(ctx context.Context, description string) ([]by
 	rawRes, err := i.FetchContent(req)
 	if err != nil {
 		i.logger.Error(err, "Failed to fetch the content")
-		return nil, err
+		return outbound.GeneratedImage{}, err
 	}
 
 	err = json.Unmarshal(rawRes, &dalleRes)
 	if err != nil {
 		i.logger.Error(err, "Failed to unmarshal the response")
-		return nil, err
+		return outbound.GeneratedImage{}, err
 	}
 
 	decodedImage, err := base64.StdEncoding.DecodeString(dalleRes.Data[0].B64Json)
 	if err != nil {
 		i.logger.Error(err, "Failed to decode the image")
-		return nil, err
+		return outbound.GeneratedImage{}, err
 	}
 
-	return decodedImage, nil
+	return outbound.GeneratedImage{
+		Content:  decodedImage,
+		Provider: dalleImageProvider,
+	}, nil
 }
 
-func (i *imageGenerator) getRequest(ctx context.Context, text string) (*http.Request, error) {
+func (i *imageGenerator) getRequest(ctx context.Context, generateImageParams outbound.GenerateImageParams) (*http.Request, error) {
+	style := generateImageParams.Style
+	if style == "" {
+		style = outbound.DefaultImageStyle
+	}
 	reqBody := DalleApiRequest{
-		Prompt:         fmt.Sprintf("%s, in a cartoon style", text),
+		Prompt:         fmt.Sprintf("%s, in a %s style", generateImageParams.Description, style),
 		Size:           "256x256",
 		Number:         1,
 		ResponseFormat: "b64_json",
@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"context"
+	"generate-script-lambda/application/ports/outbound"
 	"generate-script-lambda/config"
 	"testing"
 )
@@ -15,7 +16,7 @@ func TestImageGenerator_Generate(t *testing.T) {
 	fetcher := NewContentFetcher(logger)
 	generator := NewImageGenerator(fetcher, dalleConfig, logger)
 
-	_, err = generator.Generate(context.Background(), "Hello world")
+	_, err = generator.Generate(context.Background(), outbound.GenerateImageParams{Description: "Hello world"})
 	if err != nil {
 		t.Fatal("Failed to generate image:", err)
 	}
@@ -0,0 +1,162 @@
+package adapters
+
+import (
+	"fmt"
+	"generate-script-lambda/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MediaOwnedBy reports whether a media item path lies below the user's own
+// media.
+func MediaOwnedBy(itemPath string, userID string) bool {
+	return userID != "" && strings.HasPrefix(itemPath, fmt.Sprintf("user/%s/", userID))
+}
+
+func mediaStoryPath(storyID string, userID string) string {
+	return fmt.Sprintf("user/%s/story/%s/segments/", userID, storyID)
+}
+
+// mediaVersionPath is where ArchiveMedia keeps a segment's replaced media.
+// It sits outside the segments prefix so listings only see current media.
+func mediaVersionPath(storyID string, userID string, version int) string {
+	return fmt.Sprintf("user/%s/story/%s/versions/%d/", userID, storyID, version)
+}
+
+func mediaItemPath(segment domain.Segment, userID string) string {
+	return fmt.Sprintf("%s%s/%s", mediaStoryPath(segment.StoryID, userID), segment.Type, segment.ID)
+}
+
+func mediaObjectPath(object domain.MediaObject, userID string) string {
+	itemPath := mediaItemPath(domain.Segment{StoryID: object.StoryID, Type: object.Type, ID: object.SegmentID}, userID)
+	if object.Version > 0 {
+		itemPath = fmt.Sprintf("%s%s/%s", mediaVersionPath(object.StoryID, userID, object.Version), object.Type, object.SegmentID)
+	}
+	if object.Extension != "" {
+		itemPath += "." + object.Extension
+	}
+
+	return itemPath
+}
+
+func parseMediaObject(itemPath string) (domain.MediaObject, bool) {
+	parts := strings.Split(itemPath, "/")
+	if len(parts) < 7 || parts[0] != "user" || parts[2] != "story" {
+		return domain.MediaObject{}, false
+	}
+
+	version := 0
+	switch {
+	case len(parts) == 7 && parts[4] == "segments":
+	case len(parts) == 8 && parts[4] == "versions":
+		parsed, err := strconv.Atoi(parts[5])
+		if err != nil || parsed < 1 {
+			return domain.MediaObject{}, false
+		}
+		version = parsed
+		parts = append(parts[:5], parts[6:]...)
+	default:
+		return domain.MediaObject{}, false
+	}
+
+	segmentID, extension, _ := strings.Cut(parts[6], ".")
+	if segmentID == "" {
+		return domain.MediaObject{}, false
+	}
+
+	return domain.MediaObject{
+		StoryID:   parts[3],
+		SegmentID: segmentID,
+		Type:      domain.SegmentType(parts[5]),
+		Extension: extension,
+		Version:   version,
+	}, true
+}
+
+// currentSegmentObjects keeps the objects that make up a segment's current
+// media: the media itself and its attachments.
+func currentSegmentObjects(objects []domain.MediaObject, segment domain.Segment) []domain.MediaObject {
+	current := make([]domain.MediaObject, 0)
+	hasMedia := false
+	for _, object := range objects {
+		if object.Version != 0 || object.SegmentID != segment.ID || object.Type != segment.Type {
+			continue
+		}
+		current = append(current, object)
+		hasMedia = hasMedia || object.Extension == ""
+	}
+	if !hasMedia {
+		return nil
+	}
+
+	return current
+}
+
+// storedSegmentMedia collects the objects a store holds for one segment: the
+// media itself, whose parent directory is the segment type, and attachments
+// such as subtitles stored next to it with an extension.
+type storedSegmentMedia struct {
+	segmentID       string
+	version         int
+	mediaPath       string
+	segmentType     domain.SegmentType
+	attachmentPaths map[string]string
+}
+
+func newStoredSegmentMedia(segmentID string, version int) *storedSegmentMedia {
+	return &storedSegmentMedia{
+		segmentID:       segmentID,
+		version:         version,
+		attachmentPaths: make(map[string]string),
+	}
+}
+
+func (m *storedSegmentMedia) add(itemPath string) {
+	object, ok := parseMediaObject(itemPath)
+	if !ok || object.SegmentID != m.segmentID || object.Version != m.version {
+		return
+	}
+	if object.Extension == "" {
+		m.mediaPath = itemPath
+		m.segmentType = object.Type
+		return
+	}
+	m.attachmentPaths[object.Extension] = itemPath
+}
+
+func (m *storedSegmentMedia) links(storyID string, url func(itemPath string) (string, time.Time, error)) (domain.MediaLinks, error) {
+	if m.mediaPath == "" {
+		return domain.MediaLinks{}, fmt.Errorf("%w: media for segment %s", domain.ErrNotFound, m.segmentID)
+	}
+
+	mediaUrl, expiresAt, err := url(m.mediaPath)
+	if err != nil {
+		return domain.MediaLinks{}, err
+	}
+	links := domain.MediaLinks{
+		StoryID:   storyID,
+		SegmentID: m.segmentID,
+		Type:      m.segmentType,
+		Version:   m.version,
+		URL:       mediaUrl,
+	}
+	if !expiresAt.IsZero() {
+		links.ExpiresAt = &expiresAt
+	}
+
+	for extension, itemPath := range m.attachmentPaths {
+		attachmentUrl, _, err := url(itemPath)
+		if err != nil {
+			return domain.MediaLinks{}, err
+		}
+		switch extension {
+		case "vtt":
+			links.VttURL = attachmentUrl
+		case "srt":
+			links.SrtURL = attachmentUrl
+		}
+	}
+
+	return links, nil
+}
@@ -0,0 +1,108 @@
+package adapters
+
+import (
+	"fmt"
+	"generate-script-lambda/config"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudfront/sign"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MediaUrlStrategy turns a stored object key into a URL clients can fetch.
+// A zero expiry means the URL does not expire.
+type MediaUrlStrategy interface {
+	URL(itemPath string) (string, time.Time, error)
+}
+
+func NewMediaUrlStrategy(s3Svc *s3.S3, s3Config *config.S3Config, mediaUrlConfig *config.MediaUrlConfig) (MediaUrlStrategy, error) {
+	expiry := time.Duration(mediaUrlConfig.ExpiryMinutes) * time.Minute
+
+	switch mediaUrlConfig.Strategy {
+	case config.PresignedMediaUrlStrategy:
+		return &presignedUrlStrategy{s3Svc: s3Svc, bucketName: s3Config.BucketName, expiry: expiry}, nil
+	case config.CdnMediaUrlStrategy:
+		return &cdnUrlStrategy{baseUrl: mediaUrlConfig.CdnBaseUrl}, nil
+	case config.CloudFrontMediaUrlStrategy:
+		privateKey, err := sign.LoadPEMPrivKeyFile(mediaUrlConfig.CloudFrontPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cloudfront private key: %w", err)
+		}
+		return &cloudFrontUrlStrategy{
+			cdn:    cdnUrlStrategy{baseUrl: mediaUrlConfig.CdnBaseUrl},
+			signer: sign.NewURLSigner(mediaUrlConfig.CloudFrontKeyPairId, privateKey),
+			expiry: expiry,
+		}, nil
+	default:
+		if s3Config.Endpoint != "" {
+			return &cdnUrlStrategy{baseUrl: strings.TrimSuffix(s3Config.Endpoint, "/") + "/" + s3Config.BucketName}, nil
+		}
+		return &publicUrlStrategy{bucketName: s3Config.BucketName, region: s3Config.Region}, nil
+	}
+}
+
+type publicUrlStrategy struct {
+	bucketName string
+	region     string
+}
+
+func (p *publicUrlStrategy) URL(itemPath string) (string, time.Time, error) {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", p.bucketName, p.region, itemPath), time.Time{}, nil
+}
+
+type presignedUrlStrategy struct {
+	s3Svc      *s3.S3
+	bucketName string
+	expiry     time.Duration
+}
+
+func (p *presignedUrlStrategy) URL(itemPath string) (string, time.Time, error) {
+	req, _ := p.s3Svc.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(p.bucketName),
+		Key:    aws.String(itemPath),
+	})
+
+	expiresAt := time.Now().Add(p.expiry)
+	presignedUrl, err := req.Presign(p.expiry)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return presignedUrl, expiresAt, nil
+}
+
+type cdnUrlStrategy struct {
+	baseUrl string
+}
+
+func (c *cdnUrlStrategy) URL(itemPath string) (string, time.Time, error) {
+	segments := strings.Split(itemPath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.TrimSuffix(c.baseUrl, "/") + "/" + strings.Join(segments, "/"), time.Time{}, nil
+}
+
+type cloudFrontUrlStrategy struct {
+	cdn    cdnUrlStrategy
+	signer *sign.URLSigner
+	expiry time.Duration
+}
+
+func (c *cloudFrontUrlStrategy) URL(itemPath string) (string, time.Time, error) {
+	unsignedUrl, _, err := c.cdn.URL(itemPath)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(c.expiry)
+	signedUrl, err := c.signer.Sign(unsignedUrl, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signedUrl, expiresAt, nil
+}
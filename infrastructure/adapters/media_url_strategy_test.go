@@ -0,0 +1,136 @@
+package adapters
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"generate-script-lambda/config"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestPrivateKey(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal("Failed to generate key:", err)
+	}
+	path := filepath.Join(t.TempDir(), "cloudfront.pem")
+	content := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err = os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal("Failed to write key:", err)
+	}
+
+	return path
+}
+
+func TestMediaUrlStrategy_URL(t *testing.T) {
+	s3Svc := s3.New(session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("eu-west-1"),
+		Credentials: credentials.NewStaticCredentials("AKIDEXAMPLE", "secret", ""),
+	})))
+	s3Config := &config.S3Config{BucketName: "stories", Region: "eu-west-1"}
+	keyFile := writeTestPrivateKey(t)
+	itemPath := "user/user-1/story/story-1/segments/audio/audio-1"
+
+	tests := []struct {
+		name      string
+		itemPath  string
+		s3Config  *config.S3Config
+		urlConfig config.MediaUrlConfig
+		prefix    string
+		query     []string
+		expires   bool
+	}{
+		{
+			name:      "public",
+			urlConfig: config.MediaUrlConfig{Strategy: config.PublicMediaUrlStrategy},
+			prefix:    "https://stories.s3.eu-west-1.amazonaws.com/user/user-1/story/",
+		},
+		{
+			name:      "public behind a custom endpoint",
+			s3Config:  &config.S3Config{BucketName: "stories", Endpoint: "http://localhost:9000/"},
+			urlConfig: config.MediaUrlConfig{Strategy: config.PublicMediaUrlStrategy},
+			prefix:    "http://localhost:9000/stories/user/user-1/story/",
+		},
+		{
+			name:      "presigned",
+			urlConfig: config.MediaUrlConfig{Strategy: config.PresignedMediaUrlStrategy, ExpiryMinutes: 15},
+			prefix:    "https://stories.s3.eu-west-1.amazonaws.com/user/user-1/story/",
+			query:     []string{"X-Amz-Signature", "X-Amz-Credential"},
+			expires:   true,
+		},
+		{
+			name:      "cdn",
+			itemPath:  "user/user 1/story/story-1/segments/audio/audio-1",
+			urlConfig: config.MediaUrlConfig{Strategy: config.CdnMediaUrlStrategy, CdnBaseUrl: "https://media.example.com/"},
+			prefix:    "https://media.example.com/user/user%201/story/story-1/segments/audio/audio-1",
+		},
+		{
+			name: "cloudfront",
+			urlConfig: config.MediaUrlConfig{Strategy: config.CloudFrontMediaUrlStrategy, ExpiryMinutes: 15,
+				CdnBaseUrl: "https://media.example.com", CloudFrontKeyPairId: "K2JCJMDEHXQW5F", CloudFrontPrivateKeyFile: keyFile},
+			prefix:  "https://media.example.com/user/user-1/story/story-1/segments/audio/audio-1?",
+			query:   []string{"Expires", "Signature", "Key-Pair-Id"},
+			expires: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucketConfig := tt.s3Config
+			if bucketConfig == nil {
+				bucketConfig = s3Config
+			}
+			strategy, err := NewMediaUrlStrategy(s3Svc, bucketConfig, &tt.urlConfig)
+			if err != nil {
+				t.Fatal("Failed to create strategy:", err)
+			}
+
+			path := tt.itemPath
+			if path == "" {
+				path = itemPath
+			}
+			mediaUrl, expiresAt, err := strategy.URL(path)
+			if err != nil {
+				t.Fatal("Failed to build media url:", err)
+			}
+			if !strings.HasPrefix(mediaUrl, tt.prefix) {
+				t.Errorf("expected %s to start with %s", mediaUrl, tt.prefix)
+			}
+			parsed, err := url.Parse(mediaUrl)
+			if err != nil {
+				t.Fatal("Failed to parse media url:", err)
+			}
+			for _, key := range tt.query {
+				if parsed.Query().Get(key) == "" {
+					t.Errorf("expected %s to carry %s", mediaUrl, key)
+				}
+			}
+
+			if !tt.expires && !expiresAt.IsZero() {
+				t.Errorf("expected a url that does not expire, got %v", expiresAt)
+			}
+			if tt.expires {
+				if remaining := time.Until(expiresAt); remaining <= 14*time.Minute || remaining > 15*time.Minute {
+					t.Errorf("expected the url to expire in 15 minutes, got %v", remaining)
+				}
+			}
+		})
+	}
+
+	_, err := NewMediaUrlStrategy(s3Svc, s3Config, &config.MediaUrlConfig{
+		Strategy: config.CloudFrontMediaUrlStrategy, CloudFrontPrivateKeyFile: filepath.Join(t.TempDir(), "missing.pem"),
+	})
+	if err == nil {
+		t.Error("expected a missing cloudfront key to be rejected")
+	}
+}
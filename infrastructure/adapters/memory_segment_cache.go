@@ -0,0 +1,128 @@
+package adapters
+
+import (
+	"context"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryCacheEntry struct {
+	segment   cachedSegment
+	expiresAt time.Time
+}
+
+const memoryCacheSweepInterval = time.Minute
+
+type memorySegmentCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	now       func() time.Time
+	lastSweep time.Time
+	stories   map[string]map[string]memoryCacheEntry
+}
+
+// NewMemorySegmentCache keeps segments in process memory. It suits tests and
+// single-node deployments; entries expire ttl after their last write.
+func NewMemorySegmentCache(ttl time.Duration) outbound.SegmentCachePort {
+	return &memorySegmentCache{
+		ttl:     ttl,
+		now:     time.Now,
+		stories: make(map[string]map[string]memoryCacheEntry),
+	}
+}
+
+func (m *memorySegmentCache) Save(ctx context.Context, segment domain.SegmentWithMediaUrl, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(segment, userID)
+
+	return nil
+}
+
+func (m *memorySegmentCache) SaveBatch(ctx context.Context, segments []domain.SegmentWithMediaUrl, userID string) ([]domain.SegmentWithMediaUrl, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, segment := range segments {
+		m.put(segment, userID)
+	}
+
+	return nil, nil
+}
+
+func (m *memorySegmentCache) List(ctx context.Context, storyID string) ([]domain.SegmentWithMediaUrl, error) {
+	return m.find(storyID, func(cachedSegment) bool { return true }), nil
+}
+
+func (m *memorySegmentCache) Query(ctx context.Context, query outbound.SegmentQuery) ([]domain.SegmentWithMediaUrl, error) {
+	return m.find(query.StoryID, func(segment cachedSegment) bool {
+		return segment.matches(query)
+	}), nil
+}
+
+func (m *memorySegmentCache) put(segment domain.SegmentWithMediaUrl, userID string) {
+	m.sweep()
+
+	story, ok := m.stories[segment.StoryID]
+	if !ok {
+		story = make(map[string]memoryCacheEntry)
+		m.stories[segment.StoryID] = story
+	}
+	story[segment.ID] = memoryCacheEntry{
+		segment:   newCachedSegment(segment, userID),
+		expiresAt: m.now().Add(m.ttl),
+	}
+}
+
+// sweep drops expired entries of every story, at most once per sweep
+// interval, so stories nobody reads again do not pile up.
+func (m *memorySegmentCache) sweep() {
+	now := m.now()
+	if now.Sub(m.lastSweep) < memoryCacheSweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for storyID, story := range m.stories {
+		for segmentID, entry := range story {
+			if !entry.expiresAt.After(now) {
+				delete(story, segmentID)
+			}
+		}
+		if len(story) == 0 {
+			delete(m.stories, storyID)
+		}
+	}
+}
+
+func (m *memorySegmentCache) find(storyID string, match func(cachedSegment) bool) []domain.SegmentWithMediaUrl {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	story := m.stories[storyID]
+	entries := make([]cachedSegment, 0, len(story))
+	for _, entry := range story {
+		if entry.expiresAt.After(now) && match(entry.segment) {
+			entries = append(entries, entry.segment)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Ordinal != entries[j].Ordinal {
+			return entries[i].Ordinal < entries[j].Ordinal
+		}
+		return entries[i].SegmentID < entries[j].SegmentID
+	})
+
+	segments := make([]domain.SegmentWithMediaUrl, 0, len(entries))
+	for _, entry := range entries {
+		segments = append(segments, entry.toSegment())
+	}
+
+	return segments
+}
@@ -0,0 +1,138 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const memoryMediaUrlPrefix = "memory://"
+
+type memorySegmentMediaStore struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+func NewMemorySegmentMediaStore() outbound.SegmentMediaStorePort {
+	return &memorySegmentMediaStore{
+		objects: make(map[string][]byte),
+	}
+}
+
+func (m *memorySegmentMediaStore) Save(ctx context.Context, segment domain.SegmentWithMedia, userID string) (string, error) {
+	return m.put(mediaItemPath(segment.Segment, userID), segment.MediaContent), nil
+}
+
+func (m *memorySegmentMediaStore) SaveStream(ctx context.Context, segment domain.SegmentWithMedia, content io.Reader, userID string) (string, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return "", err
+	}
+
+	return m.put(mediaItemPath(segment.Segment, userID), data), nil
+}
+
+func (m *memorySegmentMediaStore) SaveAttachment(ctx context.Context, segment domain.Segment, extension string, content []byte, userID string) (string, error) {
+	return m.put(mediaItemPath(segment, userID)+"."+extension, content), nil
+}
+
+func (m *memorySegmentMediaStore) FindMedia(ctx context.Context, storyID string, segmentID string, userID string) (domain.MediaLinks, error) {
+	return m.find(mediaStoryPath(storyID, userID), storyID, newStoredSegmentMedia(segmentID, 0))
+}
+
+func (m *memorySegmentMediaStore) FindMediaVersion(ctx context.Context, storyID string, segmentID string, version int,
+	userID string) (domain.MediaLinks, error) {
+	return m.find(mediaVersionPath(storyID, userID, version), storyID, newStoredSegmentMedia(segmentID, version))
+}
+
+func (m *memorySegmentMediaStore) find(prefix string, storyID string, stored *storedSegmentMedia) (domain.MediaLinks, error) {
+	m.mu.RLock()
+	itemPaths := make([]string, 0)
+	for itemPath := range m.objects {
+		if strings.HasPrefix(itemPath, prefix) {
+			itemPaths = append(itemPaths, itemPath)
+		}
+	}
+	m.mu.RUnlock()
+	sort.Strings(itemPaths)
+
+	for _, itemPath := range itemPaths {
+		stored.add(itemPath)
+	}
+
+	return stored.links(storyID, m.url)
+}
+
+func (m *memorySegmentMediaStore) ArchiveMedia(ctx context.Context, segment domain.Segment, userID string) error {
+	objects, err := m.ListMedia(ctx, segment.StoryID, userID)
+	if err != nil {
+		return err
+	}
+	current := currentSegmentObjects(objects, segment)
+	if current == nil {
+		return fmt.Errorf("%w: media for segment %s", domain.ErrNotFound, segment.ID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, object := range current {
+		archived := object
+		archived.Version = segment.CurrentVersion()
+		m.objects[mediaObjectPath(archived, userID)] = m.objects[mediaObjectPath(object, userID)]
+	}
+
+	return nil
+}
+
+func (m *memorySegmentMediaStore) ListMedia(ctx context.Context, storyID string, userID string) ([]domain.MediaObject, error) {
+	prefix := mediaStoryPath(storyID, userID)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	objects := make([]domain.MediaObject, 0)
+	for itemPath := range m.objects {
+		if !strings.HasPrefix(itemPath, prefix) {
+			continue
+		}
+		if object, ok := parseMediaObject(itemPath); ok {
+			objects = append(objects, object)
+		}
+	}
+
+	return objects, nil
+}
+
+func (m *memorySegmentMediaStore) OpenMedia(ctx context.Context, object domain.MediaObject, userID string) (io.ReadCloser, error) {
+	m.mu.RLock()
+	content, ok := m.objects[mediaObjectPath(object, userID)]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: media for segment %s", domain.ErrNotFound, object.SegmentID)
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (m *memorySegmentMediaStore) put(itemPath string, content []byte) string {
+	stored := make([]byte, len(content))
+	copy(stored, content)
+
+	m.mu.Lock()
+	m.objects[itemPath] = stored
+	m.mu.Unlock()
+
+	url, _, _ := m.url(itemPath)
+	return url
+}
+
+func (m *memorySegmentMediaStore) url(itemPath string) (string, time.Time, error) {
+	return memoryMediaUrlPrefix + itemPath, time.Time{}, nil
+}
@@ -0,0 +1,120 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"net/http"
+)
+
+type OpenAiSpeechRequest struct {
+	Model          string  `json:"model"`
+	Input          string  `json:"input"`
+	Voice          string  `json:"voice"`
+	ResponseFormat string  `json:"response_format"`
+	Speed          float64 `json:"speed,omitempty"`
+}
+
+var openAiOutputFormats = map[outbound.AudioFormat]string{
+	outbound.AudioFormatMP3:  "mp3",
+	outbound.AudioFormatWAV:  "wav",
+	outbound.AudioFormatOpus: "opus",
+	outbound.AudioFormatPCM:  "pcm",
+}
+
+type openAiAudioGenerator struct {
+	ContentFetcher
+	logger          outbound.LoggerPort
+	openAiTtsConfig *config.OpenAiTtsConfig
+}
+
+func NewOpenAiAudioGenerator(contentFetcher ContentFetcher, openAiTtsConfig *config.OpenAiTtsConfig, logger outbound.LoggerPort) outbound.AudioGeneratorPort {
+	return &openAiAudioGenerator{
+		ContentFetcher:  contentFetcher,
+		logger:          logger,
+		openAiTtsConfig: openAiTtsConfig,
+	}
+}
+
+func (o *openAiAudioGenerator) Generate(ctx context.Context, generateAudioParams outbound.GenerateAudioParams) (outbound.GeneratedAudio, error) {
+	format := o.getOutputFormat(generateAudioParams)
+	req, err := o.getRequest(ctx, generateAudioParams, format)
+	if err != nil {
+		return outbound.GeneratedAudio{}, err
+	}
+
+	content, err := o.FetchContent(req)
+	if err != nil {
+		return outbound.GeneratedAudio{}, err
+	}
+
+	return outbound.GeneratedAudio{
+		Content: content,
+		Format:  format,
+	}, nil
+}
+
+func (o *openAiAudioGenerator) GenerateStream(ctx context.Context, generateAudioParams outbound.GenerateAudioParams) (outbound.AudioStream, error) {
+	format := o.getOutputFormat(generateAudioParams)
+	req, err := o.getRequest(ctx, generateAudioParams, format)
+	if err != nil {
+		return outbound.AudioStream{}, err
+	}
+
+	content, err := o.StreamContent(req)
+	if err != nil {
+		return outbound.AudioStream{}, err
+	}
+
+	return outbound.AudioStream{
+		Content: content,
+		Format:  format,
+	}, nil
+}
+
+func (o *openAiAudioGenerator) getOutputFormat(generateAudioParams outbound.GenerateAudioParams) outbound.AudioFormat {
+	if generateAudioParams.OutputFormat == "" {
+		return outbound.AudioFormatMP3
+	}
+	return generateAudioParams.OutputFormat
+}
+
+func (o *openAiAudioGenerator) getRequest(ctx context.Context, generateAudioParams outbound.GenerateAudioParams, format outbound.AudioFormat) (*http.Request, error) {
+	responseFormat, ok := openAiOutputFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("openai tts does not support output format %s", format)
+	}
+
+	reqBody := OpenAiSpeechRequest{
+		Model:          o.openAiTtsConfig.Model,
+		Input:          renderPlainText(generateAudioParams),
+		Voice:          generateAudioParams.VoiceID,
+		ResponseFormat: responseFormat,
+	}
+	if generateAudioParams.VoiceSettings != nil {
+		reqBody.Speed = generateAudioParams.VoiceSettings.Speed
+	}
+
+	jsonPayload, err := json.Marshal(reqBody)
+	if err != nil {
+		o.logger.Error(err, "Failed to marshal the request body")
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", o.openAiTtsConfig.ApiUrl, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		o.logger.ErrorWithFields(err, "Failed to create the HTTP request", map[string]interface{}{
+			"action": "Creating HTTP Request",
+			"URL":    o.openAiTtsConfig.ApiUrl,
+		})
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+o.openAiTtsConfig.ApiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
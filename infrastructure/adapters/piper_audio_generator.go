@@ -0,0 +1,100 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"net/http"
+)
+
+type PiperRequest struct {
+	Text        string  `json:"text"`
+	Voice       string  `json:"voice,omitempty"`
+	LengthScale float64 `json:"length_scale,omitempty"`
+}
+
+type piperAudioGenerator struct {
+	ContentFetcher
+	logger      outbound.LoggerPort
+	piperConfig *config.PiperConfig
+}
+
+func NewPiperAudioGenerator(contentFetcher ContentFetcher, piperConfig *config.PiperConfig, logger outbound.LoggerPort) outbound.AudioGeneratorPort {
+	return &piperAudioGenerator{
+		ContentFetcher: contentFetcher,
+		logger:         logger,
+		piperConfig:    piperConfig,
+	}
+}
+
+func (p *piperAudioGenerator) Generate(ctx context.Context, generateAudioParams outbound.GenerateAudioParams) (outbound.GeneratedAudio, error) {
+	req, err := p.getRequest(ctx, generateAudioParams)
+	if err != nil {
+		return outbound.GeneratedAudio{}, err
+	}
+
+	content, err := p.FetchContent(req)
+	if err != nil {
+		return outbound.GeneratedAudio{}, err
+	}
+
+	return outbound.GeneratedAudio{
+		Content: content,
+		Format:  outbound.AudioFormatWAV,
+	}, nil
+}
+
+func (p *piperAudioGenerator) GenerateStream(ctx context.Context, generateAudioParams outbound.GenerateAudioParams) (outbound.AudioStream, error) {
+	req, err := p.getRequest(ctx, generateAudioParams)
+	if err != nil {
+		return outbound.AudioStream{}, err
+	}
+
+	content, err := p.StreamContent(req)
+	if err != nil {
+		return outbound.AudioStream{}, err
+	}
+
+	return outbound.AudioStream{
+		Content: content,
+		Format:  outbound.AudioFormatWAV,
+	}, nil
+}
+
+func (p *piperAudioGenerator) getRequest(ctx context.Context, generateAudioParams outbound.GenerateAudioParams) (*http.Request, error) {
+	if generateAudioParams.OutputFormat != "" && generateAudioParams.OutputFormat != outbound.AudioFormatWAV {
+		p.logger.DebugWithFields("Piper only produces WAV, ignoring requested output format", map[string]interface{}{
+			"format": generateAudioParams.OutputFormat,
+		})
+	}
+
+	reqBody := PiperRequest{
+		Text:  renderPlainText(generateAudioParams),
+		Voice: generateAudioParams.VoiceID,
+	}
+	if generateAudioParams.VoiceSettings != nil && generateAudioParams.VoiceSettings.Speed > 0 {
+		reqBody.LengthScale = 1 / generateAudioParams.VoiceSettings.Speed
+	}
+
+	jsonPayload, err := json.Marshal(reqBody)
+	if err != nil {
+		p.logger.Error(err, "Failed to marshal the request body")
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.piperConfig.ApiUrl, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		p.logger.ErrorWithFields(err, "Failed to create the HTTP request", map[string]interface{}{
+			"action": "Creating HTTP Request",
+			"URL":    p.piperConfig.ApiUrl,
+		})
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "audio/wav")
+
+	return req, nil
+}
@@ -0,0 +1,137 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+type redisSegmentCache struct {
+	logger    outbound.LoggerPort
+	client    redis.UniversalClient
+	keyPrefix string
+	ttl       time.Duration
+}
+
+func NewRedisClient(cacheConfig *config.SegmentCacheConfig) (redis.UniversalClient, error) {
+	options, err := redis.ParseURL(cacheConfig.RedisUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	return redis.NewClient(options), nil
+}
+
+// NewRedisSegmentCache keeps every story under two keys: a sorted set of
+// segment ids scored by ordinal and a hash holding the segments. Both expire
+// ttl after the story's last write.
+func NewRedisSegmentCache(logger outbound.LoggerPort, client redis.UniversalClient, cacheConfig *config.SegmentCacheConfig) outbound.SegmentCachePort {
+	return &redisSegmentCache{
+		logger:    logger,
+		client:    client,
+		keyPrefix: cacheConfig.KeyPrefix,
+		ttl:       time.Duration(cacheConfig.TtlMinutes) * time.Minute,
+	}
+}
+
+func (r *redisSegmentCache) Save(ctx context.Context, segment domain.SegmentWithMediaUrl, userID string) error {
+	_, err := r.SaveBatch(ctx, []domain.SegmentWithMediaUrl{segment}, userID)
+
+	return err
+}
+
+func (r *redisSegmentCache) SaveBatch(ctx context.Context, segments []domain.SegmentWithMediaUrl, userID string) ([]domain.SegmentWithMediaUrl, error) {
+	pipe := r.client.TxPipeline()
+	touched := make(map[string]bool)
+	for _, segment := range segments {
+		value, err := json.Marshal(newCachedSegment(segment, userID))
+		if err != nil {
+			r.logger.ErrorWithFields(err, "Failed to marshal segment", map[string]interface{}{
+				"segment_id": segment.ID,
+			})
+			return nil, err
+		}
+		pipe.ZAdd(ctx, r.indexKey(segment.StoryID), redis.Z{Score: float64(segment.Ordinal), Member: segment.ID})
+		pipe.HSet(ctx, r.dataKey(segment.StoryID), segment.ID, value)
+		touched[segment.StoryID] = true
+	}
+	for storyID := range touched {
+		pipe.Expire(ctx, r.indexKey(storyID), r.ttl)
+		pipe.Expire(ctx, r.dataKey(storyID), r.ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.logger.ErrorWithFields(err, "Failed to save segments to redis", map[string]interface{}{
+			"count": len(segments),
+		})
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (r *redisSegmentCache) List(ctx context.Context, storyID string) ([]domain.SegmentWithMediaUrl, error) {
+	return r.find(ctx, storyID, func(cachedSegment) bool { return true })
+}
+
+func (r *redisSegmentCache) Query(ctx context.Context, query outbound.SegmentQuery) ([]domain.SegmentWithMediaUrl, error) {
+	return r.find(ctx, query.StoryID, func(segment cachedSegment) bool {
+		return segment.matches(query)
+	})
+}
+
+func (r *redisSegmentCache) find(ctx context.Context, storyID string, match func(cachedSegment) bool) ([]domain.SegmentWithMediaUrl, error) {
+	segmentIDs, err := r.client.ZRange(ctx, r.indexKey(storyID), 0, -1).Result()
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to read segment index from redis", map[string]interface{}{
+			"story_id": storyID,
+		})
+		return nil, err
+	}
+	if len(segmentIDs) == 0 {
+		return make([]domain.SegmentWithMediaUrl, 0), nil
+	}
+
+	values, err := r.client.HMGet(ctx, r.dataKey(storyID), segmentIDs...).Result()
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to read segments from redis", map[string]interface{}{
+			"story_id": storyID,
+		})
+		return nil, err
+	}
+
+	segments := make([]domain.SegmentWithMediaUrl, 0, len(values))
+	for _, value := range values {
+		encoded, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var segment cachedSegment
+		if err = json.Unmarshal([]byte(encoded), &segment); err != nil {
+			r.logger.ErrorWithFields(err, "Failed to unmarshal segment", map[string]interface{}{
+				"story_id": storyID,
+			})
+			return nil, err
+		}
+		if match(segment) {
+			segments = append(segments, segment.toSegment())
+		}
+	}
+
+	return segments, nil
+}
+
+// The hash tag keeps both keys of a story on the same cluster slot, which
+// the transactional pipeline in SaveBatch relies on.
+func (r *redisSegmentCache) indexKey(storyID string) string {
+	return fmt.Sprintf("%s:{%s}:index", r.keyPrefix, storyID)
+}
+
+func (r *redisSegmentCache) dataKey(storyID string) string {
+	return fmt.Sprintf("%s:{%s}:data", r.keyPrefix, storyID)
+}
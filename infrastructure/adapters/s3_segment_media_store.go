@@ -1,38 +1,110 @@
 package adapters
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"generate-script-lambda/application/ports/outbound"
 	"generate-script-lambda/config"
 	"generate-script-lambda/domain"
+	"generate-script-lambda/media_utils"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"strings"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"io"
+	"net/url"
+	"strconv"
 )
 
+var attachmentContentTypes = map[string]string{
+	"vtt":  "text/vtt; charset=utf-8",
+	"srt":  "application/x-subrip; charset=utf-8",
+	"html": "text/html; charset=utf-8",
+}
+
+// pageCacheControl applies to published pages whatever S3_CACHE_CONTROL says:
+// a republished storybook must show up on the next load.
+const pageCacheControl = "no-cache"
+
 type s3SegmentMediaStore struct {
-	logger   outbound.LoggerPort
-	s3Svc    *s3.S3
-	s3Config *config.S3Config
+	logger      outbound.LoggerPort
+	s3Svc       *s3.S3
+	uploader    *s3manager.Uploader
+	s3Config    *config.S3Config
+	urlStrategy MediaUrlStrategy
 }
 
-func NewS3SegmentMediaStore(s3Svc *s3.S3, s3Config *config.S3Config, logger outbound.LoggerPort) outbound.SegmentMediaStorePort {
+// NewS3Client points the client at S3_ENDPOINT when one is configured so the
+// store also works against S3-compatible servers such as MinIO.
+func NewS3Client(sess *session.Session, s3Config *config.S3Config) *s3.S3 {
+	awsConfig := aws.NewConfig().WithRegion(s3Config.Region)
+	if s3Config.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(s3Config.Endpoint)
+	}
+	if s3Config.ForcePathStyle {
+		awsConfig = awsConfig.WithS3ForcePathStyle(true)
+	}
+
+	return s3.New(sess, awsConfig)
+}
+
+func NewS3SegmentMediaStore(s3Svc *s3.S3, s3Config *config.S3Config, urlStrategy MediaUrlStrategy, logger outbound.LoggerPort) outbound.SegmentMediaStorePort {
 	return &s3SegmentMediaStore{
-		logger:   logger,
-		s3Svc:    s3Svc,
-		s3Config: s3Config,
+		logger:      logger,
+		s3Svc:       s3Svc,
+		uploader:    s3manager.NewUploaderWithClient(s3Svc),
+		s3Config:    s3Config,
+		urlStrategy: urlStrategy,
 	}
 }
 
 func (s *s3SegmentMediaStore) Save(ctx context.Context, segment domain.SegmentWithMedia, userID string) (string, error) {
-	itemPath := s.getS3ItemPath(segment, userID)
+	itemPath := mediaItemPath(segment.Segment, userID)
+	contentType := segment.MimeType
+	if contentType == "" {
+		contentType = media_utils.DetectContentType(segment.MediaContent)
+	}
+
+	if len(segment.MediaContent) > s.s3Config.MultipartThresholdMB*1024*1024 {
+		return s.upload(ctx, itemPath, bytes.NewReader(segment.MediaContent), contentType, s.s3Config.CacheControl, s.getMetadata(segment))
+	}
+
+	return s.put(ctx, itemPath, segment.MediaContent, contentType, s.s3Config.CacheControl, s.getMetadata(segment))
+}
+
+func (s *s3SegmentMediaStore) SaveStream(ctx context.Context, segment domain.SegmentWithMedia, content io.Reader, userID string) (string, error) {
+	return s.upload(ctx, mediaItemPath(segment.Segment, userID), content, segment.MimeType, s.s3Config.CacheControl,
+		s.getMetadata(segment))
+}
+
+func (s *s3SegmentMediaStore) SaveAttachment(ctx context.Context, segment domain.Segment, extension string, content []byte, userID string) (string, error) {
+	contentType, ok := attachmentContentTypes[extension]
+	if !ok {
+		contentType = media_utils.DetectContentType(content)
+	}
 
+	cacheControl := s.s3Config.CacheControl
+	if extension == "html" {
+		cacheControl = pageCacheControl
+	}
+
+	return s.put(ctx, mediaItemPath(segment, userID)+"."+extension, content, contentType, cacheControl,
+		s.getMetadata(domain.SegmentWithMedia{Segment: segment}))
+}
+
+func (s *s3SegmentMediaStore) put(ctx context.Context, itemPath string, content []byte, contentType string, cacheControl string,
+	metadata map[string]*string) (string, error) {
 	putInput := &s3.PutObjectInput{
 		Bucket:        aws.String(s.s3Config.BucketName),
 		Key:           aws.String(itemPath),
-		Body:          strings.NewReader(string(segment.MediaContent)),
-		ContentLength: aws.Int64(int64(len(segment.MediaContent))),
+		Body:          bytes.NewReader(content),
+		ContentLength: aws.Int64(int64(len(content))),
+		ContentType:   aws.String(contentType),
+		CacheControl:  aws.String(cacheControl),
+		Metadata:      metadata,
 	}
 
 	_, err := s.s3Svc.PutObjectWithContext(ctx, putInput)
@@ -41,11 +113,162 @@ func (s *s3SegmentMediaStore) Save(ctx context.Context, segment domain.SegmentWi
 		return "", err
 	}
 
-	s3Url := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.s3Config.BucketName, s.s3Config.Region, itemPath)
+	return s.getUrl(itemPath)
+}
+
+func (s *s3SegmentMediaStore) upload(ctx context.Context, itemPath string, content io.Reader, contentType string, cacheControl string,
+	metadata map[string]*string) (string, error) {
+	uploadInput := &s3manager.UploadInput{
+		Bucket:       aws.String(s.s3Config.BucketName),
+		Key:          aws.String(itemPath),
+		Body:         content,
+		CacheControl: aws.String(cacheControl),
+		Metadata:     metadata,
+	}
+	if contentType != "" {
+		uploadInput.ContentType = aws.String(contentType)
+	}
+
+	_, err := s.uploader.UploadWithContext(ctx, uploadInput)
+	if err != nil {
+		s.logger.Error(err, "Failed to stream object to S3")
+		return "", err
+	}
+
+	return s.getUrl(itemPath)
+}
+
+func (s *s3SegmentMediaStore) getMetadata(segment domain.SegmentWithMedia) map[string]*string {
+	metadata := map[string]*string{
+		"story-id":     aws.String(segment.StoryID),
+		"segment-id":   aws.String(segment.ID),
+		"segment-type": aws.String(string(segment.Type)),
+		"ordinal":      aws.String(strconv.Itoa(segment.Ordinal)),
+	}
+	if segment.Provider != "" {
+		metadata["provider"] = aws.String(segment.Provider)
+	}
+
+	return metadata
+}
+
+// FindMedia lists the story's objects to find the segment's media and
+// subtitle files and issues fresh URLs for them. Listing under the caller's
+// own prefix also keeps users from reaching other users' media.
+func (s *s3SegmentMediaStore) FindMedia(ctx context.Context, storyID string, segmentID string, userID string) (domain.MediaLinks, error) {
+	return s.find(ctx, mediaStoryPath(storyID, userID), storyID, newStoredSegmentMedia(segmentID, 0))
+}
 
-	return s3Url, nil
+func (s *s3SegmentMediaStore) FindMediaVersion(ctx context.Context, storyID string, segmentID string, version int,
+	userID string) (domain.MediaLinks, error) {
+	return s.find(ctx, mediaVersionPath(storyID, userID, version), storyID, newStoredSegmentMedia(segmentID, version))
 }
 
-func (s *s3SegmentMediaStore) getS3ItemPath(segment domain.SegmentWithMedia, userID string) string {
-	return fmt.Sprintf("user/%s/story/%s/segments/%s/%s", userID, segment.StoryID, segment.Type, segment.ID)
+func (s *s3SegmentMediaStore) find(ctx context.Context, prefix string, storyID string, stored *storedSegmentMedia) (domain.MediaLinks, error) {
+	err := s.s3Svc.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.s3Config.BucketName),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			stored.add(aws.StringValue(object.Key))
+		}
+		return true
+	})
+	if err != nil {
+		s.logger.ErrorWithFields(err, "Failed to list story objects", map[string]interface{}{
+			"story_id":   storyID,
+			"segment_id": stored.segmentID,
+		})
+		return domain.MediaLinks{}, err
+	}
+
+	return stored.links(storyID, s.urlStrategy.URL)
+}
+
+// ArchiveMedia copies objects server side, keeping their content type,
+// cache control and metadata.
+func (s *s3SegmentMediaStore) ArchiveMedia(ctx context.Context, segment domain.Segment, userID string) error {
+	objects, err := s.ListMedia(ctx, segment.StoryID, userID)
+	if err != nil {
+		return err
+	}
+	current := currentSegmentObjects(objects, segment)
+	if current == nil {
+		return fmt.Errorf("%w: media for segment %s", domain.ErrNotFound, segment.ID)
+	}
+
+	for _, object := range current {
+		archived := object
+		archived.Version = segment.CurrentVersion()
+		source := url.URL{Path: s.s3Config.BucketName + "/" + mediaObjectPath(object, userID)}
+		_, err := s.s3Svc.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+			Bucket:     aws.String(s.s3Config.BucketName),
+			Key:        aws.String(mediaObjectPath(archived, userID)),
+			CopySource: aws.String(source.EscapedPath()),
+		})
+		if err != nil {
+			s.logger.ErrorWithFields(err, "Failed to archive object in S3", map[string]interface{}{
+				"story_id":   segment.StoryID,
+				"segment_id": segment.ID,
+			})
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *s3SegmentMediaStore) ListMedia(ctx context.Context, storyID string, userID string) ([]domain.MediaObject, error) {
+	objects := make([]domain.MediaObject, 0)
+
+	err := s.s3Svc.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.s3Config.BucketName),
+		Prefix: aws.String(mediaStoryPath(storyID, userID)),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			if mediaObject, ok := parseMediaObject(aws.StringValue(object.Key)); ok {
+				objects = append(objects, mediaObject)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		s.logger.ErrorWithFields(err, "Failed to list story objects", map[string]interface{}{
+			"story_id": storyID,
+		})
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+func (s *s3SegmentMediaStore) OpenMedia(ctx context.Context, object domain.MediaObject, userID string) (io.ReadCloser, error) {
+	res, err := s.s3Svc.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.s3Config.BucketName),
+		Key:    aws.String(mediaObjectPath(object, userID)),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, fmt.Errorf("%w: media for segment %s", domain.ErrNotFound, object.SegmentID)
+		}
+		s.logger.ErrorWithFields(err, "Failed to get object from S3", map[string]interface{}{
+			"segment_id": object.SegmentID,
+		})
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
+func (s *s3SegmentMediaStore) getUrl(itemPath string) (string, error) {
+	url, _, err := s.urlStrategy.URL(itemPath)
+	if err != nil {
+		s.logger.ErrorWithFields(err, "Failed to create media url", map[string]interface{}{
+			"item_path": itemPath,
+		})
+		return "", err
+	}
+
+	return url, nil
 }
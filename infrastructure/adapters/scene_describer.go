@@ -0,0 +1,100 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"net/http"
+	"strings"
+)
+
+// maxDescribedPassageLength keeps long chapters within the model's context;
+// the opening of a scene is what its illustration shows anyway.
+const maxDescribedPassageLength = 4000
+
+type chatGptCompletionBody struct {
+	Choices []struct {
+		Message chatGptMessage `json:"message"`
+	} `json:"choices"`
+}
+
+type sceneDescriber struct {
+	ContentFetcher
+	logger    outbound.LoggerPort
+	gptConfig *config.GptConfig
+}
+
+func NewSceneDescriber(contentFetcher ContentFetcher, gptConfig *config.GptConfig, logger outbound.LoggerPort) outbound.SceneDescriberPort {
+	return &sceneDescriber{
+		ContentFetcher: contentFetcher,
+		logger:         logger,
+		gptConfig:      gptConfig,
+	}
+}
+
+func (s *sceneDescriber) Describe(ctx context.Context, passage string) (string, error) {
+	req, err := s.createRequest(ctx, passage)
+	if err != nil {
+		s.logger.Error(err, "Failed to create the HTTP request")
+		return "", err
+	}
+
+	rawRes, err := s.FetchContent(req)
+	if err != nil {
+		s.logger.Error(err, "Failed to fetch the scene description")
+		return "", err
+	}
+
+	var completion chatGptCompletionBody
+	err = json.Unmarshal(rawRes, &completion)
+	if err != nil {
+		s.logger.Error(err, "Failed to unmarshal the response")
+		return "", err
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("no scene description was returned")
+	}
+
+	return strings.TrimSpace(completion.Choices[0].Message.Content), nil
+}
+
+func (s *sceneDescriber) createRequest(ctx context.Context, passage string) (*http.Request, error) {
+	if len(passage) > maxDescribedPassageLength {
+		passage = strings.ToValidUTF8(passage[:maxDescribedPassageLength], "")
+	}
+
+	promptReq := chatGptRequest{
+		Model: s.gptConfig.Model,
+		Messages: []chatGptMessage{
+			{
+				Role: "system",
+				Content: "Describe the scenery of the passage the user sends for an illustration.\n" +
+					"The description:\n" +
+					"- Should not contain any names\n" +
+					"- Should be descriptive in a short manner (at most one sentence)\n" +
+					"- Should not use square brackets\n" +
+					"Answer with the description only.",
+			},
+			{Role: "user", Content: passage},
+		},
+	}
+
+	payloadBytes, err := json.Marshal(promptReq)
+	if err != nil {
+		s.logger.Error(err, "Failed to marshal the request body")
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.gptConfig.ApiUrl, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+s.gptConfig.ApiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
@@ -0,0 +1,191 @@
+package adapters
+
+import (
+	"context"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/redis/go-redis/v9"
+	"testing"
+	"time"
+)
+
+const conformanceTTL = 10 * time.Minute
+
+// segmentCacheUnderTest builds a fresh cache; expire moves the cache's
+// clock past the TTL.
+type segmentCacheUnderTest struct {
+	cache  outbound.SegmentCachePort
+	expire func()
+}
+
+func TestMemorySegmentCache_Conformance(t *testing.T) {
+	runSegmentCacheConformance(t, func(t *testing.T) segmentCacheUnderTest {
+		now := time.Now()
+		cache := NewMemorySegmentCache(conformanceTTL).(*memorySegmentCache)
+		cache.now = func() time.Time { return now }
+		return segmentCacheUnderTest{
+			cache:  cache,
+			expire: func() { now = now.Add(conformanceTTL + time.Second) },
+		}
+	})
+}
+
+func TestRedisSegmentCache_Conformance(t *testing.T) {
+	runSegmentCacheConformance(t, func(t *testing.T) segmentCacheUnderTest {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		cache := NewRedisSegmentCache(NewZerologWrapper(), client, &config.SegmentCacheConfig{
+			KeyPrefix:  "test",
+			TtlMinutes: int(conformanceTTL / time.Minute),
+		})
+		return segmentCacheUnderTest{
+			cache:  cache,
+			expire: func() { server.FastForward(conformanceTTL + time.Second) },
+		}
+	})
+}
+
+// The Dynamo cache needs a table, so only its item mapping is checked here.
+func TestDynamoSegmentItem_RoundTrip(t *testing.T) {
+	segment := domain.SegmentWithMediaUrl{
+		MediaURL: "https://media/audio-1",
+		Segment:  domain.NewSegment("Hello", domain.AudioSegmentType, "audio-1", "story-1", 3),
+	}
+	segment.Mood = domain.TenseMood
+	segment.Version = 2
+
+	cache := &dynamoCache{dynamoConfig: &config.DynamoConfig{TtlMinutes: 1}}
+	av, err := dynamodbattribute.MarshalMap(cache.newItem(segment, "user-1"))
+	if err != nil {
+		t.Fatal("Failed to marshal segment item:", err)
+	}
+	var item dynamoSegmentItem
+	if err = dynamodbattribute.UnmarshalMap(av, &item); err != nil {
+		t.Fatal("Failed to unmarshal segment item:", err)
+	}
+
+	got := item.toSegment()
+	if got.Mood != segment.Mood || got.Version != segment.Version || got.Ordinal != segment.Ordinal || got.MediaURL != segment.MediaURL {
+		t.Fatalf("segment did not round-trip: %+v", got)
+	}
+}
+
+func runSegmentCacheConformance(t *testing.T, newCache func(t *testing.T) segmentCacheUnderTest) {
+	ctx := context.Background()
+
+	audio := domain.SegmentWithMediaUrl{
+		MediaURL:  "https://media/audio-1",
+		Metadata:  &domain.MediaMetadata{MimeType: "audio/mpeg", Size: 42, Duration: 1.5},
+		Alignment: &domain.Alignment{Words: []domain.WordTiming{{Word: "Hello", Start: 0, End: 0.5}}},
+		Subtitles: domain.SubtitleURLs{VttURL: "https://media/audio-1.vtt", SrtURL: "https://media/audio-1.srt"},
+		Segment:   domain.NewSegment("Hello {img-1}", domain.AudioSegmentType, "audio-1", "story-1", 0),
+	}
+	audio.Mood = domain.CalmMood
+	image := domain.SegmentWithMediaUrl{
+		MediaURL: "https://media/img-1",
+		Segment:  domain.NewSegment("a castle", domain.ImageSegmentType, "img-1", "story-1", 0),
+	}
+	later := domain.SegmentWithMediaUrl{
+		MediaURL: "https://media/audio-2",
+		Segment:  domain.NewSegment("Goodbye", domain.AudioSegmentType, "audio-2", "story-1", 1),
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		cache := newCache(t).cache
+		if err := cache.Save(ctx, audio, "user-1"); err != nil {
+			t.Fatal("Failed to save segment:", err)
+		}
+
+		segments, err := cache.List(ctx, "story-1")
+		if err != nil {
+			t.Fatal("Failed to list segments:", err)
+		}
+		if len(segments) != 1 {
+			t.Fatalf("expected 1 segment, got %d", len(segments))
+		}
+		got := segments[0]
+		if got.ID != audio.ID || got.Text != audio.Text || got.Type != audio.Type || got.Ordinal != audio.Ordinal ||
+			got.Mood != audio.Mood || got.MediaURL != audio.MediaURL || got.Subtitles != audio.Subtitles {
+			t.Errorf("segment did not round-trip: %+v", got)
+		}
+		if got.Metadata == nil || *got.Metadata != *audio.Metadata {
+			t.Errorf("metadata did not round-trip: %+v", got.Metadata)
+		}
+		if got.Alignment == nil || len(got.Alignment.Words) != 1 || got.Alignment.Words[0] != audio.Alignment.Words[0] {
+			t.Errorf("word timings did not round-trip: %+v", got.Alignment)
+		}
+	})
+
+	t.Run("save overwrites by segment id", func(t *testing.T) {
+		cache := newCache(t).cache
+		_ = cache.Save(ctx, image, "user-1")
+		updated := image
+		updated.MediaURL = "https://media/img-1-v2"
+		_ = cache.Save(ctx, updated, "user-1")
+
+		segments, _ := cache.List(ctx, "story-1")
+		if len(segments) != 1 || segments[0].MediaURL != updated.MediaURL {
+			t.Errorf("expected the overwritten segment, got %+v", segments)
+		}
+	})
+
+	t.Run("batch save", func(t *testing.T) {
+		cache := newCache(t).cache
+		unprocessed, err := cache.SaveBatch(ctx, []domain.SegmentWithMediaUrl{audio, image, later}, "user-1")
+		if err != nil {
+			t.Fatal("Failed to save batch:", err)
+		}
+
+		segments, _ := cache.List(ctx, "story-1")
+		if len(segments)+len(unprocessed) != 3 {
+			t.Errorf("expected 3 segments stored or unprocessed, got %d and %d", len(segments), len(unprocessed))
+		}
+	})
+
+	t.Run("query by owner and type", func(t *testing.T) {
+		cache := newCache(t).cache
+		_, _ = cache.SaveBatch(ctx, []domain.SegmentWithMediaUrl{audio, image, later}, "user-1")
+		other := later
+		other.StoryID = "story-2"
+		_ = cache.Save(ctx, other, "user-2")
+
+		tests := []struct {
+			query    outbound.SegmentQuery
+			expected int
+		}{
+			{query: outbound.SegmentQuery{StoryID: "story-1", UserID: "user-1"}, expected: 3},
+			{query: outbound.SegmentQuery{StoryID: "story-1", UserID: "user-1", Type: domain.AudioSegmentType}, expected: 2},
+			{query: outbound.SegmentQuery{StoryID: "story-1", UserID: "user-1", Type: domain.ImageSegmentType}, expected: 1},
+			{query: outbound.SegmentQuery{StoryID: "story-1", UserID: "user-2"}, expected: 0},
+			{query: outbound.SegmentQuery{StoryID: "story-2", UserID: "user-2"}, expected: 1},
+			{query: outbound.SegmentQuery{StoryID: "missing", UserID: "user-1"}, expected: 0},
+		}
+		for _, tt := range tests {
+			segments, err := cache.Query(ctx, tt.query)
+			if err != nil {
+				t.Fatal("Failed to query segments:", err)
+			}
+			if len(segments) != tt.expected {
+				t.Errorf("query %+v returned %d segments, expected %d", tt.query, len(segments), tt.expected)
+			}
+		}
+	})
+
+	t.Run("segments expire", func(t *testing.T) {
+		underTest := newCache(t)
+		_ = underTest.cache.Save(ctx, audio, "user-1")
+		underTest.expire()
+
+		segments, err := underTest.cache.List(ctx, "story-1")
+		if err != nil {
+			t.Fatal("Failed to list segments:", err)
+		}
+		if len(segments) != 0 {
+			t.Errorf("expected expired segments to be gone, got %d", len(segments))
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLocalSegmentMediaStores_FindMedia(t *testing.T) {
+	filesystemStore, err := NewFilesystemSegmentMediaStore(&config.MediaStoreConfig{
+		RootDir: t.TempDir(),
+		BaseUrl: "http://localhost:8080/media/",
+	}, NewZerologWrapper())
+	if err != nil {
+		t.Fatal("Failed to create filesystem store:", err)
+	}
+
+	stores := map[string]outbound.SegmentMediaStorePort{
+		"filesystem": filesystemStore,
+		"memory":     NewMemorySegmentMediaStore(),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			segment := domain.NewSegment("Once upon a time.", domain.AudioSegmentType, "segment-1", "story-1", 0)
+
+			url, err := store.SaveStream(ctx, domain.SegmentWithMedia{Segment: segment}, strings.NewReader("audio"), "user-1")
+			if err != nil {
+				t.Fatal("Failed to save media:", err)
+			}
+			if !strings.HasSuffix(url, "user/user-1/story/story-1/segments/audio/segment-1") {
+				t.Fatalf("Unexpected media url: %s", url)
+			}
+			if _, err := store.SaveAttachment(ctx, segment, "vtt", []byte("WEBVTT"), "user-1"); err != nil {
+				t.Fatal("Failed to save attachment:", err)
+			}
+
+			links, err := store.FindMedia(ctx, "story-1", "segment-1", "user-1")
+			if err != nil {
+				t.Fatal("Failed to find media:", err)
+			}
+			if links.Type != domain.AudioSegmentType || links.URL != url || !strings.HasSuffix(links.VttURL, "segment-1.vtt") {
+				t.Fatalf("Unexpected links: %+v", links)
+			}
+
+			_, err = store.FindMedia(ctx, "story-1", "segment-1", "user-2")
+			if !errors.Is(err, domain.ErrNotFound) {
+				t.Fatalf("Expected other users' media to be hidden, got %v", err)
+			}
+
+			if err := store.ArchiveMedia(ctx, segment, "user-1"); err != nil {
+				t.Fatal("Failed to archive media:", err)
+			}
+			archived, err := store.FindMediaVersion(ctx, "story-1", "segment-1", 1, "user-1")
+			if err != nil {
+				t.Fatal("Failed to find archived media:", err)
+			}
+			if archived.Version != 1 || !strings.HasSuffix(archived.URL, "story-1/versions/1/audio/segment-1") || archived.VttURL == "" {
+				t.Fatalf("Unexpected archived links: %+v", archived)
+			}
+			objects, _ := store.ListMedia(ctx, "story-1", "user-1")
+			if len(objects) != 2 {
+				t.Fatalf("Expected archived versions to stay out of the listing, got %+v", objects)
+			}
+		})
+	}
+}
+
+func TestLocalMediaPath_RejectsTraversal(t *testing.T) {
+	root := t.TempDir()
+	for _, itemPath := range []string{"../secret", "user/../../etc/passwd", "user//story"} {
+		if _, err := LocalMediaPath(root, itemPath); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Fatalf("Expected %q to be rejected, got %v", itemPath, err)
+		}
+	}
+
+	resolved, err := LocalMediaPath(root, "user/u/story/s/segments/audio/a")
+	if err != nil || !strings.HasPrefix(resolved, root+string(os.PathSeparator)) {
+		t.Fatalf("Unexpected resolved path %q: %v", resolved, err)
+	}
+}
@@ -0,0 +1,62 @@
+package adapters
+
+import (
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"html"
+	"strings"
+	"time"
+)
+
+const maxElevenLabsBreak = 3 * time.Second
+
+// renderElevenLabsText maps speech markup onto the subset of SSML ElevenLabs
+// accepts inline: <break> and <phoneme>. Emphasis has no equivalent and is
+// spoken as plain text.
+func renderElevenLabsText(params outbound.GenerateAudioParams) string {
+	if len(params.Speech) == 0 {
+		return params.Text
+	}
+
+	var builder strings.Builder
+	for _, token := range params.Speech {
+		switch token.Type {
+		case domain.BreakSpeechToken:
+			pause := token.Pause
+			if pause > maxElevenLabsBreak {
+				pause = maxElevenLabsBreak
+			}
+			builder.WriteString(fmt.Sprintf(` <break time="%.1fs" /> `, pause.Seconds()))
+		case domain.PhonemeSpeechToken:
+			builder.WriteString(fmt.Sprintf(`<phoneme alphabet="%s" ph="%s">%s</phoneme>`,
+				html.EscapeString(token.Alphabet), html.EscapeString(token.Phoneme), html.EscapeString(token.Text)))
+		default:
+			builder.WriteString(token.Text)
+		}
+	}
+
+	return builder.String()
+}
+
+// renderPlainText is used for providers without any markup support: pauses
+// become ellipses and phonemes fall back to their alias or spelling.
+func renderPlainText(params outbound.GenerateAudioParams) string {
+	if len(params.Speech) == 0 {
+		return params.Text
+	}
+
+	var builder strings.Builder
+	for _, token := range params.Speech {
+		switch {
+		case token.Type == domain.BreakSpeechToken:
+			builder.WriteString("... ")
+		case token.Alias != "":
+			builder.WriteString(token.Alias)
+		default:
+			builder.WriteString(token.Text)
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,40 @@
+package adapters
+
+import (
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"testing"
+	"time"
+)
+
+func TestRenderElevenLabsText(t *testing.T) {
+	tests := []struct {
+		name     string
+		speech   domain.Speech
+		expected string
+	}{
+		{
+			name:     "phoneme",
+			speech:   domain.Speech{{Type: domain.PhonemeSpeechToken, Text: "Siobhan", Phoneme: "ʃɪˈvɔːn", Alphabet: "ipa"}},
+			expected: `<phoneme alphabet="ipa" ph="ʃɪˈvɔːn">Siobhan</phoneme>`,
+		},
+		{
+			name:     "escaped phoneme text",
+			speech:   domain.Speech{{Type: domain.PhonemeSpeechToken, Text: "R&D <lab>", Phoneme: `"ar"`, Alphabet: "ipa"}},
+			expected: `<phoneme alphabet="ipa" ph="&#34;ar&#34;">R&amp;D &lt;lab&gt;</phoneme>`,
+		},
+		{
+			name:     "capped break",
+			speech:   domain.Speech{{Type: domain.TextSpeechToken, Text: "Wait."}, {Type: domain.BreakSpeechToken, Pause: 5 * time.Second}},
+			expected: `Wait. <break time="3.0s" /> `,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rendered := renderElevenLabsText(outbound.GenerateAudioParams{Speech: tt.speech}); rendered != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, rendered)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteLexiconSchema = `
+CREATE TABLE IF NOT EXISTS lexicons (
+	user_id    TEXT NOT NULL,
+	id         TEXT NOT NULL,
+	story_id   TEXT NOT NULL DEFAULT '',
+	name       TEXT NOT NULL,
+	entries    TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL,
+	PRIMARY KEY (user_id, id)
+);
+`
+
+const sqliteLexiconColumns = "user_id, id, story_id, name, entries, created_at, updated_at"
+
+type sqliteLexiconRepository struct {
+	logger outbound.LoggerPort
+	db     *sql.DB
+}
+
+func NewSqliteLexiconRepository(path string, logger outbound.LoggerPort) (outbound.LexiconRepositoryPort, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lexicon database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err = db.Exec(sqliteLexiconSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create lexicon schema: %w", err)
+	}
+
+	return &sqliteLexiconRepository{
+		logger: logger,
+		db:     db,
+	}, nil
+}
+
+func (r *sqliteLexiconRepository) Save(ctx context.Context, lexicon domain.Lexicon) error {
+	entries, err := json.Marshal(lexicon.Entries)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+INSERT INTO lexicons (`+sqliteLexiconColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id, id) DO UPDATE SET
+	story_id = excluded.story_id,
+	name = excluded.name,
+	entries = excluded.entries,
+	created_at = excluded.created_at,
+	updated_at = excluded.updated_at`,
+		lexicon.UserID, lexicon.ID, lexicon.StoryID, lexicon.Name, string(entries), lexicon.CreatedAt.UnixMilli(),
+		lexicon.UpdatedAt.UnixMilli())
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to save lexicon row", map[string]interface{}{
+			"lexicon_id": lexicon.ID,
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteLexiconRepository) Get(ctx context.Context, userID string, lexiconID string) (domain.Lexicon, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+sqliteLexiconColumns+" FROM lexicons WHERE user_id = ? AND id = ?", userID, lexiconID)
+	lexicon, err := scanLexicon(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Lexicon{}, domain.ErrNotFound
+	}
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to get lexicon row", map[string]interface{}{
+			"lexicon_id": lexiconID,
+		})
+		return domain.Lexicon{}, err
+	}
+
+	return lexicon, nil
+}
+
+func (r *sqliteLexiconRepository) List(ctx context.Context, userID string) ([]domain.Lexicon, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+sqliteLexiconColumns+" FROM lexicons WHERE user_id = ? ORDER BY id", userID)
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to list lexicons", map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+	defer rows.Close()
+
+	lexicons := make([]domain.Lexicon, 0)
+	for rows.Next() {
+		lexicon, err := scanLexicon(rows)
+		if err != nil {
+			return nil, err
+		}
+		lexicons = append(lexicons, lexicon)
+	}
+
+	return lexicons, rows.Err()
+}
+
+func (r *sqliteLexiconRepository) Delete(ctx context.Context, userID string, lexiconID string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM lexicons WHERE user_id = ? AND id = ?", userID, lexiconID)
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to delete lexicon row", map[string]interface{}{
+			"lexicon_id": lexiconID,
+		})
+	}
+
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLexicon(row rowScanner) (domain.Lexicon, error) {
+	var lexicon domain.Lexicon
+	var entries string
+	var createdAt, updatedAt int64
+	err := row.Scan(&lexicon.UserID, &lexicon.ID, &lexicon.StoryID, &lexicon.Name, &entries, &createdAt, &updatedAt)
+	if err != nil {
+		return domain.Lexicon{}, err
+	}
+	if err = json.Unmarshal([]byte(entries), &lexicon.Entries); err != nil {
+		return domain.Lexicon{}, err
+	}
+	lexicon.CreatedAt = time.UnixMilli(createdAt).UTC()
+	lexicon.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+
+	return lexicon, nil
+}
@@ -0,0 +1,41 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/domain"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSqliteLexiconRepository(t *testing.T) {
+	ctx := context.Background()
+	repository, err := NewSqliteLexiconRepository(filepath.Join(t.TempDir(), "lexicons.db"), NewZerologWrapper())
+	if err != nil {
+		t.Fatal("Failed to create repository:", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	lexicon := domain.Lexicon{ID: "lexicon-1", UserID: "user-1", Name: "names", CreatedAt: now, UpdatedAt: now,
+		Entries: []domain.LexiconEntry{{Grapheme: "Aoife", Alias: "Ee-fa"}}}
+	if err = repository.Save(ctx, lexicon); err != nil {
+		t.Fatal("Failed to save lexicon:", err)
+	}
+	lexicon.Name = "renamed"
+	_ = repository.Save(ctx, lexicon)
+
+	got, err := repository.Get(ctx, "user-1", "lexicon-1")
+	if err != nil || got.Name != "renamed" || len(got.Entries) != 1 || !got.CreatedAt.Equal(now) {
+		t.Fatalf("Unexpected lexicon %+v: %v", got, err)
+	}
+	if _, err = repository.Get(ctx, "user-2", "lexicon-1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("Expected other users' lexicons to be hidden, got %v", err)
+	}
+
+	_ = repository.Delete(ctx, "user-1", "lexicon-1")
+	lexicons, err := repository.List(ctx, "user-1")
+	if err != nil || len(lexicons) != 0 {
+		t.Fatalf("Expected the lexicon to be deleted, got %+v: %v", lexicons, err)
+	}
+}
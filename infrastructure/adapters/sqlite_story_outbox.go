@@ -0,0 +1,172 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteStoryOutboxSchema = `
+CREATE TABLE IF NOT EXISTS story_outbox (
+	id              TEXT PRIMARY KEY,
+	story           TEXT NOT NULL,
+	attempts        INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at INTEGER NOT NULL,
+	last_error      TEXT NOT NULL DEFAULT '',
+	dead_lettered   INTEGER NOT NULL DEFAULT 0,
+	created_at      INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS story_outbox_due ON story_outbox (dead_lettered, next_attempt_at);
+`
+
+const sqliteStoryOutboxColumns = "id, story, attempts, next_attempt_at, last_error, dead_lettered, created_at"
+
+type sqliteStoryOutbox struct {
+	logger outbound.LoggerPort
+	db     *sql.DB
+}
+
+func NewSqliteStoryOutbox(path string, logger outbound.LoggerPort) (outbound.StoryOutboxPort, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open story outbox database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err = db.Exec(sqliteStoryOutboxSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create story outbox schema: %w", err)
+	}
+
+	return &sqliteStoryOutbox{
+		logger: logger,
+		db:     db,
+	}, nil
+}
+
+func (o *sqliteStoryOutbox) Add(ctx context.Context, entry outbound.StoryOutboxEntry) error {
+	story, err := json.Marshal(entry.Story)
+	if err != nil {
+		return err
+	}
+
+	_, err = o.db.ExecContext(ctx, "INSERT INTO story_outbox ("+sqliteStoryOutboxColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		entry.ID, string(story), entry.Attempts, entry.NextAttemptAt.UnixMilli(), entry.LastError, entry.DeadLettered,
+		entry.CreatedAt.UnixMilli())
+	if err != nil {
+		o.logger.ErrorWithFields(err, "Failed to add story outbox row", map[string]interface{}{
+			"story_id": entry.Story.ID,
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (o *sqliteStoryOutbox) Due(ctx context.Context, now time.Time, limit int) ([]outbound.StoryOutboxEntry, error) {
+	rows, err := o.db.QueryContext(ctx, "SELECT "+sqliteStoryOutboxColumns+` FROM story_outbox
+WHERE dead_lettered = 0 AND next_attempt_at <= ? ORDER BY next_attempt_at, created_at LIMIT ?`, now.UnixMilli(), limit)
+	if err != nil {
+		o.logger.Error(err, "Failed to list due story outbox rows")
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]outbound.StoryOutboxEntry, 0)
+	for rows.Next() {
+		entry, err := scanStoryOutboxEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (o *sqliteStoryOutbox) Claim(ctx context.Context, entry outbound.StoryOutboxEntry, leaseUntil time.Time) error {
+	res, err := o.db.ExecContext(ctx, `UPDATE story_outbox SET next_attempt_at = ?
+WHERE id = ? AND dead_lettered = 0 AND next_attempt_at = ?`, leaseUntil.UnixMilli(), entry.ID, entry.NextAttemptAt.UnixMilli())
+	if err != nil {
+		o.logger.ErrorWithFields(err, "Failed to claim story outbox row", map[string]interface{}{
+			"story_id": entry.Story.ID,
+		})
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("%w: unclaimed outbox entry %s", domain.ErrNotFound, entry.ID)
+	}
+
+	return nil
+}
+
+func (o *sqliteStoryOutbox) Update(ctx context.Context, entry outbound.StoryOutboxEntry) error {
+	res, err := o.db.ExecContext(ctx, `UPDATE story_outbox
+SET attempts = ?, next_attempt_at = ?, last_error = ?, dead_lettered = ? WHERE id = ?`,
+		entry.Attempts, entry.NextAttemptAt.UnixMilli(), entry.LastError, entry.DeadLettered, entry.ID)
+	if err != nil {
+		o.logger.ErrorWithFields(err, "Failed to update story outbox row", map[string]interface{}{
+			"story_id": entry.Story.ID,
+		})
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("%w: outbox entry %s", domain.ErrNotFound, entry.ID)
+	}
+
+	return nil
+}
+
+func (o *sqliteStoryOutbox) Remove(ctx context.Context, id string) error {
+	_, err := o.db.ExecContext(ctx, "DELETE FROM story_outbox WHERE id = ?", id)
+	if err != nil {
+		o.logger.ErrorWithFields(err, "Failed to remove story outbox row", map[string]interface{}{
+			"entry_id": id,
+		})
+	}
+
+	return err
+}
+
+func (o *sqliteStoryOutbox) Depth(ctx context.Context) (domain.OutboxDepth, error) {
+	var depth domain.OutboxDepth
+	var oldest sql.NullInt64
+	err := o.db.QueryRowContext(ctx, `SELECT
+	COUNT(*) FILTER (WHERE dead_lettered = 0),
+	COUNT(*) FILTER (WHERE dead_lettered = 1),
+	MIN(created_at) FILTER (WHERE dead_lettered = 0)
+FROM story_outbox`).Scan(&depth.Pending, &depth.DeadLettered, &oldest)
+	if err != nil {
+		o.logger.Error(err, "Failed to count story outbox rows")
+		return domain.OutboxDepth{}, err
+	}
+	if oldest.Valid {
+		oldestPendingAt := time.UnixMilli(oldest.Int64).UTC()
+		depth.OldestPendingAt = &oldestPendingAt
+	}
+
+	return depth, nil
+}
+
+func scanStoryOutboxEntry(row rowScanner) (outbound.StoryOutboxEntry, error) {
+	var entry outbound.StoryOutboxEntry
+	var story string
+	var nextAttemptAt, createdAt int64
+	err := row.Scan(&entry.ID, &story, &entry.Attempts, &nextAttemptAt, &entry.LastError, &entry.DeadLettered, &createdAt)
+	if err != nil {
+		return outbound.StoryOutboxEntry{}, err
+	}
+	if err = json.Unmarshal([]byte(story), &entry.Story); err != nil {
+		return outbound.StoryOutboxEntry{}, err
+	}
+	entry.NextAttemptAt = time.UnixMilli(nextAttemptAt).UTC()
+	entry.CreatedAt = time.UnixMilli(createdAt).UTC()
+
+	return entry, nil
+}
@@ -0,0 +1,295 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteStorySchema = `
+CREATE TABLE IF NOT EXISTS stories (
+	id                    TEXT PRIMARY KEY,
+	user_id               TEXT NOT NULL,
+	status                TEXT NOT NULL,
+	parameters            TEXT NOT NULL,
+	remix_of              TEXT NOT NULL DEFAULT '',
+	provider              TEXT NOT NULL DEFAULT '',
+	model                 TEXT NOT NULL DEFAULT '',
+	full_audio_url        TEXT NOT NULL DEFAULT '',
+	full_audio_media_path TEXT NOT NULL DEFAULT '',
+	segments              TEXT NOT NULL,
+	edits                 TEXT NOT NULL DEFAULT '[]',
+	created_at            INTEGER NOT NULL,
+	updated_at            INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS stories_user_created_at ON stories (user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS stories_user_updated_at ON stories (user_id, updated_at, id);
+`
+
+// sqliteStoryMigrations bring databases created before a column existed up to
+// date; a duplicate column error means the migration already ran.
+var sqliteStoryMigrations = []string{
+	"ALTER TABLE stories ADD COLUMN edits TEXT NOT NULL DEFAULT '[]'",
+	"ALTER TABLE stories ADD COLUMN remix_of TEXT NOT NULL DEFAULT ''",
+	"ALTER TABLE stories ADD COLUMN full_audio_media_path TEXT NOT NULL DEFAULT ''",
+}
+
+const sqliteStoryColumns = "id, user_id, status, parameters, remix_of, provider, model, full_audio_url, full_audio_media_path, segments, " +
+	"edits, created_at, updated_at"
+
+// sqliteStorySortColumns doubles as the whitelist for the ORDER BY column.
+var sqliteStorySortColumns = map[domain.StorySortField]string{
+	domain.CreatedAtStorySortField: "created_at",
+	domain.UpdatedAtStorySortField: "updated_at",
+}
+
+type sqliteStoryRepository struct {
+	logger outbound.LoggerPort
+	db     *sql.DB
+}
+
+func NewSqliteStoryRepository(path string, logger outbound.LoggerPort) (outbound.StoryRepositoryPort, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open story database: %w", err)
+	}
+	// SQLite allows one writer at a time; a single connection avoids
+	// "database is locked" errors under concurrent saves.
+	db.SetMaxOpenConns(1)
+
+	if _, err = db.Exec(sqliteStorySchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create story schema: %w", err)
+	}
+	for _, migration := range sqliteStoryMigrations {
+		_, err = db.Exec(migration)
+		if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to migrate story schema: %w", err)
+		}
+	}
+
+	return &sqliteStoryRepository{
+		logger: logger,
+		db:     db,
+	}, nil
+}
+
+func (r *sqliteStoryRepository) Save(ctx context.Context, story domain.Story) error {
+	parameters, err := json.Marshal(story.Parameters)
+	if err != nil {
+		return err
+	}
+	segments, err := json.Marshal(story.Segments)
+	if err != nil {
+		return err
+	}
+	edits, err := json.Marshal(story.Edits)
+	if err != nil {
+		return err
+	}
+
+	// The user_id guard keeps a story from being taken over by another user
+	// reusing its ID.
+	_, err = r.db.ExecContext(ctx, `
+INSERT INTO stories (`+sqliteStoryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+	status = excluded.status,
+	parameters = excluded.parameters,
+	remix_of = excluded.remix_of,
+	provider = excluded.provider,
+	model = excluded.model,
+	full_audio_url = excluded.full_audio_url,
+	full_audio_media_path = excluded.full_audio_media_path,
+	segments = excluded.segments,
+	edits = excluded.edits,
+	created_at = excluded.created_at,
+	updated_at = excluded.updated_at
+WHERE stories.user_id = excluded.user_id`,
+		story.ID, story.UserID, story.Status, string(parameters), story.RemixOf, story.Provider, story.Model, story.FullAudioURL,
+		story.FullAudioMediaPath, string(segments), string(edits), story.CreatedAt.UnixMilli(), story.UpdatedAt.UnixMilli())
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to save story row", map[string]interface{}{
+			"story_id": story.ID,
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (r *sqliteStoryRepository) Update(ctx context.Context, story domain.Story, previousUpdatedAt time.Time) error {
+	parameters, err := json.Marshal(story.Parameters)
+	if err != nil {
+		return err
+	}
+	segments, err := json.Marshal(story.Segments)
+	if err != nil {
+		return err
+	}
+	edits, err := json.Marshal(story.Edits)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+UPDATE stories SET
+	status = ?,
+	parameters = ?,
+	remix_of = ?,
+	provider = ?,
+	model = ?,
+	full_audio_url = ?,
+	full_audio_media_path = ?,
+	segments = ?,
+	edits = ?,
+	updated_at = ?
+WHERE user_id = ? AND id = ? AND updated_at = ?`,
+		story.Status, string(parameters), story.RemixOf, story.Provider, story.Model, story.FullAudioURL, story.FullAudioMediaPath,
+		string(segments), string(edits), story.UpdatedAt.UnixMilli(), story.UserID, story.ID, previousUpdatedAt.UnixMilli())
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to update story row", map[string]interface{}{
+			"story_id": story.ID,
+		})
+		return err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return fmt.Errorf("%w: story %s changed concurrently", domain.ErrConflict, story.ID)
+	}
+
+	return nil
+}
+
+func (r *sqliteStoryRepository) Get(ctx context.Context, userID string, storyID string) (domain.Story, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+sqliteStoryColumns+" FROM stories WHERE user_id = ? AND id = ?", userID, storyID)
+	story, err := scanStory(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Story{}, fmt.Errorf("%w: story %s", domain.ErrNotFound, storyID)
+	}
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to get story row", map[string]interface{}{
+			"story_id": storyID,
+		})
+		return domain.Story{}, err
+	}
+
+	return story, nil
+}
+
+// List uses keyset pagination on (sort column, id); the cursor carries the
+// last row's values.
+func (r *sqliteStoryRepository) List(ctx context.Context, query outbound.StoryListQuery) (domain.StoryPage, error) {
+	column, ok := sqliteStorySortColumns[query.SortBy]
+	if !ok {
+		column = sqliteStorySortColumns[domain.CreatedAtStorySortField]
+	}
+	direction, comparison := "DESC", "<"
+	if query.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+
+	statement := "SELECT " + sqliteStoryColumns + " FROM stories WHERE user_id = ?"
+	args := []interface{}{query.UserID}
+	if query.Cursor != "" {
+		sortValue, storyID, err := decodeSqliteCursor(query.Cursor)
+		if err != nil {
+			return domain.StoryPage{}, fmt.Errorf("%w: invalid cursor", domain.ErrInvalidInput)
+		}
+		statement += fmt.Sprintf(" AND (%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", column, comparison)
+		args = append(args, sortValue, sortValue, storyID)
+	}
+	statement += fmt.Sprintf(" ORDER BY %[1]s %[2]s, id %[2]s LIMIT ?", column, direction)
+	args = append(args, query.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		r.logger.ErrorWithFields(err, "Failed to list stories", map[string]interface{}{
+			"user_id": query.UserID,
+		})
+		return domain.StoryPage{}, err
+	}
+	defer rows.Close()
+
+	page := domain.StoryPage{Stories: make([]domain.Story, 0, query.Limit)}
+	for rows.Next() {
+		story, err := scanStory(rows)
+		if err != nil {
+			return domain.StoryPage{}, err
+		}
+		page.Stories = append(page.Stories, story)
+	}
+	if err = rows.Err(); err != nil {
+		return domain.StoryPage{}, err
+	}
+
+	if len(page.Stories) > query.Limit {
+		page.Stories = page.Stories[:query.Limit]
+		last := page.Stories[len(page.Stories)-1]
+		sortValue := last.CreatedAt
+		if column == sqliteStorySortColumns[domain.UpdatedAtStorySortField] {
+			sortValue = last.UpdatedAt
+		}
+		page.NextCursor = encodeSqliteCursor(sortValue.UnixMilli(), last.ID)
+	}
+
+	return page, nil
+}
+
+func scanStory(row rowScanner) (domain.Story, error) {
+	var story domain.Story
+	var parameters, segments, edits string
+	var createdAt, updatedAt int64
+	err := row.Scan(&story.ID, &story.UserID, &story.Status, &parameters, &story.RemixOf, &story.Provider, &story.Model,
+		&story.FullAudioURL, &story.FullAudioMediaPath, &segments, &edits, &createdAt, &updatedAt)
+	if err != nil {
+		return domain.Story{}, err
+	}
+	if err = json.Unmarshal([]byte(parameters), &story.Parameters); err != nil {
+		return domain.Story{}, err
+	}
+	if err = json.Unmarshal([]byte(segments), &story.Segments); err != nil {
+		return domain.Story{}, err
+	}
+	if story.Segments == nil {
+		story.Segments = make([]domain.StorySegment, 0)
+	}
+	if err = json.Unmarshal([]byte(edits), &story.Edits); err != nil {
+		return domain.Story{}, err
+	}
+	story.CreatedAt = time.UnixMilli(createdAt).UTC()
+	story.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+
+	return story, nil
+}
+
+func encodeSqliteCursor(sortValue int64, storyID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(sortValue, 10) + ":" + storyID))
+}
+
+func decodeSqliteCursor(cursor string) (int64, string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, "", err
+	}
+	value, storyID, ok := strings.Cut(string(decoded), ":")
+	if !ok || storyID == "" {
+		return 0, "", fmt.Errorf("malformed cursor")
+	}
+	sortValue, err := strconv.ParseInt(value, 10, 64)
+
+	return sortValue, storyID, err
+}
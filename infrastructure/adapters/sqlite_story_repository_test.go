@@ -0,0 +1,99 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSqliteStoryRepository(t *testing.T) {
+	ctx := context.Background()
+	repository, err := NewSqliteStoryRepository(filepath.Join(t.TempDir(), "stories.db"), NewZerologWrapper())
+	if err != nil {
+		t.Fatal("Failed to create repository:", err)
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stories := []domain.Story{
+		{ID: "a", UserID: "user-1", CreatedAt: base, UpdatedAt: base.Add(3 * time.Hour)},
+		{ID: "b", UserID: "user-1", CreatedAt: base.Add(time.Hour), UpdatedAt: base.Add(time.Hour)},
+		{ID: "c", UserID: "user-1", CreatedAt: base.Add(2 * time.Hour), UpdatedAt: base.Add(2 * time.Hour)},
+		{ID: "d", UserID: "user-2", CreatedAt: base, UpdatedAt: base},
+	}
+	for _, story := range stories {
+		story.Status = domain.CompletedStoryStatus
+		story.Parameters = domain.StoryParameters{Input: "story " + story.ID, VoiceID: "voice"}
+		story.Segments = []domain.StorySegment{{SegmentID: story.ID + "-1", Type: domain.AudioSegmentType}}
+		if err = repository.Save(ctx, story); err != nil {
+			t.Fatal("Failed to save story:", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		query    outbound.StoryListQuery
+		expected []string
+	}{
+		{name: "newest first", query: outbound.StoryListQuery{UserID: "user-1", SortBy: domain.CreatedAtStorySortField}, expected: []string{"c", "b", "a"}},
+		{name: "oldest update first", query: outbound.StoryListQuery{UserID: "user-1", SortBy: domain.UpdatedAtStorySortField, Ascending: true}, expected: []string{"b", "c", "a"}},
+		{name: "other user", query: outbound.StoryListQuery{UserID: "user-2"}, expected: []string{"d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := make([]string, 0)
+			query := tt.query
+			query.Limit = 2
+			for {
+				page, err := repository.List(ctx, query)
+				if err != nil {
+					t.Fatal("Failed to list stories:", err)
+				}
+				for _, story := range page.Stories {
+					ids = append(ids, story.ID)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				query.Cursor = page.NextCursor
+			}
+			if len(ids) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, ids)
+				}
+			}
+		})
+	}
+
+	story, err := repository.Get(ctx, "user-1", "a")
+	if err != nil {
+		t.Fatal("Failed to get story:", err)
+	}
+	if story.Parameters.Input != "story a" || len(story.Segments) != 1 || !story.UpdatedAt.Equal(base.Add(3*time.Hour)) {
+		t.Errorf("story did not round-trip: %+v", story)
+	}
+
+	_ = repository.Save(ctx, domain.Story{ID: "a", UserID: "user-2", Status: domain.FailedStoryStatus})
+	if _, err = repository.Get(ctx, "user-2", "a"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected another user's story to stay hidden, got %v", err)
+	}
+
+	updated := story
+	updated.Status = domain.FailedStoryStatus
+	updated.UpdatedAt = story.UpdatedAt.Add(time.Minute)
+	if err = repository.Update(ctx, updated, story.UpdatedAt); err != nil {
+		t.Fatal("Failed to update story:", err)
+	}
+	if err = repository.Update(ctx, updated, story.UpdatedAt); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("expected an update from a stale read to conflict, got %v", err)
+	}
+	if story, _ = repository.Get(ctx, "user-1", "a"); story.Status != domain.FailedStoryStatus {
+		t.Errorf("expected the conditional update to be stored, got %+v", story)
+	}
+}
@@ -0,0 +1,54 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"os"
+)
+
+type staticVoiceCatalog struct {
+	logger   outbound.LoggerPort
+	fileName string
+}
+
+func NewStaticVoiceCatalog(fileName string, logger outbound.LoggerPort) outbound.VoiceCatalogPort {
+	return &staticVoiceCatalog{
+		logger:   logger,
+		fileName: fileName,
+	}
+}
+
+func (s *staticVoiceCatalog) ListVoices(_ context.Context) ([]domain.Voice, error) {
+	file, err := os.Open(s.fileName)
+	if err != nil {
+		s.logger.ErrorWithFields(err, "Failed to open voice catalog file", map[string]interface{}{
+			"file": s.fileName,
+		})
+		return nil, err
+	}
+	defer func(file *os.File) {
+		err := file.Close()
+		if err != nil {
+			s.logger.Error(err, "Failed to close voice catalog file")
+		}
+	}(file)
+
+	var voices []domain.Voice
+	if err := json.NewDecoder(file).Decode(&voices); err != nil {
+		s.logger.Error(err, "Failed to decode voice catalog file")
+		return nil, err
+	}
+
+	for i, voice := range voices {
+		if voice.Provider == "" {
+			return nil, fmt.Errorf("voice %s in %s has no provider", voice.ID, s.fileName)
+		}
+		provider, id := domain.SplitVoiceID(voice.ID, voice.Provider)
+		voices[i].ID = domain.JoinVoiceID(provider, id)
+	}
+
+	return voices, nil
+}
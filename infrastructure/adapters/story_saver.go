@@ -6,27 +6,74 @@ import (
 	"encoding/json"
 	"fmt"
 	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
 	"io"
 	"net/http"
+	"time"
 )
 
+// StoryManifestSchemaVersion is bumped whenever a field of
+// StoryManifestRequest changes meaning or goes away; new optional fields
+// keep the version. Version 3 leaves out media URLs that expire.
+const StoryManifestSchemaVersion = 3
+
 type StoryRequest struct {
 	Input  string `json:"input"`
 	ID     string `json:"id"`
 	UserID string `json:"user_id"`
 }
 
+// StoryManifestRequest is everything a generation run produced. The legacy
+// StoryRequest fields stay at the top level.
+type StoryManifestRequest struct {
+	StoryRequest
+	SchemaVersion int                    `json:"schema_version"`
+	RemixOf       string                 `json:"remix_of,omitempty"`
+	Parameters    domain.StoryParameters `json:"parameters"`
+	Segments      []StoryManifestSegment `json:"segments"`
+	Assets        StoryManifestAssets    `json:"assets"`
+	Timings       StoryManifestTimings   `json:"timings"`
+}
+
+// StoryManifestSegment adds the API path that resolves the segment's media
+// to fresh URLs, as the manifest may be delivered long after signed URLs in
+// it expired.
+type StoryManifestSegment struct {
+	domain.SegmentEvent
+	MediaPath string `json:"media_path"`
+}
+
+type StoryManifestAssets struct {
+	FullAudioUrl       string `json:"full_audio_url,omitempty"`
+	FullAudioMediaPath string `json:"full_audio_media_path,omitempty"`
+	VttUrl             string `json:"vtt_url,omitempty"`
+	SrtUrl             string `json:"srt_url,omitempty"`
+}
+
+type StoryManifestTimings struct {
+	StartedAt         time.Time `json:"started_at"`
+	CompletedAt       time.Time `json:"completed_at"`
+	GenerationSeconds float64   `json:"generation_seconds"`
+	AudioSeconds      float64   `json:"audio_seconds"`
+}
+
 type storySaver struct {
-	storyApiUrl string
-	logger      outbound.LoggerPort
-	authorizer  Authorizer
+	storyApiUrl     string
+	payloadFormat   string
+	mediaUrlsExpire bool
+	logger          outbound.LoggerPort
+	authorizer      Authorizer
 }
 
-func NewStorySaver(storyApiUrl string, authorizer Authorizer, logger outbound.LoggerPort) outbound.StorySaverPort {
+func NewStorySaver(storyApiConfig *config.StoryApiConfig, authorizer Authorizer, mediaUrlsExpire bool,
+	logger outbound.LoggerPort) outbound.StorySaverPort {
 	return &storySaver{
-		logger:      logger,
-		storyApiUrl: storyApiUrl,
-		authorizer:  authorizer,
+		logger:          logger,
+		storyApiUrl:     storyApiConfig.Url,
+		payloadFormat:   storyApiConfig.PayloadFormat,
+		mediaUrlsExpire: mediaUrlsExpire,
+		authorizer:      authorizer,
 	}
 }
 
@@ -36,12 +83,7 @@ func (s *storySaver) Save(ctx context.Context, params outbound.SaveStoryParams)
 		s.logger.Error(err, "Failed to authorize")
 		return err
 	}
-	storyRequest := StoryRequest{
-		Input:  params.Input,
-		ID:     params.ID,
-		UserID: params.UserID,
-	}
-	payload, err := json.Marshal(storyRequest)
+	payload, err := json.Marshal(s.payload(params))
 	if err != nil {
 		s.logger.Error(err, "Failed to marshal the request")
 		return err
@@ -50,9 +92,11 @@ func (s *storySaver) Save(ctx context.Context, params outbound.SaveStoryParams)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.storyApiUrl, bytes.NewReader(payload))
 	if err != nil {
 		s.logger.Error(err, "Failed to create the HTTP request")
+		return err
 	}
 
 	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -86,3 +130,59 @@ func (s *storySaver) Save(ctx context.Context, params outbound.SaveStoryParams)
 
 	return nil
 }
+
+func (s *storySaver) payload(params outbound.SaveStoryParams) interface{} {
+	storyRequest := StoryRequest{
+		Input:  params.Input,
+		ID:     params.ID,
+		UserID: params.UserID,
+	}
+	if s.payloadFormat == config.LegacyStoryPayloadFormat {
+		return storyRequest
+	}
+
+	segments := make([]StoryManifestSegment, 0, len(params.Segments))
+	audioSeconds := 0.0
+	for _, event := range params.Segments {
+		if event.Type == domain.AudioSegmentType && event.Media != nil {
+			audioSeconds += event.Media.Duration
+		}
+		if s.mediaUrlsExpire {
+			event.Url, event.VttUrl, event.SrtUrl = "", "", ""
+		}
+		segments = append(segments, StoryManifestSegment{
+			SegmentEvent: event,
+			MediaPath:    domain.SegmentMediaPath(params.ID, event.SegmentId),
+		})
+	}
+	assets := StoryManifestAssets{
+		FullAudioUrl: params.Assets.FullAudioURL,
+		VttUrl:       params.Assets.Subtitles.VttURL,
+		SrtUrl:       params.Assets.Subtitles.SrtURL,
+	}
+	if assets.FullAudioUrl != "" {
+		assets.FullAudioMediaPath = domain.SegmentMediaPath(params.ID, params.ID)
+	}
+	if s.mediaUrlsExpire {
+		assets.FullAudioUrl, assets.VttUrl, assets.SrtUrl = "", "", ""
+	}
+	generationSeconds := 0.0
+	if !params.StartedAt.IsZero() && params.CompletedAt.After(params.StartedAt) {
+		generationSeconds = params.CompletedAt.Sub(params.StartedAt).Seconds()
+	}
+
+	return StoryManifestRequest{
+		StoryRequest:  storyRequest,
+		SchemaVersion: StoryManifestSchemaVersion,
+		RemixOf:       params.RemixOf,
+		Parameters:    params.Parameters,
+		Segments:      segments,
+		Assets:        assets,
+		Timings: StoryManifestTimings{
+			StartedAt:         params.StartedAt,
+			CompletedAt:       params.CompletedAt,
+			GenerationSeconds: generationSeconds,
+			AudioSeconds:      audioSeconds,
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/config"
+	"generate-script-lambda/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type staticAuthorizer struct{}
+
+func (staticAuthorizer) Authorize(context.Context) (string, error) {
+	return "token", nil
+}
+
+func TestStorySaver_Save(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received = nil
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	startedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	params := outbound.SaveStoryParams{
+		ID:         "story-1",
+		UserID:     "user-1",
+		Input:      "a castle",
+		Parameters: domain.StoryParameters{Input: "a castle", VoiceID: "narrator"},
+		Segments: []domain.SegmentEvent{
+			{SegmentId: "img-1", Type: domain.ImageSegmentType, Url: "http://media/img-1"},
+			{SegmentId: "audio-1", Type: domain.AudioSegmentType, Url: "http://media/audio-1",
+				Media: &domain.MediaMetadata{Duration: 2.5}},
+		},
+		Assets:      domain.StoryAssets{FullAudioURL: "http://media/full"},
+		StartedAt:   startedAt,
+		CompletedAt: startedAt.Add(90 * time.Second),
+	}
+
+	saver := NewStorySaver(&config.StoryApiConfig{Url: server.URL, PayloadFormat: config.ManifestStoryPayloadFormat},
+		staticAuthorizer{}, false, NewZerologWrapper())
+	if err := saver.Save(context.Background(), params); err != nil {
+		t.Fatal("Failed to save story:", err)
+	}
+
+	segments, _ := received["segments"].([]interface{})
+	timings, _ := received["timings"].(map[string]interface{})
+	if received["id"] != "story-1" || received["schema_version"] != float64(StoryManifestSchemaVersion) || len(segments) != 2 {
+		t.Fatalf("Unexpected manifest: %v", received)
+	}
+	if timings["generation_seconds"] != float64(90) || timings["audio_seconds"] != 2.5 {
+		t.Fatalf("Unexpected timings: %v", timings)
+	}
+
+	saver = NewStorySaver(&config.StoryApiConfig{Url: server.URL, PayloadFormat: config.ManifestStoryPayloadFormat},
+		staticAuthorizer{}, true, NewZerologWrapper())
+	if err := saver.Save(context.Background(), params); err != nil {
+		t.Fatal("Failed to save story:", err)
+	}
+	segments, _ = received["segments"].([]interface{})
+	audio, _ := segments[1].(map[string]interface{})
+	assets, _ := received["assets"].(map[string]interface{})
+	if audio["url"] != "" || audio["media_path"] != "/stories/story-1/media/audio-1" ||
+		assets["full_audio_url"] != nil || assets["full_audio_media_path"] != "/stories/story-1/media/story-1" {
+		t.Fatalf("Expected expiring URLs to be replaced by media paths, got %v and %v", audio, assets)
+	}
+
+	saver = NewStorySaver(&config.StoryApiConfig{Url: server.URL, PayloadFormat: config.LegacyStoryPayloadFormat},
+		staticAuthorizer{}, false, NewZerologWrapper())
+	if err := saver.Save(context.Background(), params); err != nil {
+		t.Fatal("Failed to save story:", err)
+	}
+	if len(received) != 3 || received["input"] != "a castle" {
+		t.Fatalf("Unexpected legacy payload: %v", received)
+	}
+}
@@ -143,6 +143,10 @@ func (s *storyScriptGenerator) createRequest(ctx context.Context, input string)
 			"- Should be used only 4 times per story\n"+
 			"- Should be used in a meaningful way (only when the scenery changes drastically)\n"+
 			"- Should not be part of the storytelling (similar to a theater play, just to set the scenery)\n"+
+			"Whenever the tone of the story changes, write a mood marker right before the passage, for example: <mood value=\"tense\"/>\n"+
+			"The mood markers:\n"+
+			"- Should use only one of the values: calm, tense, joyful\n"+
+			"- Should not be part of the storytelling\n"+
 			"The story should be of about %d words.", input, s.wordsPerStory),
 	}
 
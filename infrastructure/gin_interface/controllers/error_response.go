@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"errors"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func abortWithDomainError(c *gin.Context, logger outbound.LoggerPort, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotSupported):
+		c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrConflict):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Error(err, "request failed")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
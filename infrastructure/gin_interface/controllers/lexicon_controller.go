@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/infrastructure/gin_interface/dto"
+	"generate-script-lambda/middleware"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type LexiconController interface {
+	CreateLexicon(c *gin.Context)
+	ListLexicons(c *gin.Context)
+	GetLexicon(c *gin.Context)
+	UpdateLexicon(c *gin.Context)
+	DeleteLexicon(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
+}
+
+type lexiconController struct {
+	logger         outbound.LoggerPort
+	lexiconManager inbound.LexiconManagerPort
+}
+
+func NewLexiconController(logger outbound.LoggerPort, lexiconManager inbound.LexiconManagerPort) LexiconController {
+	return &lexiconController{
+		logger:         logger,
+		lexiconManager: lexiconManager,
+	}
+}
+
+func (l *lexiconController) CreateLexicon(c *gin.Context) {
+	var lexiconRequest dto.LexiconRequest
+	if err := c.ShouldBindJSON(&lexiconRequest); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lexicon, err := l.lexiconManager.Create(c, c.GetString(middleware.ContextUserIDKey), l.toParams(lexiconRequest))
+	if err != nil {
+		abortWithDomainError(c, l.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, lexicon)
+}
+
+func (l *lexiconController) ListLexicons(c *gin.Context) {
+	lexicons, err := l.lexiconManager.List(c, c.GetString(middleware.ContextUserIDKey))
+	if err != nil {
+		abortWithDomainError(c, l.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lexicons)
+}
+
+func (l *lexiconController) GetLexicon(c *gin.Context) {
+	lexicon, err := l.lexiconManager.Get(c, c.GetString(middleware.ContextUserIDKey), c.Param("id"))
+	if err != nil {
+		abortWithDomainError(c, l.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lexicon)
+}
+
+func (l *lexiconController) UpdateLexicon(c *gin.Context) {
+	var lexiconRequest dto.LexiconRequest
+	if err := c.ShouldBindJSON(&lexiconRequest); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lexicon, err := l.lexiconManager.Update(c, c.GetString(middleware.ContextUserIDKey), c.Param("id"), l.toParams(lexiconRequest))
+	if err != nil {
+		abortWithDomainError(c, l.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lexicon)
+}
+
+func (l *lexiconController) DeleteLexicon(c *gin.Context) {
+	err := l.lexiconManager.Delete(c, c.GetString(middleware.ContextUserIDKey), c.Param("id"))
+	if err != nil {
+		abortWithDomainError(c, l.logger, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (l *lexiconController) toParams(lexiconRequest dto.LexiconRequest) inbound.LexiconParams {
+	return inbound.LexiconParams{
+		Name:    lexiconRequest.Name,
+		StoryID: lexiconRequest.StoryID,
+		Entries: lexiconRequest.Entries,
+	}
+}
+
+func (l *lexiconController) RegisterRoutes(g gin.IRouter) {
+	g.POST("/lexicons", l.CreateLexicon)
+	g.GET("/lexicons", l.ListLexicons)
+	g.GET("/lexicons/:id", l.GetLexicon)
+	g.PUT("/lexicons/:id", l.UpdateLexicon)
+	g.DELETE("/lexicons/:id", l.DeleteLexicon)
+}
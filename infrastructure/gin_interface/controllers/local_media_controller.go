@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/infrastructure/adapters"
+	"generate-script-lambda/media_utils"
+	"generate-script-lambda/middleware"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+var localAttachmentContentTypes = map[string]string{
+	".vtt":  "text/vtt; charset=utf-8",
+	".srt":  "application/x-subrip; charset=utf-8",
+	".html": "text/html; charset=utf-8",
+}
+
+type LocalMediaController interface {
+	ServeMedia(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
+}
+
+type localMediaController struct {
+	logger  outbound.LoggerPort
+	rootDir string
+}
+
+func NewLocalMediaController(logger outbound.LoggerPort, rootDir string) LocalMediaController {
+	return &localMediaController{
+		logger:  logger,
+		rootDir: rootDir,
+	}
+}
+
+func (l *localMediaController) ServeMedia(c *gin.Context) {
+	itemPath := strings.TrimPrefix(c.Param("path"), "/")
+	filePath, err := adapters.LocalMediaPath(l.rootDir, itemPath)
+	if err != nil {
+		abortWithDomainError(c, l.logger, err)
+		return
+	}
+	// Media of other users is answered like missing media.
+	if !adapters.MediaOwnedBy(itemPath, c.GetString(middleware.ContextUserIDKey)) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	file, err := os.Open(filePath)
+	var info os.FileInfo
+	if err == nil {
+		defer file.Close()
+		info, err = file.Stat()
+	}
+	if err == nil && info.IsDir() {
+		err = os.ErrNotExist
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		abortWithDomainError(c, l.logger, err)
+		return
+	}
+
+	contentType, ok := localAttachmentContentTypes[path.Ext(itemPath)]
+	if !ok {
+		contentType, err = l.sniffContentType(file)
+		if err != nil {
+			abortWithDomainError(c, l.logger, err)
+			return
+		}
+	}
+	c.Header("Content-Type", contentType)
+	http.ServeContent(c.Writer, c.Request, path.Base(itemPath), info.ModTime(), file)
+}
+
+// sniffContentType reads no more than the content type detection looks at
+// and rewinds the file for serving.
+func (l *localMediaController) sniffContentType(file *os.File) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return media_utils.DetectContentType(head[:n]), nil
+}
+
+func (l *localMediaController) RegisterRoutes(g gin.IRouter) {
+	g.GET("/media/*path", l.ServeMedia)
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/infrastructure/gin_interface/dto"
+	"generate-script-lambda/middleware"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type SegmentEditController interface {
+	EditSegment(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
+}
+
+type segmentEditController struct {
+	logger outbound.LoggerPort
+	editor inbound.SegmentEditorPort
+}
+
+func NewSegmentEditController(logger outbound.LoggerPort, editor inbound.SegmentEditorPort) SegmentEditController {
+	return &segmentEditController{
+		logger: logger,
+		editor: editor,
+	}
+}
+
+func (s *segmentEditController) EditSegment(c *gin.Context) {
+	var request dto.EditSegmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	edited, err := s.editor.EditText(c, inbound.EditSegmentTextParams{
+		StoryID:   c.Param("id"),
+		SegmentID: c.Param("segmentId"),
+		UserID:    c.GetString(middleware.ContextUserIDKey),
+		Text:      request.Text,
+	})
+	if err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, edited)
+}
+
+func (s *segmentEditController) RegisterRoutes(g gin.IRouter) {
+	g.PATCH("/stories/:id/segments/:segmentId", s.EditSegment)
+}
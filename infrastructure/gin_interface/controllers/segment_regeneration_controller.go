@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"errors"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/infrastructure/gin_interface/dto"
+	"generate-script-lambda/middleware"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type SegmentRegenerationController interface {
+	RegenerateSegment(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
+}
+
+type segmentRegenerationController struct {
+	logger       outbound.LoggerPort
+	regenerator  inbound.SegmentRegeneratorPort
+	voiceCatalog inbound.VoiceCatalogServicePort
+}
+
+func NewSegmentRegenerationController(logger outbound.LoggerPort, regenerator inbound.SegmentRegeneratorPort,
+	voiceCatalog inbound.VoiceCatalogServicePort) SegmentRegenerationController {
+	return &segmentRegenerationController{
+		logger:       logger,
+		regenerator:  regenerator,
+		voiceCatalog: voiceCatalog,
+	}
+}
+
+func (s *segmentRegenerationController) RegenerateSegment(c *gin.Context) {
+	var request dto.RegenerateSegmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.VoiceID != "" {
+		if err := s.voiceCatalog.Validate(c, request.VoiceID); err != nil {
+			abortWithDomainError(c, s.logger, err)
+			return
+		}
+	}
+
+	event, err := s.regenerator.Regenerate(c, inbound.RegenerateSegmentParams{
+		StoryID:       c.Param("id"),
+		SegmentID:     c.Param("segmentId"),
+		UserID:        c.GetString(middleware.ContextUserIDKey),
+		Text:          strings.TrimSpace(request.Text),
+		VoiceID:       request.VoiceID,
+		VoiceSettings: request.VoiceSettings.ToDomain(),
+	})
+	if err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, event)
+}
+
+func (s *segmentRegenerationController) RegisterRoutes(g gin.IRouter) {
+	g.POST("/stories/:id/segments/:segmentId/regenerate", s.RegenerateSegment)
+}
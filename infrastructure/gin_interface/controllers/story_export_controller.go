@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"fmt"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/middleware"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type StoryExportController interface {
+	ExportStory(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
+}
+
+type storyExportController struct {
+	logger   outbound.LoggerPort
+	exporter inbound.StoryExporterPort
+}
+
+func NewStoryExportController(logger outbound.LoggerPort, exporter inbound.StoryExporterPort) StoryExportController {
+	return &storyExportController{
+		logger:   logger,
+		exporter: exporter,
+	}
+}
+
+func (s *storyExportController) ExportStory(c *gin.Context) {
+	storyID := c.Param("id")
+	export, err := s.exporter.Export(c, inbound.ExportStoryParams{
+		StoryID: storyID,
+		UserID:  c.GetString(middleware.ContextUserIDKey),
+		Format:  inbound.ExportFormat(c.DefaultQuery("format", string(inbound.ZipExportFormat))),
+	})
+	if err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName))
+	c.Header("Cache-Control", "private, no-store")
+	c.Status(http.StatusOK)
+
+	// Headers are already on the wire, so a failure here can only cut the
+	// download short.
+	if err = export.Write(c.Writer); err != nil {
+		s.logger.ErrorWithFields(err, "Failed to stream story export", map[string]interface{}{
+			"story_id": storyID,
+		})
+	}
+}
+
+func (s *storyExportController) RegisterRoutes(g gin.IRouter) {
+	g.GET("/stories/:id/export", s.ExportStory)
+}
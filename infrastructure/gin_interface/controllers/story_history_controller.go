@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/infrastructure/gin_interface/dto"
+	"generate-script-lambda/middleware"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type StoryHistoryController interface {
+	ListStories(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
+}
+
+type storyHistoryController struct {
+	logger       outbound.LoggerPort
+	storyHistory inbound.StoryHistoryPort
+}
+
+func NewStoryHistoryController(logger outbound.LoggerPort, storyHistory inbound.StoryHistoryPort) StoryHistoryController {
+	return &storyHistoryController{
+		logger:       logger,
+		storyHistory: storyHistory,
+	}
+}
+
+func (s *storyHistoryController) ListStories(c *gin.Context) {
+	var request dto.ListStoriesRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := s.storyHistory.List(c, inbound.ListStoriesParams{
+		UserID:    c.GetString(middleware.ContextUserIDKey),
+		SortBy:    request.SortField(),
+		Ascending: request.Ascending(),
+		Limit:     request.Limit,
+		Cursor:    request.Cursor,
+	})
+	if err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, page)
+}
+
+func (s *storyHistoryController) RegisterRoutes(g gin.IRouter) {
+	g.GET("/stories", s.ListStories)
+}
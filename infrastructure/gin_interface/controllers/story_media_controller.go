@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/gin_interface/dto"
+	"generate-script-lambda/middleware"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type StoryMediaController interface {
+	GetSegmentMedia(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
+}
+
+type storyMediaController struct {
+	logger     outbound.LoggerPort
+	mediaStore outbound.SegmentMediaStorePort
+}
+
+func NewStoryMediaController(logger outbound.LoggerPort, mediaStore outbound.SegmentMediaStorePort) StoryMediaController {
+	return &storyMediaController{
+		logger:     logger,
+		mediaStore: mediaStore,
+	}
+}
+
+// GetSegmentMedia returns the segment's current media, or with ?version= one
+// of the versions it replaced when it was regenerated.
+func (s *storyMediaController) GetSegmentMedia(c *gin.Context) {
+	var request dto.SegmentMediaRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	storyID, segmentID, userID := c.Param("id"), c.Param("segmentId"), c.GetString(middleware.ContextUserIDKey)
+	var links domain.MediaLinks
+	var err error
+	if request.Version > 0 {
+		links, err = s.mediaStore.FindMediaVersion(c, storyID, segmentID, request.Version, userID)
+	} else {
+		links, err = s.mediaStore.FindMedia(c, storyID, segmentID, userID)
+	}
+	if err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, links)
+}
+
+func (s *storyMediaController) RegisterRoutes(g gin.IRouter) {
+	g.GET("/stories/:id/media/:segmentId", s.GetSegmentMedia)
+}
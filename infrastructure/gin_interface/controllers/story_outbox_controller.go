@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type StoryOutboxController interface {
+	GetDepth(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
+}
+
+type storyOutboxController struct {
+	logger        outbound.LoggerPort
+	storyDelivery inbound.StoryDeliveryPort
+}
+
+func NewStoryOutboxController(logger outbound.LoggerPort, storyDelivery inbound.StoryDeliveryPort) StoryOutboxController {
+	return &storyOutboxController{
+		logger:        logger,
+		storyDelivery: storyDelivery,
+	}
+}
+
+func (s *storyOutboxController) GetDepth(c *gin.Context) {
+	depth, err := s.storyDelivery.Depth(c)
+	if err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, depth)
+}
+
+func (s *storyOutboxController) RegisterRoutes(g gin.IRouter) {
+	g.GET("/metrics/story-outbox", s.GetDepth)
+}
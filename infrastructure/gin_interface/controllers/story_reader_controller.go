@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/infrastructure/gin_interface/dto"
+	"generate-script-lambda/middleware"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type StoryReaderController interface {
+	ListSegments(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
+}
+
+type storyReaderController struct {
+	logger      outbound.LoggerPort
+	storyReader inbound.StoryReaderPort
+}
+
+func NewStoryReaderController(logger outbound.LoggerPort, storyReader inbound.StoryReaderPort) StoryReaderController {
+	return &storyReaderController{
+		logger:      logger,
+		storyReader: storyReader,
+	}
+}
+
+func (s *storyReaderController) ListSegments(c *gin.Context) {
+	var request dto.ListSegmentsRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := s.storyReader.ListSegments(c, inbound.ListSegmentsParams{
+		StoryID: c.Param("id"),
+		UserID:  c.GetString(middleware.ContextUserIDKey),
+		Type:    request.SegmentType(),
+		Limit:   request.Limit,
+		Cursor:  request.Cursor,
+	})
+	if err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, page)
+}
+
+func (s *storyReaderController) RegisterRoutes(g gin.IRouter) {
+	g.GET("/stories/:id/segments", s.ListSegments)
+}
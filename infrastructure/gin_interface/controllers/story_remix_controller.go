@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
+	"generate-script-lambda/infrastructure/gin_interface/dto"
+	"generate-script-lambda/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type StoryRemixController interface {
+	RemixStory(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
+}
+
+type storyRemixController struct {
+	logger        outbound.LoggerPort
+	workerPool    outbound.TaskDispatcher
+	remixer       inbound.StoryRemixerPort
+	storyDelivery inbound.StoryDeliveryPort
+	voiceCatalog  inbound.VoiceCatalogServicePort
+	storyHistory  inbound.StoryHistoryPort
+}
+
+func NewStoryRemixController(logger outbound.LoggerPort, workerPool outbound.TaskDispatcher, remixer inbound.StoryRemixerPort,
+	storyDelivery inbound.StoryDeliveryPort, voiceCatalog inbound.VoiceCatalogServicePort, storyHistory inbound.StoryHistoryPort) StoryRemixController {
+	return &storyRemixController{
+		logger:        logger,
+		workerPool:    workerPool,
+		remixer:       remixer,
+		storyDelivery: storyDelivery,
+		voiceCatalog:  voiceCatalog,
+		storyHistory:  storyHistory,
+	}
+}
+
+// RemixStory streams the variant the same way /generate streams a new story.
+func (s *storyRemixController) RemixStory(c *gin.Context) {
+	var request dto.RemixStoryRequest
+	newCtx, cancel := context.WithCancel(c)
+	defer cancel()
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.VoiceID != "" {
+		if err := s.voiceCatalog.Validate(newCtx, request.VoiceID); err != nil {
+			abortWithDomainError(c, s.logger, err)
+			return
+		}
+	}
+
+	userID := c.GetString(middleware.ContextUserIDKey)
+	storyID := uuid.NewString()
+	startedAt := time.Now().UTC()
+
+	remix, err := s.remixer.Remix(newCtx, inbound.RemixStoryParams{
+		SourceStoryID: c.Param("id"),
+		StoryID:       storyID,
+		UserID:        userID,
+		VoiceID:       request.VoiceID,
+		VoiceSettings: request.VoiceSettings.ToDomain(),
+		ImageStyle:    strings.TrimSpace(request.ImageStyle),
+	})
+	if err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+
+	err = s.storyHistory.Start(newCtx, inbound.StartStoryParams{
+		StoryID:    storyID,
+		UserID:     userID,
+		Parameters: remix.Parameters,
+		RemixOf:    remix.Source.ID,
+	})
+	if err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+	completed := false
+	defer func() {
+		if !completed {
+			failStory(s.logger, s.storyHistory, storyID, userID)
+		}
+	}()
+
+	err = s.workerPool.Submit(func() {
+		var sendErrOnce sync.Once
+		for err := range remix.Errors {
+			cancel()
+			s.logger.Error(err, "error in remix pipeline")
+			sendErrOnce.Do(func() {
+				c.SSEvent("error", "internal server error")
+				c.Abort()
+			})
+		}
+	})
+	if err != nil {
+		s.logger.Error(err, "failed to submit worker")
+		c.SSEvent("error", "internal server error")
+		return
+	}
+
+	events := make([]domain.SegmentEvent, 0)
+	for segmentEvents := remix.Segments; segmentEvents != nil; {
+		select {
+		case <-newCtx.Done():
+			return
+		case event, ok := <-segmentEvents:
+			if !ok {
+				segmentEvents = nil
+				continue
+			}
+			events = append(events, event)
+			c.SSEvent("segment", event)
+		}
+	}
+
+	storyAssets, ok := <-remix.Assets
+	if !ok {
+		return
+	}
+
+	events = domain.SortEventsInReadingOrder(events)
+	err = s.storyHistory.Complete(newCtx, inbound.CompleteStoryParams{
+		StoryID:  storyID,
+		UserID:   userID,
+		Segments: events,
+		Assets:   storyAssets,
+	})
+	if err != nil {
+		s.logger.Error(err, "failed to record story")
+		c.SSEvent("error", "internal server error")
+		return
+	}
+	completed = true
+
+	err = s.storyDelivery.Deliver(newCtx, outbound.SaveStoryParams{
+		ID:          storyID,
+		UserID:      userID,
+		Input:       remix.Source.Parameters.Input,
+		RemixOf:     remix.Source.ID,
+		Parameters:  remix.Parameters,
+		Segments:    events,
+		Assets:      storyAssets,
+		StartedAt:   startedAt,
+		CompletedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		s.logger.Error(err, "failed to queue story for delivery")
+		c.SSEvent("error", "internal server error")
+		return
+	}
+
+	c.SSEvent("generation_complete", storyAssets.ToEvent())
+}
+
+func (s *storyRemixController) RegisterRoutes(g gin.IRouter) {
+	g.POST("/stories/:id/remix", s.RemixStory)
+}
@@ -2,41 +2,54 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"generate-script-lambda/application/ports/inbound"
 	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/domain"
 	"generate-script-lambda/infrastructure/gin_interface/dto"
 	"generate-script-lambda/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"sync"
+	"time"
 )
 
+const storyRecordTimeout = 10 * time.Second
+
 type StorySegmentsController interface {
 	CreateStory(c *gin.Context)
-	RegisterRoutes(g *gin.Engine)
+	CreateManuscriptStory(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
 }
 
 type storySegmentsController struct {
 	logger               outbound.LoggerPort
 	workerPool           outbound.TaskDispatcher
 	pipelineOrchestrator inbound.SegmentPipelineOrchestrator
-	storySaver           outbound.StorySaverPort
+	storyDelivery        inbound.StoryDeliveryPort
+	voiceCatalog         inbound.VoiceCatalogServicePort
+	storyHistory         inbound.StoryHistoryPort
+	lexiconManager       inbound.LexiconManagerPort
 }
 
 func NewStorySegmentsController(logger outbound.LoggerPort, workerPool outbound.TaskDispatcher,
-	pipelineOrchestrator inbound.SegmentPipelineOrchestrator, storySaver outbound.StorySaverPort) StorySegmentsController {
+	pipelineOrchestrator inbound.SegmentPipelineOrchestrator, storyDelivery inbound.StoryDeliveryPort,
+	voiceCatalog inbound.VoiceCatalogServicePort, storyHistory inbound.StoryHistoryPort,
+	lexiconManager inbound.LexiconManagerPort) StorySegmentsController {
 	return &storySegmentsController{
 		logger:               logger,
 		workerPool:           workerPool,
 		pipelineOrchestrator: pipelineOrchestrator,
-		storySaver:           storySaver,
+		storyDelivery:        storyDelivery,
+		voiceCatalog:         voiceCatalog,
+		storyHistory:         storyHistory,
+		lexiconManager:       lexiconManager,
 	}
 }
 
 func (s *storySegmentsController) CreateStory(c *gin.Context) {
 	var createStoryRequest dto.CreateStoryRequest
-	newCtx, cancel := context.WithCancel(c)
-	defer cancel()
 	if err := c.ShouldBindJSON(&createStoryRequest); err != nil {
 		err = c.AbortWithError(400, err)
 		if err != nil {
@@ -45,18 +58,113 @@ func (s *storySegmentsController) CreateStory(c *gin.Context) {
 		return
 	}
 
+	s.generateStory(c, storyGeneration{
+		Parameters: domain.StoryParameters{
+			Input:         createStoryRequest.Input,
+			VoiceID:       createStoryRequest.VoiceID,
+			VoiceSettings: createStoryRequest.VoiceSettings.ToDomain(),
+			Language:      createStoryRequest.Language,
+			LexiconID:     createStoryRequest.LexiconID,
+		},
+		StreamAudio: createStoryRequest.StreamAudio,
+	})
+}
+
+func (s *storySegmentsController) CreateManuscriptStory(c *gin.Context) {
+	var manuscriptRequest dto.ManuscriptRequest
+	if err := c.ShouldBindJSON(&manuscriptRequest); err != nil {
+		err = c.AbortWithError(400, err)
+		if err != nil {
+			s.logger.Error(err, "failed to abort with error")
+		}
+		return
+	}
+
+	s.generateStory(c, storyGeneration{
+		Parameters: domain.StoryParameters{
+			Input:         manuscriptRequest.Manuscript,
+			VoiceID:       manuscriptRequest.VoiceID,
+			VoiceSettings: manuscriptRequest.VoiceSettings.ToDomain(),
+			Language:      manuscriptRequest.Language,
+			LexiconID:     manuscriptRequest.LexiconID,
+			Source:        domain.ManuscriptStorySource,
+		},
+		StreamAudio:   manuscriptRequest.StreamAudio,
+		SuggestScenes: manuscriptRequest.SuggestScenes,
+	})
+}
+
+type storyGeneration struct {
+	Parameters    domain.StoryParameters
+	StreamAudio   bool
+	SuggestScenes bool
+}
+
+// generateStory runs the pipeline for a new story and streams its segments
+// as server-sent events.
+func (s *storySegmentsController) generateStory(c *gin.Context, generation storyGeneration) {
+	newCtx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	parameters := generation.Parameters
+	if err := s.pipelineOrchestrator.ValidateInput(parameters.Source, parameters.Input); err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+	if err := s.voiceCatalog.Validate(newCtx, parameters.VoiceID); err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+
 	userID := c.GetString(middleware.ContextUserIDKey)
+	if parameters.LexiconID != "" {
+		if _, err := s.lexiconManager.Get(newCtx, userID, parameters.LexiconID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				err = fmt.Errorf("%w: lexicon %s", domain.ErrInvalidInput, parameters.LexiconID)
+			}
+			abortWithDomainError(c, s.logger, err)
+			return
+		}
+	}
 
 	storyID := uuid.NewString()
+	startedAt := time.Now().UTC()
+
+	err := s.storyHistory.Start(newCtx, inbound.StartStoryParams{
+		StoryID:    storyID,
+		UserID:     userID,
+		Parameters: parameters,
+	})
+	if err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+	completed := false
+	defer func() {
+		if !completed {
+			failStory(s.logger, s.storyHistory, storyID, userID)
+		}
+	}()
 
-	segmentEvents, errCh := s.pipelineOrchestrator.StartPipeline(newCtx, inbound.StartPipelineParams{
-		Input:   createStoryRequest.Input,
-		StoryID: storyID,
-		VoiceID: createStoryRequest.VoiceID,
-		UserID:  userID,
+	var audioChunks chan domain.AudioChunk
+	if generation.StreamAudio {
+		audioChunks = make(chan domain.AudioChunk)
+	}
+
+	segmentEvents, storyAssetsCh, errCh := s.pipelineOrchestrator.StartPipeline(newCtx, inbound.StartPipelineParams{
+		Input:         parameters.Input,
+		Source:        parameters.Source,
+		SuggestScenes: generation.SuggestScenes,
+		StoryID:       storyID,
+		VoiceID:       parameters.VoiceID,
+		VoiceSettings: parameters.VoiceSettings,
+		Language:      parameters.Language,
+		LexiconID:     parameters.LexiconID,
+		UserID:        userID,
+		AudioChunks:   audioChunks,
 	})
 
-	err := s.workerPool.Submit(func() {
+	err = s.workerPool.Submit(func() {
 		var sendErrOnce sync.Once
 		for err := range errCh {
 			cancel()
@@ -73,37 +181,88 @@ func (s *storySegmentsController) CreateStory(c *gin.Context) {
 		return
 	}
 
-	for event := range segmentEvents {
+	events := make([]domain.SegmentEvent, 0)
+	for segmentEvents != nil {
 		select {
 		case <-newCtx.Done():
 			return
-		default:
+		case chunk, ok := <-audioChunks:
+			if !ok {
+				audioChunks = nil
+				continue
+			}
+			c.SSEvent("audio_chunk", chunk)
+		case event, ok := <-segmentEvents:
+			if !ok {
+				segmentEvents = nil
+				continue
+			}
+			events = append(events, event)
 			c.SSEvent("segment", event)
 		}
 	}
 
+	storyAssets, ok := <-storyAssetsCh
+	if !ok {
+		return
+	}
+
 	s.logger.InfoWithFields("segments generation complete", map[string]interface{}{
 		"story_id": storyID,
 	})
 
-	err = s.storySaver.Save(newCtx, outbound.SaveStoryParams{
-		ID:     storyID,
-		UserID: userID,
-		Input:  createStoryRequest.Input,
+	// The story is recorded as completed before it is queued, so a delivery
+	// failure cannot mark a story that already went out as failed.
+	events = domain.SortEventsInReadingOrder(events)
+	err = s.storyHistory.Complete(newCtx, inbound.CompleteStoryParams{
+		StoryID:  storyID,
+		UserID:   userID,
+		Segments: events,
+		Assets:   storyAssets,
 	})
 	if err != nil {
-		s.logger.Error(err, "failed to save story")
+		s.logger.Error(err, "failed to record story")
 		c.SSEvent("error", "internal server error")
 		return
-	} else {
-		s.logger.InfoWithFields("story saved", map[string]interface{}{
+	}
+	completed = true
+
+	err = s.storyDelivery.Deliver(newCtx, outbound.SaveStoryParams{
+		ID:          storyID,
+		UserID:      userID,
+		Input:       parameters.Input,
+		Parameters:  parameters,
+		Segments:    events,
+		Assets:      storyAssets,
+		StartedAt:   startedAt,
+		CompletedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		s.logger.Error(err, "failed to queue story for delivery")
+		c.SSEvent("error", "internal server error")
+		return
+	}
+	s.logger.InfoWithFields("story queued for delivery", map[string]interface{}{
+		"story_id": storyID,
+	})
+
+	c.SSEvent("generation_complete", storyAssets.ToEvent())
+}
+
+// failStory runs after the request context may already be cancelled, so it
+// records the failure on a context of its own.
+func failStory(logger outbound.LoggerPort, storyHistory inbound.StoryHistoryPort, storyID string, userID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), storyRecordTimeout)
+	defer cancel()
+
+	if err := storyHistory.Fail(ctx, storyID, userID); err != nil {
+		logger.ErrorWithFields(err, "failed to mark story as failed", map[string]interface{}{
 			"story_id": storyID,
 		})
 	}
-
-	c.SSEvent("generation_complete", nil)
 }
 
-func (s *storySegmentsController) RegisterRoutes(g *gin.Engine) {
+func (s *storySegmentsController) RegisterRoutes(g gin.IRouter) {
 	g.POST("/generate", s.CreateStory)
+	g.POST("/generate/manuscript", s.CreateManuscriptStory)
 }
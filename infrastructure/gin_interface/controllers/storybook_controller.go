@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"generate-script-lambda/middleware"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type StorybookController interface {
+	PublishStorybook(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
+}
+
+type storybookController struct {
+	logger    outbound.LoggerPort
+	publisher inbound.StorybookPublisherPort
+}
+
+func NewStorybookController(logger outbound.LoggerPort, publisher inbound.StorybookPublisherPort) StorybookController {
+	return &storybookController{
+		logger:    logger,
+		publisher: publisher,
+	}
+}
+
+func (s *storybookController) PublishStorybook(c *gin.Context) {
+	storybook, err := s.publisher.Publish(c, inbound.PublishStorybookParams{
+		StoryID: c.Param("id"),
+		UserID:  c.GetString(middleware.ContextUserIDKey),
+		Tenant:  c.GetString(middleware.ContextTenantKey),
+	})
+	if err != nil {
+		abortWithDomainError(c, s.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, storybook)
+}
+
+func (s *storybookController) RegisterRoutes(g gin.IRouter) {
+	g.POST("/stories/:id/storybook", s.PublishStorybook)
+}
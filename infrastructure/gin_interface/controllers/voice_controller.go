@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"generate-script-lambda/application/ports/inbound"
+	"generate-script-lambda/application/ports/outbound"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type VoiceController interface {
+	ListVoices(c *gin.Context)
+	RegisterRoutes(g gin.IRouter)
+}
+
+type voiceController struct {
+	logger       outbound.LoggerPort
+	voiceCatalog inbound.VoiceCatalogServicePort
+}
+
+func NewVoiceController(logger outbound.LoggerPort, voiceCatalog inbound.VoiceCatalogServicePort) VoiceController {
+	return &voiceController{
+		logger:       logger,
+		voiceCatalog: voiceCatalog,
+	}
+}
+
+func (v *voiceController) ListVoices(c *gin.Context) {
+	voices, err := v.voiceCatalog.List(c, inbound.VoiceFilter{
+		Provider: c.Query("provider"),
+		Language: c.Query("language"),
+		Gender:   c.Query("gender"),
+		Age:      c.Query("age"),
+	})
+	if err != nil {
+		abortWithDomainError(c, v.logger, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.JSON(http.StatusOK, voices)
+}
+
+func (v *voiceController) RegisterRoutes(g gin.IRouter) {
+	g.GET("/voices", v.ListVoices)
+}
@@ -1,6 +1,33 @@
 package dto
 
+import "generate-script-lambda/domain"
+
 type CreateStoryRequest struct {
-	Input   string `json:"input" binding:"required"`
-	VoiceID string `json:"voice_id" binding:"required"`
+	Input         string                `json:"input" binding:"required"`
+	VoiceID       string                `json:"voice_id" binding:"required"`
+	VoiceSettings *VoiceSettingsRequest `json:"voice_settings"`
+	Language      string                `json:"language"`
+	LexiconID     string                `json:"lexicon_id"`
+	StreamAudio   bool                  `json:"stream_audio"`
+}
+
+// VoiceSettingsRequest fields left out keep the configured defaults.
+type VoiceSettingsRequest struct {
+	Stability       *float64 `json:"stability" binding:"omitempty,min=0,max=1"`
+	SimilarityBoost *float64 `json:"similarity_boost" binding:"omitempty,min=0,max=1"`
+	Style           *float64 `json:"style" binding:"omitempty,min=0,max=1"`
+	Speed           *float64 `json:"speed" binding:"omitempty,min=0.7,max=1.2"`
+}
+
+func (v *VoiceSettingsRequest) ToDomain() *domain.VoiceSettingsOverride {
+	if v == nil {
+		return nil
+	}
+
+	return &domain.VoiceSettingsOverride{
+		Stability:       v.Stability,
+		SimilarityBoost: v.SimilarityBoost,
+		Style:           v.Style,
+		Speed:           v.Speed,
+	}
 }
@@ -0,0 +1,5 @@
+package dto
+
+type EditSegmentRequest struct {
+	Text string `json:"text" binding:"required,max=5000"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "generate-script-lambda/domain"
+
+type LexiconRequest struct {
+	Name    string                `json:"name" binding:"required"`
+	StoryID string                `json:"story_id"`
+	Entries []domain.LexiconEntry `json:"entries" binding:"required"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+import "generate-script-lambda/domain"
+
+type ListSegmentsRequest struct {
+	Type   string `form:"type" binding:"omitempty,oneof=audio image"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+	Cursor string `form:"cursor"`
+}
+
+func (r ListSegmentsRequest) SegmentType() domain.SegmentType {
+	return domain.SegmentType(r.Type)
+}
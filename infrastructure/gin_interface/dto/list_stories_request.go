@@ -0,0 +1,18 @@
+package dto
+
+import "generate-script-lambda/domain"
+
+type ListStoriesRequest struct {
+	Sort   string `form:"sort" binding:"omitempty,oneof=created_at updated_at"`
+	Order  string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+}
+
+func (r ListStoriesRequest) SortField() domain.StorySortField {
+	return domain.StorySortField(r.Sort)
+}
+
+func (r ListStoriesRequest) Ascending() bool {
+	return r.Order == "asc"
+}
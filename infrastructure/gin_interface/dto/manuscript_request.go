@@ -0,0 +1,14 @@
+package dto
+
+// ManuscriptRequest narrates user-written plain text or Markdown. Scenes are
+// separated by [scene] or [scene: description] marker lines, headings or
+// thematic breaks.
+type ManuscriptRequest struct {
+	Manuscript    string                `json:"manuscript" binding:"required,max=100000"`
+	VoiceID       string                `json:"voice_id" binding:"required"`
+	VoiceSettings *VoiceSettingsRequest `json:"voice_settings"`
+	Language      string                `json:"language"`
+	LexiconID     string                `json:"lexicon_id"`
+	StreamAudio   bool                  `json:"stream_audio"`
+	SuggestScenes bool                  `json:"suggest_scenes"`
+}
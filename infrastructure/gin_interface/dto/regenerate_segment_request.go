@@ -0,0 +1,9 @@
+package dto
+
+// RegenerateSegmentRequest may be empty, in which case the segment is
+// regenerated from its current text with the story's voice.
+type RegenerateSegmentRequest struct {
+	Text          string                `json:"text"`
+	VoiceID       string                `json:"voice_id"`
+	VoiceSettings *VoiceSettingsRequest `json:"voice_settings"`
+}
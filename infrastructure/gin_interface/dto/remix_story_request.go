@@ -0,0 +1,8 @@
+package dto
+
+// RemixStoryRequest needs a voice id, an image style or both.
+type RemixStoryRequest struct {
+	VoiceID       string                `json:"voice_id"`
+	VoiceSettings *VoiceSettingsRequest `json:"voice_settings"`
+	ImageStyle    string                `json:"image_style" binding:"max=100"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type SegmentMediaRequest struct {
+	Version int `form:"version" binding:"omitempty,min=1"`
+}
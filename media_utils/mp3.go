@@ -0,0 +1,317 @@
+package media_utils
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+var ErrNotMp3 = errors.New("content is not an mp3 stream")
+
+const (
+	id3v2HeaderSize = 10
+	id3v1TagSize    = 128
+)
+
+var mp3BitratesV1 = [3][16]int{
+	{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0},
+	{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},
+	{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+}
+
+var mp3BitratesV2 = [3][16]int{
+	{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0},
+	{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+}
+
+var mp3SampleRates = map[int][3]int{
+	1:  {44100, 48000, 32000},
+	2:  {22050, 24000, 16000},
+	25: {11025, 12000, 8000},
+}
+
+type Mp3FrameHeader struct {
+	Version         int
+	Layer           int
+	Bitrate         int
+	SampleRate      int
+	Padding         bool
+	Mono            bool
+	FrameLength     int
+	SamplesPerFrame int
+}
+
+func (h Mp3FrameHeader) Duration() time.Duration {
+	return time.Duration(h.SamplesPerFrame) * time.Second / time.Duration(h.SampleRate)
+}
+
+type Mp3Frame struct {
+	Header Mp3FrameHeader
+	Data   []byte
+}
+
+type Mp3Part struct {
+	Content      []byte
+	SilenceAfter time.Duration
+}
+
+func ParseMp3FrameHeader(b []byte) (Mp3FrameHeader, bool) {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return Mp3FrameHeader{}, false
+	}
+
+	var version int
+	switch (b[1] >> 3) & 0x03 {
+	case 0:
+		version = 25
+	case 2:
+		version = 2
+	case 3:
+		version = 1
+	default:
+		return Mp3FrameHeader{}, false
+	}
+
+	layer := 4 - int((b[1]>>1)&0x03)
+	if layer == 4 {
+		return Mp3FrameHeader{}, false
+	}
+
+	bitrateIndex := int(b[2] >> 4)
+	sampleRateIndex := int((b[2] >> 2) & 0x03)
+	if bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		return Mp3FrameHeader{}, false
+	}
+
+	var bitrate int
+	if version == 1 {
+		bitrate = mp3BitratesV1[layer-1][bitrateIndex]
+	} else {
+		bitrate = mp3BitratesV2[layer-1][bitrateIndex]
+	}
+
+	header := Mp3FrameHeader{
+		Version:    version,
+		Layer:      layer,
+		Bitrate:    bitrate,
+		SampleRate: mp3SampleRates[version][sampleRateIndex],
+		Padding:    (b[2]>>1)&0x01 == 1,
+		Mono:       b[3]>>6 == 3,
+	}
+
+	padding := 0
+	if header.Padding {
+		padding = 1
+	}
+
+	switch {
+	case layer == 1:
+		header.SamplesPerFrame = 384
+		header.FrameLength = (12*bitrate*1000/header.SampleRate + padding) * 4
+	case layer == 3 && version != 1:
+		header.SamplesPerFrame = 576
+		header.FrameLength = 72*bitrate*1000/header.SampleRate + padding
+	default:
+		header.SamplesPerFrame = 1152
+		header.FrameLength = 144*bitrate*1000/header.SampleRate + padding
+	}
+
+	return header, true
+}
+
+// ParseMp3Frames returns the audio frames of an mp3 stream, skipping ID3 tags
+// and the Xing/Info/VBRI frame encoders put in front of the audio.
+func ParseMp3Frames(content []byte) ([]Mp3Frame, error) {
+	offset := skipId3v2(content)
+	end := len(content)
+	if end-offset >= id3v1TagSize && bytes.Equal(content[end-id3v1TagSize:end-id3v1TagSize+3], []byte("TAG")) {
+		end -= id3v1TagSize
+	}
+
+	frames := make([]Mp3Frame, 0)
+	for offset+4 <= end {
+		header, ok := ParseMp3FrameHeader(content[offset:end])
+		if !ok || offset+header.FrameLength > end {
+			if len(frames) == 0 {
+				offset++
+				continue
+			}
+			break
+		}
+
+		frame := Mp3Frame{
+			Header: header,
+			Data:   content[offset : offset+header.FrameLength],
+		}
+		if len(frames) > 0 || !isVbrInfoFrame(frame) {
+			frames = append(frames, frame)
+		}
+		offset += header.FrameLength
+	}
+
+	if len(frames) == 0 {
+		return nil, ErrNotMp3
+	}
+
+	return frames, nil
+}
+
+// ConcatenateMp3 joins mp3 streams frame by frame without re-encoding,
+// appending silent frames after every part that requests a pause. It also
+// returns where each part starts on the resulting timeline.
+func ConcatenateMp3(parts []Mp3Part) ([]byte, []time.Duration, error) {
+	var buffer bytes.Buffer
+	offsets := make([]time.Duration, len(parts))
+	var position time.Duration
+
+	for i, part := range parts {
+		frames, err := ParseMp3Frames(part.Content)
+		if err != nil {
+			return nil, nil, err
+		}
+		offsets[i] = position
+		for _, frame := range frames {
+			buffer.Write(frame.Data)
+			position += frame.Header.Duration()
+		}
+		if part.SilenceAfter > 0 {
+			reference := frames[len(frames)-1].Header
+			silence := Mp3Silence(reference, part.SilenceAfter)
+			buffer.Write(silence)
+			position += time.Duration(len(silence)/reference.frameLengthWithoutPadding()) * reference.Duration()
+		}
+	}
+
+	return buffer.Bytes(), offsets, nil
+}
+
+// Mp3Silence builds silent frames with the same stream parameters as the
+// reference header. A frame whose side information and main data are all
+// zero decodes to silence, so no encoder is needed.
+func Mp3Silence(reference Mp3FrameHeader, duration time.Duration) []byte {
+	frameLength := reference.frameLengthWithoutPadding()
+	reference.Padding = false
+	reference.FrameLength = frameLength
+	frameDuration := reference.Duration()
+	frameCount := int((duration + frameDuration - 1) / frameDuration)
+
+	frame := make([]byte, frameLength)
+	copy(frame, reference.bytes())
+
+	return bytes.Repeat(frame, frameCount)
+}
+
+func (h Mp3FrameHeader) frameLengthWithoutPadding() int {
+	if h.Padding {
+		if h.Layer == 1 {
+			return h.FrameLength - 4
+		}
+		return h.FrameLength - 1
+	}
+	return h.FrameLength
+}
+
+func (h Mp3FrameHeader) bytes() []byte {
+	var versionBits byte
+	switch h.Version {
+	case 1:
+		versionBits = 3
+	case 2:
+		versionBits = 2
+	default:
+		versionBits = 0
+	}
+	sampleRates := mp3SampleRates[h.Version]
+
+	bitrates := mp3BitratesV2[h.Layer-1]
+	if h.Version == 1 {
+		bitrates = mp3BitratesV1[h.Layer-1]
+	}
+
+	var bitrateIndex, sampleRateIndex byte
+	for i, bitrate := range bitrates {
+		if bitrate == h.Bitrate && i != 0 {
+			bitrateIndex = byte(i)
+			break
+		}
+	}
+	for i, sampleRate := range sampleRates {
+		if sampleRate == h.SampleRate {
+			sampleRateIndex = byte(i)
+			break
+		}
+	}
+
+	var padding byte
+	if h.Padding {
+		padding = 1
+	}
+	var channelMode byte
+	if h.Mono {
+		channelMode = 3
+	}
+
+	return []byte{
+		0xFF,
+		0xE0 | versionBits<<3 | byte(4-h.Layer)<<1 | 0x01,
+		bitrateIndex<<4 | sampleRateIndex<<2 | padding<<1,
+		channelMode << 6,
+	}
+}
+
+func (h Mp3FrameHeader) sideInfoLength() int {
+	if h.Version == 1 {
+		if h.Mono {
+			return 17
+		}
+		return 32
+	}
+	if h.Mono {
+		return 9
+	}
+	return 17
+}
+
+func isVbrInfoFrame(frame Mp3Frame) bool {
+	xingOffset := 4 + frame.Header.sideInfoLength()
+	if len(frame.Data) >= xingOffset+4 {
+		tag := frame.Data[xingOffset : xingOffset+4]
+		if bytes.Equal(tag, []byte("Xing")) || bytes.Equal(tag, []byte("Info")) {
+			return true
+		}
+	}
+
+	const vbriOffset = 36
+	return len(frame.Data) >= vbriOffset+4 && bytes.Equal(frame.Data[vbriOffset:vbriOffset+4], []byte("VBRI"))
+}
+
+func skipId3v2(content []byte) int {
+	offset := 0
+	for len(content)-offset >= id3v2HeaderSize && bytes.Equal(content[offset:offset+3], []byte("ID3")) {
+		header := content[offset : offset+id3v2HeaderSize]
+		size := int(header[6])<<21 | int(header[7])<<14 | int(header[8])<<7 | int(header[9])
+		offset += id3v2HeaderSize + size
+		if header[5]&0x10 != 0 {
+			offset += id3v2HeaderSize
+		}
+	}
+	if offset > len(content) {
+		return len(content)
+	}
+	return offset
+}
+
+func Mp3Duration(content []byte) (time.Duration, error) {
+	frames, err := ParseMp3Frames(content)
+	if err != nil {
+		return 0, err
+	}
+
+	var duration time.Duration
+	for _, frame := range frames {
+		duration += frame.Header.Duration()
+	}
+
+	return duration, nil
+}
@@ -0,0 +1,76 @@
+package media_utils
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func testMp3Frame(fill byte) []byte {
+	frame := bytes.Repeat([]byte{fill}, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+	return frame
+}
+
+func testXingFrame() []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+	copy(frame[36:], "Xing")
+	return frame
+}
+
+func TestParseMp3FrameHeader(t *testing.T) {
+	header, ok := ParseMp3FrameHeader(testMp3Frame(0))
+	if !ok {
+		t.Fatal("Expected a valid frame header")
+	}
+	if header.Version != 1 || header.Layer != 3 || header.Bitrate != 128 || header.SampleRate != 44100 {
+		t.Fatalf("Unexpected header: %+v", header)
+	}
+	if header.FrameLength != 417 || header.SamplesPerFrame != 1152 {
+		t.Fatalf("Unexpected frame size: %+v", header)
+	}
+}
+
+func TestConcatenateMp3(t *testing.T) {
+	id3 := append([]byte("ID3\x04\x00\x00\x00\x00\x00\x05"), []byte("xxxxx")...)
+	first := append(append(append([]byte{}, id3...), testXingFrame()...), testMp3Frame(1)...)
+	second := append(testMp3Frame(2), testMp3Frame(3)...)
+
+	content, offsets, err := ConcatenateMp3([]Mp3Part{
+		{Content: first, SilenceAfter: 50 * time.Millisecond},
+		{Content: second},
+	})
+	if err != nil {
+		t.Fatal("Failed to concatenate:", err)
+	}
+
+	frameDuration := 1152 * time.Second / 44100
+	if offsets[0] != 0 || offsets[1] != 3*frameDuration {
+		t.Fatalf("Unexpected part offsets: %v", offsets)
+	}
+
+	frames, err := ParseMp3Frames(content)
+	if err != nil {
+		t.Fatal("Failed to parse concatenated stream:", err)
+	}
+
+	// 50ms of silence at 1152 samples / 44.1kHz needs two frames.
+	if len(frames) != 5 {
+		t.Fatalf("Expected 5 frames, got %d", len(frames))
+	}
+	if frames[0].Data[4] != 1 || frames[3].Data[4] != 2 || frames[4].Data[4] != 3 {
+		t.Fatal("Frames are out of order")
+	}
+	for _, silent := range frames[1:3] {
+		if !bytes.Equal(silent.Data[4:], make([]byte, 413)) {
+			t.Fatal("Expected silent frame to have an empty body")
+		}
+	}
+}
+
+func TestParseMp3Frames_NotMp3(t *testing.T) {
+	if _, err := ParseMp3Frames([]byte("RIFF....WAVEfmt ")); err != ErrNotMp3 {
+		t.Fatalf("Expected ErrNotMp3, got %v", err)
+	}
+}
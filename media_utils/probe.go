@@ -0,0 +1,113 @@
+package media_utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"time"
+)
+
+var ErrUnknownMedia = errors.New("unrecognised media content")
+
+type MediaInfo struct {
+	MimeType   string
+	Width      int
+	Height     int
+	Duration   time.Duration
+	Bitrate    int
+	SampleRate int
+}
+
+// ProbeMedia reads the container headers of the formats the generators
+// produce without decoding the payload.
+func ProbeMedia(content []byte) (MediaInfo, error) {
+	switch {
+	case isRiff(content, "WEBP"):
+		return probeWebp(content)
+	case isRiff(content, "WAVE"):
+		info, err := ParseWav(content)
+		if err != nil {
+			return MediaInfo{}, err
+		}
+		return MediaInfo{
+			MimeType:   "audio/wav",
+			Duration:   info.Duration,
+			Bitrate:    info.SampleRate * info.Channels * info.BitDepth,
+			SampleRate: info.SampleRate,
+		}, nil
+	}
+
+	if config, format, err := image.DecodeConfig(bytes.NewReader(content)); err == nil {
+		return MediaInfo{
+			MimeType: "image/" + format,
+			Width:    config.Width,
+			Height:   config.Height,
+		}, nil
+	}
+
+	if frames, err := ParseMp3Frames(content); err == nil {
+		return probeMp3(frames), nil
+	}
+
+	return MediaInfo{}, ErrUnknownMedia
+}
+
+func probeMp3(frames []Mp3Frame) MediaInfo {
+	var duration time.Duration
+	var bitrateSum int
+	for _, frame := range frames {
+		duration += frame.Header.Duration()
+		bitrateSum += frame.Header.Bitrate
+	}
+
+	return MediaInfo{
+		MimeType:   "audio/mpeg",
+		Duration:   duration,
+		Bitrate:    bitrateSum * 1000 / len(frames),
+		SampleRate: frames[0].Header.SampleRate,
+	}
+}
+
+func probeWebp(content []byte) (MediaInfo, error) {
+	if len(content) < 30 {
+		return MediaInfo{}, ErrUnknownMedia
+	}
+
+	info := MediaInfo{MimeType: "image/webp"}
+	switch string(content[12:16]) {
+	case "VP8 ":
+		info.Width = int(binary.LittleEndian.Uint16(content[26:28]) & 0x3fff)
+		info.Height = int(binary.LittleEndian.Uint16(content[28:30]) & 0x3fff)
+	case "VP8L":
+		bits := binary.LittleEndian.Uint32(content[21:25])
+		info.Width = int(bits&0x3fff) + 1
+		info.Height = int((bits>>14)&0x3fff) + 1
+	case "VP8X":
+		info.Width = int(uint32(content[24])|uint32(content[25])<<8|uint32(content[26])<<16) + 1
+		info.Height = int(uint32(content[27])|uint32(content[28])<<8|uint32(content[29])<<16) + 1
+	default:
+		return MediaInfo{}, ErrUnknownMedia
+	}
+
+	return info, nil
+}
+
+func isRiff(content []byte, format string) bool {
+	return len(content) >= 12 && bytes.Equal(content[0:4], []byte("RIFF")) && string(content[8:12]) == format
+}
+
+// DetectContentType prefers the probed media type and falls back to the
+// standard library sniffer for anything else.
+func DetectContentType(content []byte) string {
+	info, err := ProbeMedia(content)
+	if err == nil {
+		return info.MimeType
+	}
+
+	return http.DetectContentType(content)
+}
@@ -0,0 +1,54 @@
+package media_utils
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestProbeMedia_Png(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, 64, 32))); err != nil {
+		t.Fatal("Failed to encode png:", err)
+	}
+
+	info, err := ProbeMedia(buffer.Bytes())
+	if err != nil {
+		t.Fatal("Failed to probe png:", err)
+	}
+	if info.MimeType != "image/png" || info.Width != 64 || info.Height != 32 {
+		t.Fatalf("Unexpected png info: %+v", info)
+	}
+}
+
+func TestProbeMedia_Webp(t *testing.T) {
+	content := append([]byte("RIFF\x00\x00\x00\x00WEBPVP8L\x00\x00\x00\x00\x2f"), 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	// 1024x768 packed as (width-1) | (height-1) << 14.
+	bits := uint32(1023) | uint32(767)<<14
+	content[21], content[22], content[23], content[24] = byte(bits), byte(bits>>8), byte(bits>>16), byte(bits>>24)
+
+	info, err := ProbeMedia(content)
+	if err != nil {
+		t.Fatal("Failed to probe webp:", err)
+	}
+	if info.MimeType != "image/webp" || info.Width != 1024 || info.Height != 768 {
+		t.Fatalf("Unexpected webp info: %+v", info)
+	}
+}
+
+func TestProbeMedia_Mp3(t *testing.T) {
+	content := append(testXingFrame(), append(testMp3Frame(1), testMp3Frame(2)...)...)
+
+	info, err := ProbeMedia(content)
+	if err != nil {
+		t.Fatal("Failed to probe mp3:", err)
+	}
+	if info.MimeType != "audio/mpeg" || info.Bitrate != 128000 || info.SampleRate != 44100 {
+		t.Fatalf("Unexpected mp3 info: %+v", info)
+	}
+	if info.Duration != 2*(1152*time.Second/44100) {
+		t.Fatalf("Unexpected mp3 duration: %v", info.Duration)
+	}
+}
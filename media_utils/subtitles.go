@@ -0,0 +1,87 @@
+package media_utils
+
+import (
+	"fmt"
+	"generate-script-lambda/domain"
+	"strings"
+)
+
+const (
+	maxWordsPerCue   = 8
+	maxSecondsPerCue = 4.0
+)
+
+type SubtitleCue struct {
+	Start float64
+	End   float64
+	Text  string
+}
+
+// BuildSubtitleCues groups word timings into cues, breaking after sentence
+// punctuation or when a cue grows too long. offset shifts every cue, which is
+// how segment cues are placed on the whole-story timeline.
+func BuildSubtitleCues(words []domain.WordTiming, offset float64) []SubtitleCue {
+	cues := make([]SubtitleCue, 0)
+
+	current := make([]domain.WordTiming, 0, maxWordsPerCue)
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		text := make([]string, len(current))
+		for i, word := range current {
+			text[i] = word.Word
+		}
+		cues = append(cues, SubtitleCue{
+			Start: current[0].Start + offset,
+			End:   current[len(current)-1].End + offset,
+			Text:  strings.Join(text, " "),
+		})
+		current = current[:0]
+	}
+
+	for _, word := range words {
+		if len(current) > 0 && (len(current) == maxWordsPerCue || word.End-current[0].Start > maxSecondsPerCue) {
+			flush()
+		}
+		current = append(current, word)
+		if strings.ContainsAny(word.Word[len(word.Word)-1:], ".!?") {
+			flush()
+		}
+	}
+	flush()
+
+	return cues
+}
+
+func RenderWebVTT(cues []SubtitleCue) []byte {
+	var builder strings.Builder
+	builder.WriteString("WEBVTT\n")
+	for _, cue := range cues {
+		builder.WriteString(fmt.Sprintf("\n%s --> %s\n%s\n", formatSubtitleTime(cue.Start, "."), formatSubtitleTime(cue.End, "."), cue.Text))
+	}
+
+	return []byte(builder.String())
+}
+
+func RenderSRT(cues []SubtitleCue) []byte {
+	var builder strings.Builder
+	for i, cue := range cues {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(fmt.Sprintf("%d\n%s --> %s\n%s\n", i+1, formatSubtitleTime(cue.Start, ","), formatSubtitleTime(cue.End, ","), cue.Text))
+	}
+
+	return []byte(builder.String())
+}
+
+func formatSubtitleTime(seconds float64, fractionSeparator string) string {
+	totalMs := int64(seconds*1000 + 0.5)
+	hours := totalMs / 3600000
+	minutes := totalMs % 3600000 / 60000
+	secs := totalMs % 60000 / 1000
+	ms := totalMs % 1000
+
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", hours, minutes, secs, fractionSeparator, ms)
+}
@@ -0,0 +1,29 @@
+package media_utils
+
+import (
+	"generate-script-lambda/domain"
+	"testing"
+)
+
+func TestRenderSubtitles(t *testing.T) {
+	alignment := domain.NewAlignmentFromCharacters([]domain.CharacterTiming{
+		{Character: "H", Start: 0, End: 0.1},
+		{Character: "i", Start: 0.1, End: 0.2},
+		{Character: ".", Start: 0.2, End: 0.3},
+		{Character: " ", Start: 0.3, End: 0.4},
+		{Character: "Y", Start: 0.4, End: 0.5},
+		{Character: "o", Start: 0.5, End: 0.6},
+	})
+
+	cues := BuildSubtitleCues(alignment.Words, 61)
+
+	expectedVtt := "WEBVTT\n\n00:01:01.000 --> 00:01:01.300\nHi.\n\n00:01:01.400 --> 00:01:01.600\nYo\n"
+	if vtt := string(RenderWebVTT(cues)); vtt != expectedVtt {
+		t.Fatalf("Unexpected WebVTT:\n%s", vtt)
+	}
+
+	expectedSrt := "1\n00:01:01,000 --> 00:01:01,300\nHi.\n\n2\n00:01:01,400 --> 00:01:01,600\nYo\n"
+	if srt := string(RenderSRT(cues)); srt != expectedSrt {
+		t.Fatalf("Unexpected SRT:\n%s", srt)
+	}
+}
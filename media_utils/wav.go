@@ -0,0 +1,54 @@
+package media_utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+var ErrNotWav = errors.New("content is not a wav file")
+
+type WavInfo struct {
+	SampleRate int
+	Channels   int
+	BitDepth   int
+	Duration   time.Duration
+}
+
+func ParseWav(content []byte) (WavInfo, error) {
+	if len(content) < 12 || !bytes.Equal(content[0:4], []byte("RIFF")) || !bytes.Equal(content[8:12], []byte("WAVE")) {
+		return WavInfo{}, ErrNotWav
+	}
+
+	var info WavInfo
+	var byteRate int
+	offset := 12
+	for offset+8 <= len(content) {
+		chunkID := content[offset : offset+4]
+		chunkSize := int(binary.LittleEndian.Uint32(content[offset+4 : offset+8]))
+		body := offset + 8
+
+		switch {
+		case bytes.Equal(chunkID, []byte("fmt ")) && body+16 <= len(content):
+			info.Channels = int(binary.LittleEndian.Uint16(content[body+2 : body+4]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(content[body+4 : body+8]))
+			byteRate = int(binary.LittleEndian.Uint32(content[body+8 : body+12]))
+			info.BitDepth = int(binary.LittleEndian.Uint16(content[body+14 : body+16]))
+		case bytes.Equal(chunkID, []byte("data")):
+			if byteRate == 0 {
+				return WavInfo{}, ErrNotWav
+			}
+			dataSize := chunkSize
+			if body+dataSize > len(content) || dataSize == 0xFFFFFFFF {
+				dataSize = len(content) - body
+			}
+			info.Duration = time.Duration(dataSize) * time.Second / time.Duration(byteRate)
+			return info, nil
+		}
+
+		offset = body + chunkSize + chunkSize%2
+	}
+
+	return WavInfo{}, ErrNotWav
+}
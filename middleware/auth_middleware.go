@@ -13,11 +13,13 @@ import (
 const (
 	ContextUserIDKey = "userID"
 	ContextScopesKey = "scopes"
+	ContextTenantKey = "tenant"
 )
 
 type CustomClaims struct {
 	jwt.RegisteredClaims
 	Scopes string `json:"scope,omitempty"`
+	Tenant string `json:"custom:tenant,omitempty"`
 }
 
 type AuthHandler interface {
@@ -72,6 +74,7 @@ func (h *authHandler) AuthMiddleware() gin.HandlerFunc {
 			scopes := strings.Split(claims.Scopes, " ")
 			c.Set(ContextUserIDKey, claims.Subject)
 			c.Set(ContextScopesKey, scopes)
+			c.Set(ContextTenantKey, claims.Tenant)
 		} else {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
 			return
@@ -12,7 +12,7 @@ import (
 
 type MockSegmentController interface {
 	CreateStory(c *gin.Context)
-	RegisterRoutes(g *gin.Engine)
+	RegisterRoutes(g gin.IRouter)
 }
 
 type mockSegmentController struct {
@@ -49,7 +49,7 @@ func (m *mockSegmentController) CreateStory(c *gin.Context) {
 
 	storyID := uuid.NewString()
 
-	segmentEvents, errCh := m.runner.Run(newCtx)
+	segmentEvents, errCh := m.runner.Run(newCtx, userID)
 
 	err := m.workerPool.Submit(func() {
 		var sendErrOnce sync.Once
@@ -104,6 +104,6 @@ func (m *mockSegmentController) CreateStory(c *gin.Context) {
 	c.SSEvent("generation_complete", nil)
 }
 
-func (m *mockSegmentController) RegisterRoutes(g *gin.Engine) {
+func (m *mockSegmentController) RegisterRoutes(g gin.IRouter) {
 	g.POST("generate/mock", m.CreateStory)
 }
@@ -6,7 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func Init(g *gin.Engine, workerPool outbound.TaskDispatcher, metadataSaver inbound.SegmentMetadataSaverPort, storySaver outbound.StorySaverPort,
+func Init(g gin.IRouter, workerPool outbound.TaskDispatcher, metadataSaver inbound.SegmentMetadataSaverPort, storySaver outbound.StorySaverPort,
 	logger outbound.LoggerPort) {
 	segmentReader := NewFileSegmentReader(logger)
 	runner := NewRunner(workerPool, segmentReader, metadataSaver, logger)
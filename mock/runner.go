@@ -26,10 +26,10 @@ func NewRunner(workerPool outbound.TaskDispatcher, segmentReader SegmentReader,
 	}
 }
 
-func (r *Runner) Run(ctx context.Context) (<-chan domain.SegmentEvent, <-chan error) {
+func (r *Runner) Run(ctx context.Context, userID string) (<-chan domain.SegmentEvent, <-chan error) {
 	segmentCh, segmentErrCh := r.createSegmentStream(ctx, uuid.NewString())
 
-	segmentEvents, metadataSaverErrCh := r.metadataSaver.Save(ctx, segmentCh)
+	segmentEvents, metadataSaverErrCh := r.metadataSaver.Save(ctx, segmentCh, userID)
 
 	mergedErrCh, err := channel_utils.MergeChannels(r.workerPool, segmentErrCh, metadataSaverErrCh)
 	if err != nil {